@@ -0,0 +1,175 @@
+// Command fetch downloads the pinned npm packages the docs pages need and
+// copies the files they use into the directory given as its argument. Every
+// tarball is checked against the sha512 integrity the registry publishes for
+// its version before anything is written.
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const registry = "https://registry.npmjs.org"
+
+type pkg struct {
+	Name    string
+	Version string
+	// Files maps a path inside the package to the name it is served under.
+	Files map[string]string
+}
+
+// packages are the versions the pages were written against; bump them here
+// and run go generate ./assets to upgrade.
+var packages = []pkg{
+	{Name: "redoc", Version: "2.1.5", Files: map[string]string{
+		"bundles/redoc.standalone.js": "redoc.standalone.js",
+	}},
+	{Name: "react", Version: "18.3.1", Files: map[string]string{
+		"umd/react.production.min.js": "react.production.min.js",
+	}},
+	{Name: "react-dom", Version: "18.3.1", Files: map[string]string{
+		"umd/react-dom.production.min.js": "react-dom.production.min.js",
+	}},
+	{Name: "graphql-ws", Version: "5.16.0", Files: map[string]string{
+		"umd/graphql-ws.min.js": "graphql-ws.min.js",
+	}},
+	{Name: "graphiql", Version: "3.7.1", Files: map[string]string{
+		"graphiql.min.js":  "graphiql.min.js",
+		"graphiql.min.css": "graphiql.min.css",
+	}},
+}
+
+// licenseFiles are copied, as LICENSE.<package>, from whichever a package
+// has.
+var licenseFiles = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "license"}
+
+var client = &http.Client{Timeout: time.Minute}
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: fetch <directory>")
+	}
+	directory := os.Args[1]
+
+	for _, p := range packages {
+		if err := fetch(p, directory); err != nil {
+			log.Fatalf("%s@%s: %v", p.Name, p.Version, err)
+		}
+		log.Printf("%s@%s", p.Name, p.Version)
+	}
+}
+
+func fetch(p pkg, directory string) error {
+	var manifest struct {
+		Dist struct {
+			Tarball   string `json:"tarball"`
+			Integrity string `json:"integrity"`
+		} `json:"dist"`
+	}
+	body, err := get(registry + "/" + p.Name + "/" + p.Version)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return fmt.Errorf("decode manifest: %w", err)
+	}
+
+	tarball, err := get(manifest.Dist.Tarball)
+	if err != nil {
+		return err
+	}
+	if err := verify(tarball, manifest.Dist.Integrity); err != nil {
+		return err
+	}
+
+	contents, err := untar(tarball)
+	if err != nil {
+		return err
+	}
+
+	for source, target := range p.Files {
+		data, ok := contents[source]
+		if !ok {
+			return fmt.Errorf("%s is not in the package", source)
+		}
+		if err := os.WriteFile(filepath.Join(directory, target), data, 0o644); err != nil {
+			return err
+		}
+	}
+	for _, name := range licenseFiles {
+		if data, ok := contents[name]; ok {
+			return os.WriteFile(filepath.Join(directory, "LICENSE."+p.Name), data, 0o644)
+		}
+	}
+	return errors.New("the package has no license file")
+}
+
+func get(url string) ([]byte, error) {
+	response, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, response.Status)
+	}
+	return io.ReadAll(response.Body)
+}
+
+func verify(tarball []byte, integrity string) error {
+	expected, ok := strings.CutPrefix(integrity, "sha512-")
+	if !ok {
+		return fmt.Errorf("unsupported integrity %q", integrity)
+	}
+	sum := sha512.Sum512(tarball)
+	if actual := base64.StdEncoding.EncodeToString(sum[:]); actual != expected {
+		return fmt.Errorf("tarball is sha512-%s, the registry published %s", actual, integrity)
+	}
+	return nil
+}
+
+// untar returns the regular files of an npm tarball by their path inside
+// the package.
+func untar(tarball []byte) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(tarball))
+	if err != nil {
+		return nil, err
+	}
+	reader := tar.NewReader(gz)
+
+	contents := map[string][]byte{}
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return contents, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		// npm packs everything below a single top-level directory, usually
+		// package/
+		_, name, _ := strings.Cut(header.Name, "/")
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		contents[name] = data
+	}
+}
@@ -0,0 +1,22 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"todo-app-api/service"
+)
+
+// AuditVerify walks the audit chain and reports whether it is intact. It
+// needs only database access, so it can be run against a backup offline.
+func AuditVerify(ctx context.Context, auditService service.AuditService, out io.Writer) error {
+	result := auditService.Verify(ctx)
+	if !result.Valid {
+		fmt.Fprintf(out, "audit log tampered: chain broken at entry %d after %d valid entries\n", result.BrokenAt, result.Entries)
+		return errors.New("audit log verification failed")
+	}
+
+	fmt.Fprintf(out, "audit log intact: %d entries verified\n", result.Entries)
+	return nil
+}
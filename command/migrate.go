@@ -0,0 +1,78 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"todo-app-api/migration"
+)
+
+const migrateUsage = "usage: migrate up|down|status|to <version>"
+
+// Migrate runs the migrate subcommand described by args.
+func Migrate(ctx context.Context, migrator *migration.Migrator, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	var ran []migration.Migration
+	var err error
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		ran, err = migrator.Up(ctx)
+	case args[0] == "down" && len(args) == 1:
+		ran, err = migrator.Down(ctx)
+	case args[0] == "to" && len(args) == 2:
+		version, errConv := strconv.ParseInt(args[1], 10, 64)
+		if errConv != nil {
+			return fmt.Errorf("version must be a number: %q", args[1])
+		}
+		ran, err = migrator.To(ctx, version)
+	case args[0] == "status" && len(args) == 1:
+		return migrateStatus(ctx, migrator, out)
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	for _, m := range ran {
+		fmt.Fprintf(out, "migrated %04d_%s\n", m.Version, m.Name)
+	}
+	if err == nil && len(ran) == 0 {
+		fmt.Fprintln(out, "nothing to migrate")
+	}
+	return err
+}
+
+func migrateStatus(ctx context.Context, migrator *migration.Migrator, out io.Writer) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		state := "pending"
+		if status.Applied {
+			state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(out, "%04d_%s\t%s\n", status.Version, status.Name, state)
+	}
+	return nil
+}
+
+// EnsureSchema refuses to continue while migrations are pending, unless
+// autoMigrate is set in which case they are applied first.
+func EnsureSchema(ctx context.Context, migrator *migration.Migrator, autoMigrate bool, out io.Writer) error {
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if !autoMigrate {
+		return fmt.Errorf("%w (%d pending)", migration.ErrSchemaBehind, len(pending))
+	}
+	return Migrate(ctx, migrator, []string{"up"}, out)
+}
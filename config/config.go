@@ -0,0 +1,486 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "******"
+
+type AppConfig struct {
+	Port        int    `yaml:"port" toml:"port" env:"APP_PORT" validate:"min=1,max=65535"`
+	Env         string `yaml:"env" toml:"env" env:"APP_ENV" validate:"oneof=development production test"`
+	AutoMigrate bool   `yaml:"auto_migrate" toml:"auto_migrate" env:"AUTO_MIGRATE"`
+	// ShutdownTimeout bounds how long in-flight requests and background
+	// workers get to finish after SIGTERM.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" validate:"min=1"`
+}
+
+type WebhookConfig struct {
+	MaxAttempts  int           `yaml:"max_attempts" toml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS" validate:"min=1"`
+	BaseBackoff  time.Duration `yaml:"base_backoff" toml:"base_backoff" env:"WEBHOOK_BASE_BACKOFF" validate:"min=1"`
+	MaxBackoff   time.Duration `yaml:"max_backoff" toml:"max_backoff" env:"WEBHOOK_MAX_BACKOFF" validate:"gtefield=BaseBackoff"`
+	PollInterval time.Duration `yaml:"poll_interval" toml:"poll_interval" env:"WEBHOOK_POLL_INTERVAL" validate:"min=1"`
+	Timeout      time.Duration `yaml:"timeout" toml:"timeout" env:"WEBHOOK_TIMEOUT" validate:"min=1"`
+	ClaimTimeout time.Duration `yaml:"claim_timeout" toml:"claim_timeout" env:"WEBHOOK_CLAIM_TIMEOUT" validate:"gtfield=Timeout"`
+	// AllowPrivateTargets lets subscriptions reach loopback and private
+	// network addresses; leave it off unless every subscriber is trusted.
+	AllowPrivateTargets bool `yaml:"allow_private_targets" toml:"allow_private_targets" env:"WEBHOOK_ALLOW_PRIVATE_TARGETS"`
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval" toml:"poll_interval" env:"OUTBOX_POLL_INTERVAL" validate:"min=1"`
+	BatchSize    int           `yaml:"batch_size" toml:"batch_size" env:"OUTBOX_BATCH_SIZE" validate:"min=1"`
+	MaxAttempts  int           `yaml:"max_attempts" toml:"max_attempts" env:"OUTBOX_MAX_ATTEMPTS" validate:"min=1"`
+	BaseBackoff  time.Duration `yaml:"base_backoff" toml:"base_backoff" env:"OUTBOX_BASE_BACKOFF" validate:"min=1"`
+	MaxBackoff   time.Duration `yaml:"max_backoff" toml:"max_backoff" env:"OUTBOX_MAX_BACKOFF" validate:"gtefield=BaseBackoff"`
+	// ClaimTimeout is how long a claimed message is reserved for one relay
+	// before another may pick it up again.
+	ClaimTimeout time.Duration `yaml:"claim_timeout" toml:"claim_timeout" env:"OUTBOX_CLAIM_TIMEOUT" validate:"min=1"`
+}
+
+type StreamConfig struct {
+	Heartbeat   time.Duration `yaml:"heartbeat" toml:"heartbeat" env:"STREAM_HEARTBEAT" validate:"min=1"`
+	HistorySize int           `yaml:"history_size" toml:"history_size" env:"STREAM_HISTORY_SIZE" validate:"min=1"`
+	BufferSize  int           `yaml:"buffer_size" toml:"buffer_size" env:"STREAM_BUFFER_SIZE" validate:"min=1"`
+	// PollInterval is how often the outbox is read for events written
+	// through other instances.
+	PollInterval time.Duration `yaml:"poll_interval" toml:"poll_interval" env:"STREAM_POLL_INTERVAL" validate:"min=1"`
+	GapTimeout   time.Duration `yaml:"gap_timeout" toml:"gap_timeout" env:"STREAM_GAP_TIMEOUT" validate:"min=1"`
+}
+
+type MetricsConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled" env:"METRICS_ENABLED"`
+	// AdminPort serves /metrics on its own port instead of the API port when
+	// set, so it does not have to be exposed publicly.
+	AdminPort int `yaml:"admin_port" toml:"admin_port" env:"METRICS_ADMIN_PORT" validate:"min=0,max=65535"`
+}
+
+type TracingConfig struct {
+	// Exporter is none, otlp (configured by the standard
+	// OTEL_EXPORTER_OTLP_* variables) or stdout.
+	Exporter    string `yaml:"exporter" toml:"exporter" env:"TRACING_EXPORTER" validate:"oneof=none otlp stdout"`
+	File        string `yaml:"file" toml:"file" env:"TRACING_FILE"`
+	ServiceName string `yaml:"service_name" toml:"service_name" env:"OTEL_SERVICE_NAME" validate:"required"`
+}
+
+// APIConfig announces the retirement of API v1 through the Deprecation and
+// Sunset headers. Both are dates such as 2027-05-01 and empty by default, so
+// nothing is announced until a deployment decides on them.
+type APIConfig struct {
+	V1DeprecatedAt string `yaml:"v1_deprecated_at" toml:"v1_deprecated_at" env:"API_V1_DEPRECATED_AT" validate:"omitempty,datetime=2006-01-02"`
+	V1Sunset       string `yaml:"v1_sunset" toml:"v1_sunset" env:"API_V1_SUNSET" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// Date parses one of the APIConfig dates, returning the zero time when it is
+// not set.
+func Date(value string) time.Time {
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
+
+// GraphQLConfig bounds the operations /graphql accepts, see graph.Limits.
+type GraphQLConfig struct {
+	MaxDepth                   int `yaml:"max_depth" toml:"max_depth" env:"GRAPHQL_MAX_DEPTH" validate:"min=1"`
+	MaxComplexity              int `yaml:"max_complexity" toml:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY" validate:"min=1"`
+	MaxIntrospectionDepth      int `yaml:"max_introspection_depth" toml:"max_introspection_depth" env:"GRAPHQL_MAX_INTROSPECTION_DEPTH" validate:"min=1"`
+	MaxIntrospectionComplexity int `yaml:"max_introspection_complexity" toml:"max_introspection_complexity" env:"GRAPHQL_MAX_INTROSPECTION_COMPLEXITY" validate:"min=1"`
+}
+
+// GRPCConfig serves todo.v1.TodoService and todo.v1.CommentService on their
+// own port; 0 turns it off. GatewayPort serves the HTTP rules of both
+// services through the gRPC-Gateway, which calls the gRPC port; 0 turns it
+// off.
+type GRPCConfig struct {
+	Port        int `yaml:"port" toml:"port" env:"GRPC_PORT" validate:"min=0,max=65535"`
+	GatewayPort int `yaml:"gateway_port" toml:"gateway_port" env:"GRPC_GATEWAY_PORT" validate:"min=0,max=65535"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level" toml:"level" env:"LOG_LEVEL" validate:"oneof=debug info warn error"`
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT" validate:"oneof=json text"`
+}
+
+type HealthConfig struct {
+	Timeout  time.Duration `yaml:"timeout" toml:"timeout" env:"HEALTH_TIMEOUT" validate:"min=1"`
+	CacheTTL time.Duration `yaml:"cache_ttl" toml:"cache_ttl" env:"HEALTH_CACHE_TTL" validate:"min=0"`
+}
+
+// Config is the complete application configuration. Values are resolved from
+// Default, then the optional CONFIG_FILE (YAML or TOML), then environment
+// variables, which .env feeds without overriding variables already set.
+type Config struct {
+	App      AppConfig      `yaml:"app" toml:"app"`
+	Database DatabaseConfig `yaml:"database" toml:"database"`
+	Webhook  WebhookConfig  `yaml:"webhook" toml:"webhook"`
+	Outbox   OutboxConfig   `yaml:"outbox" toml:"outbox"`
+	Stream   StreamConfig   `yaml:"stream" toml:"stream"`
+	Health   HealthConfig   `yaml:"health" toml:"health"`
+	Log      LogConfig      `yaml:"log" toml:"log"`
+	Metrics  MetricsConfig  `yaml:"metrics" toml:"metrics"`
+	Tracing  TracingConfig  `yaml:"tracing" toml:"tracing"`
+	API      APIConfig      `yaml:"api" toml:"api"`
+	GraphQL  GraphQLConfig  `yaml:"graphql" toml:"graphql"`
+	GRPC     GRPCConfig     `yaml:"grpc" toml:"grpc"`
+}
+
+func Default() Config {
+	return Config{
+		App: AppConfig{
+			Port:            3000,
+			Env:             "development",
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:                  "localhost",
+			Port:                  "5432",
+			MaxOpenConns:          25,
+			MaxIdleConns:          10,
+			ConnMaxLifetime:       30 * time.Minute,
+			ConnMaxIdleTime:       5 * time.Minute,
+			ReadYourWrites:        5 * time.Second,
+			SlowQueryThreshold:    200 * time.Millisecond,
+			ReplicaHealthInterval: 10 * time.Second,
+			ConnectAttempts:       10,
+			ConnectBackoff:        500 * time.Millisecond,
+			ConnectMaxBackoff:     10 * time.Second,
+		},
+		Webhook: WebhookConfig{
+			MaxAttempts:  8,
+			BaseBackoff:  time.Second,
+			MaxBackoff:   time.Hour,
+			PollInterval: 5 * time.Second,
+			Timeout:      10 * time.Second,
+			ClaimTimeout: time.Minute,
+		},
+		Outbox: OutboxConfig{
+			PollInterval: 500 * time.Millisecond,
+			BatchSize:    100,
+			MaxAttempts:  10,
+			BaseBackoff:  time.Second,
+			MaxBackoff:   5 * time.Minute,
+			ClaimTimeout: time.Minute,
+		},
+		Stream: StreamConfig{
+			Heartbeat:    15 * time.Second,
+			HistorySize:  1000,
+			BufferSize:   64,
+			PollInterval: 500 * time.Millisecond,
+			GapTimeout:   time.Minute,
+		},
+		Health: HealthConfig{
+			Timeout:  2 * time.Second,
+			CacheTTL: 5 * time.Second,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "todo-app-api",
+		},
+		GraphQL: GraphQLConfig{
+			MaxDepth:      8,
+			MaxComplexity: 1000,
+			// enough for the introspection query of GraphiQL
+			MaxIntrospectionDepth:      15,
+			MaxIntrospectionComplexity: 50000,
+		},
+		GRPC: GRPCConfig{
+			Port: 9090,
+		},
+	}
+}
+
+// Load builds the configuration from all sources and validates it. path may
+// be empty, in which case only defaults and the environment are used.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := loadFile(path, &cfg); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(&cfg).Elem(), os.LookupEnv); err != nil {
+		return cfg, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+func loadFile(path string, cfg *Config) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+	case ".toml":
+		metadata, err := toml.Decode(string(content), cfg)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("parse %s: unknown key %s", path, undecoded[0])
+		}
+	default:
+		return fmt.Errorf("unsupported config file type %q, use .yaml, .yml or .toml", filepath.Ext(path))
+	}
+	return nil
+}
+
+// applyEnv overrides every field carrying an env tag whose variable is set.
+func applyEnv(value reflect.Value, lookup func(string) (string, bool)) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		structField := value.Type().Field(i)
+
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := structField.Tag.Get("env")
+		raw, ok := lookup(name)
+		if name == "" || !ok {
+			continue
+		}
+
+		if err := setField(field, raw); err != nil {
+			return fmt.Errorf("invalid configuration: %s=%q: %v", name, raw, err)
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, raw string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return errors.New("must be a duration such as 500ms or 5s")
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		number, err := strconv.Atoi(raw)
+		if err != nil {
+			return errors.New("must be a whole number")
+		}
+		field.SetInt(int64(number))
+	case reflect.Bool:
+		flag, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		field.SetBool(flag)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+		var values []string
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		field.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Kind())
+	}
+	return nil
+}
+
+// Validate checks every field and reports all problems at once, naming the
+// environment variable that sets each offending field.
+func (cfg Config) Validate() error {
+	var problems []string
+
+	err := validator.New().Struct(cfg)
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for _, fieldError := range validationErrors {
+			problems = append(problems, describe(cfg, fieldError))
+		}
+	}
+
+	database := cfg.Database
+	if database.Url == "" && database.DriverName() != DriverSQLite {
+		if database.Host == "" {
+			problems = append(problems, "Database.Host (DB_HOST) is required unless DATABASE_URL is set")
+		}
+		if database.Name == "" {
+			problems = append(problems, "Database.Name (DB_NAME) is required unless DATABASE_URL is set")
+		}
+	}
+
+	if database.Url != "" {
+		urlSSLMode, urlSSLRootCert := urlSSL(database.Url)
+		if database.SSLMode != "" && urlSSLMode != "" && database.SSLMode != urlSSLMode {
+			problems = append(problems, fmt.Sprintf("Database.SSLMode (DB_SSLMODE) = %s conflicts with sslmode=%s in DATABASE_URL, set only one", database.SSLMode, urlSSLMode))
+		}
+		if database.SSLRootCert != "" && urlSSLRootCert != "" && database.SSLRootCert != urlSSLRootCert {
+			problems = append(problems, fmt.Sprintf("Database.SSLRootCert (DB_SSLROOTCERT) = %s conflicts with sslrootcert=%s in DATABASE_URL, set only one", database.SSLRootCert, urlSSLRootCert))
+		}
+	}
+
+	if cfg.GRPC.GatewayPort != 0 && cfg.GRPC.Port == 0 {
+		problems = append(problems, "GRPC.GatewayPort (GRPC_GATEWAY_PORT) needs GRPC.Port (GRPC_PORT), the gateway calls the gRPC server")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration:\n  - " + strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+func describe(cfg Config, fieldError validator.FieldError) string {
+	path := strings.TrimPrefix(fieldError.StructNamespace(), "Config.")
+	name := path
+	if env := envName(reflect.TypeOf(cfg), strings.Split(path, ".")); env != "" {
+		name = fmt.Sprintf("%s (%s)", path, env)
+	}
+
+	rule := fieldError.Tag()
+	if fieldError.Param() != "" {
+		rule += "=" + fieldError.Param()
+	}
+	return fmt.Sprintf("%s = %v fails %q", name, fieldError.Value(), rule)
+}
+
+func envName(structType reflect.Type, path []string) string {
+	field, ok := structType.FieldByName(path[0])
+	if !ok {
+		return ""
+	}
+	if len(path) == 1 {
+		return field.Tag.Get("env")
+	}
+	return envName(field.Type, path[1:])
+}
+
+// Redacted returns a copy safe to print: secret fields are masked and the
+// password inside DATABASE_URL is replaced.
+func (cfg Config) Redacted() Config {
+	safe := cfg
+	redact(reflect.ValueOf(&safe).Elem())
+	return safe
+}
+
+func redact(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		tag := value.Type().Field(i).Tag.Get("secret")
+
+		switch {
+		case field.Kind() == reflect.Struct:
+			redact(field)
+		case field.Kind() == reflect.String:
+			field.SetString(redactValue(tag, field.String()))
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String && tag != "":
+			// copied, the slice still shares its array with the original
+			values := make([]string, field.Len())
+			for j := range values {
+				values[j] = redactValue(tag, field.Index(j).String())
+			}
+			field.Set(reflect.ValueOf(values))
+		}
+	}
+}
+
+func redactValue(tag string, value string) string {
+	if value == "" {
+		return value
+	}
+
+	switch tag {
+	case "true":
+		return redacted
+	case "url":
+		return redactDatabaseUrl(value)
+	}
+	return value
+}
+
+// secretParameter matches password=... in a keyword/value DSN such as
+// "host=db user=app password=secret", quoted or not.
+var secretParameter = regexp.MustCompile(`(?i)\b((?:ssl)?password)\s*=\s*('(?:[^'\\]|\\.)*'|\S*)`)
+
+// redactDatabaseUrl masks the passwords of a database URL or keyword/value
+// DSN, in the userinfo as well as in parameters. Anything it cannot read is
+// masked as a whole.
+func redactDatabaseUrl(value string) string {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme == "" {
+		if !strings.Contains(value, "=") {
+			return redacted
+		}
+		return secretParameter.ReplaceAllString(value, "$1="+redacted)
+	}
+	// such as user:password@tcp(db)/todo, read as scheme "user"
+	if strings.Contains(parsed.Opaque, "@") {
+		return redacted
+	}
+
+	// RawQuery is written as is, url.Values would percent-encode the asterisks
+	if parsed.RawQuery != "" {
+		parameters := strings.Split(parsed.RawQuery, "&")
+		for i, parameter := range parameters {
+			key, _, _ := strings.Cut(parameter, "=")
+			if name, err := url.QueryUnescape(key); err != nil || strings.Contains(strings.ToLower(name), "password") {
+				parameters[i] = key + "=" + redacted
+			}
+		}
+		parsed.RawQuery = strings.Join(parameters, "&")
+	}
+
+	if _, hasPassword := parsed.User.Password(); hasPassword {
+		// masked textually for the same reason
+		parsed.User = url.User(parsed.User.Username())
+		userinfo := parsed.User.String()
+		return strings.Replace(parsed.String(), userinfo+"@", userinfo+":"+redacted+"@", 1)
+	}
+	return parsed.String()
+}
+
+// Print writes the redacted configuration as YAML.
+func (cfg Config) Print(out io.Writer) error {
+	encoder := yaml.NewEncoder(out)
+	encoder.SetIndent(2)
+	defer encoder.Close()
+	return encoder.Encode(cfg.Redacted())
+}
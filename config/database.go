@@ -1,38 +1,391 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/url"
 	"os"
+	"strings"
+	"time"
+	"todo-app-api/logging"
 
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
-var DB *gorm.DB
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMySQL    = "mysql"
 
-func NewDB() *gorm.DB {
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	dbname := os.Getenv("DB_NAME")
+	mysqlTLSConfigName = "todo-app-api"
+)
+
+type DatabaseConfig struct {
+	Driver      string `yaml:"driver" toml:"driver" env:"DB_DRIVER" validate:"omitempty,oneof=postgres postgresql sqlite sqlite3 mysql"`
+	Url         string `yaml:"url" toml:"url" env:"DATABASE_URL" secret:"url"`
+	Host        string `yaml:"host" toml:"host" env:"DB_HOST"`
+	Port        string `yaml:"port" toml:"port" env:"DB_PORT" validate:"omitempty,numeric"`
+	User        string `yaml:"user" toml:"user" env:"DB_USER"`
+	Password    string `yaml:"password" toml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name        string `yaml:"name" toml:"name" env:"DB_NAME"`
+	SSLMode     string `yaml:"sslmode" toml:"sslmode" env:"DB_SSLMODE" validate:"omitempty,oneof=disable require verify-ca verify-full"`
+	SSLRootCert string `yaml:"sslrootcert" toml:"sslrootcert" env:"DB_SSLROOTCERT" validate:"omitempty,file"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" validate:"min=0"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" validate:"min=0"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" validate:"min=0"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" validate:"min=0"`
+
+	// ReplicaUrls are DATABASE_URL style addresses of read replicas using the
+	// same driver as the primary.
+	ReplicaUrls           []string      `yaml:"replica_urls" toml:"replica_urls" env:"DB_REPLICA_URLS" secret:"url"`
+	ReadYourWrites        time.Duration `yaml:"read_your_writes" toml:"read_your_writes" env:"DB_READ_YOUR_WRITES" validate:"min=0"`
+	ReplicaHealthInterval time.Duration `yaml:"replica_health_interval" toml:"replica_health_interval" env:"DB_REPLICA_HEALTH_INTERVAL" validate:"min=1"`
+
+	// SlowQueryThreshold is how long a query may take before it is logged
+	// as slow. Zero disables slow-query logging.
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" toml:"slow_query_threshold" env:"DB_SLOW_QUERY_THRESHOLD" validate:"min=0"`
+
+	// ConnectAttempts and the backoff bounds control how long startup waits
+	// for a database that is not accepting connections yet.
+	ConnectAttempts   int           `yaml:"connect_attempts" toml:"connect_attempts" env:"DB_CONNECT_ATTEMPTS" validate:"min=1"`
+	ConnectBackoff    time.Duration `yaml:"connect_backoff" toml:"connect_backoff" env:"DB_CONNECT_BACKOFF" validate:"min=1"`
+	ConnectMaxBackoff time.Duration `yaml:"connect_max_backoff" toml:"connect_max_backoff" env:"DB_CONNECT_MAX_BACKOFF" validate:"gtefield=ConnectBackoff"`
+}
+
+// driverFromUrl guesses the driver from a DATABASE_URL scheme.
+func driverFromUrl(databaseUrl string) string {
+	switch {
+	case strings.HasPrefix(databaseUrl, "postgres://"), strings.HasPrefix(databaseUrl, "postgresql://"):
+		return DriverPostgres
+	case strings.HasPrefix(databaseUrl, "mysql://"):
+		return DriverMySQL
+	case strings.HasPrefix(databaseUrl, "sqlite://"), strings.HasPrefix(databaseUrl, "file:"):
+		return DriverSQLite
+	}
+	return ""
+}
+
+// DriverName resolves the driver to one of the Driver constants. Postgres is
+// the default when neither DB_DRIVER nor a recognisable DATABASE_URL is set.
+func (cfg DatabaseConfig) DriverName() string {
+	driver := cfg.Driver
+	if driver == "" && cfg.Url != "" {
+		driver = driverFromUrl(cfg.Url)
+	}
+
+	switch driver {
+	case "", "postgresql":
+		return DriverPostgres
+	case "sqlite3":
+		return DriverSQLite
+	}
+	return driver
+}
+
+// Dialector builds the GORM dialector for the configured driver.
+func Dialector(cfg DatabaseConfig) (gorm.Dialector, error) {
+	driver := cfg.DriverName()
+	switch driver {
+	case DriverPostgres:
+		return postgres.Open(postgresDSN(cfg)), nil
+	case DriverSQLite:
+		return sqlite.Open(sqliteDSN(cfg)), nil
+	case DriverMySQL:
+		dsn, err := mysqlDSN(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return mysql.Open(dsn), nil
+	}
+	return nil, fmt.Errorf("unsupported DB_DRIVER %q", driver)
+}
+
+// urlSSL reads sslmode and sslrootcert out of a DATABASE_URL, which may also
+// be a libpq keyword/value string.
+func urlSSL(databaseUrl string) (sslMode string, sslRootCert string) {
+	if parsed, err := url.Parse(databaseUrl); err == nil && parsed.Scheme != "" {
+		query := parsed.Query()
+		return query.Get("sslmode"), query.Get("sslrootcert")
+	}
+	for _, field := range strings.Fields(databaseUrl) {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "sslmode":
+			sslMode = value
+		case "sslrootcert":
+			sslRootCert = value
+		}
+	}
+	return sslMode, sslRootCert
+}
+
+// postgresUrl adds DB_SSLMODE and DB_SSLROOTCERT to a DATABASE_URL that does
+// not set them itself; Config.Validate rejects one that sets them otherwise.
+func postgresUrl(cfg DatabaseConfig) string {
+	urlSSLMode, urlSSLRootCert := urlSSL(cfg.Url)
+	params := map[string]string{}
+	if cfg.SSLMode != "" && urlSSLMode == "" {
+		params["sslmode"] = cfg.SSLMode
+	}
+	if cfg.SSLRootCert != "" && urlSSLRootCert == "" {
+		params["sslrootcert"] = cfg.SSLRootCert
+	}
+	if len(params) == 0 {
+		return cfg.Url
+	}
+
+	if parsed, err := url.Parse(cfg.Url); err == nil && parsed.Scheme != "" {
+		query := parsed.Query()
+		for key, value := range params {
+			query.Set(key, value)
+		}
+		parsed.RawQuery = query.Encode()
+		return parsed.String()
+	}
+
+	dsn := cfg.Url
+	for _, key := range []string{"sslmode", "sslrootcert"} {
+		if value, ok := params[key]; ok {
+			dsn += " " + key + "=" + value
+		}
+	}
+	return dsn
+}
+
+func postgresDSN(cfg DatabaseConfig) string {
+	if cfg.Url != "" {
+		return postgresUrl(cfg)
+	}
+
+	sslMode := cfg.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
 
 	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		host, user, password, dbname, port,
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
+		cfg.Host, cfg.User, cfg.Password, cfg.Name, cfg.Port, sslMode,
 	)
+	if cfg.SSLRootCert != "" {
+		dsn += " sslrootcert=" + cfg.SSLRootCert
+	}
+	return dsn
+}
+
+// sqliteDSN accepts a file path or "memory"/":memory:" for a throwaway
+// in-memory database shared by all connections of the pool.
+func sqliteDSN(cfg DatabaseConfig) string {
+	path := cfg.Name
+	if cfg.Url != "" {
+		path = strings.TrimPrefix(cfg.Url, "sqlite://")
+	}
+
+	switch path {
+	case "":
+		return "todo.db"
+	case "memory", ":memory:":
+		return "file::memory:?cache=shared"
+	}
+	return path
+}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
+func mysqlDSN(cfg DatabaseConfig) (string, error) {
+	mysqlConfig := mysqldriver.NewConfig()
+	mysqlConfig.ParseTime = true
+	mysqlConfig.Params = map[string]string{"charset": "utf8mb4"}
+
+	if cfg.Url != "" {
+		parsed, err := url.Parse(cfg.Url)
+		if err != nil {
+			return "", fmt.Errorf("invalid DATABASE_URL: %w", err)
+		}
+		mysqlConfig.User = parsed.User.Username()
+		mysqlConfig.Passwd, _ = parsed.User.Password()
+		mysqlConfig.Net = "tcp"
+		mysqlConfig.Addr = parsed.Host
+		mysqlConfig.DBName = strings.TrimPrefix(parsed.Path, "/")
+		for key, values := range parsed.Query() {
+			switch key {
+			case "sslmode":
+				cfg.SSLMode = values[0]
+				continue
+			case "sslrootcert":
+				cfg.SSLRootCert = values[0]
+				continue
+			}
+			mysqlConfig.Params[key] = values[0]
+		}
+	} else {
+		mysqlConfig.User = cfg.User
+		mysqlConfig.Passwd = cfg.Password
+		mysqlConfig.Net = "tcp"
+		mysqlConfig.Addr = cfg.Host + ":" + cfg.Port
+		mysqlConfig.DBName = cfg.Name
+	}
+
+	tlsConfig, err := mysqlTLS(cfg)
+	if err != nil {
+		return "", err
+	}
+	mysqlConfig.TLSConfig = tlsConfig
+
+	return mysqlConfig.FormatDSN(), nil
+}
+
+// mysqlTLS maps the libpq style sslmode values onto the MySQL driver's tls
+// parameter, registering a custom config when a root certificate is given.
+func mysqlTLS(cfg DatabaseConfig) (string, error) {
+	switch cfg.SSLMode {
+	case "", "disable":
+		return "false", nil
+	case "require":
+		return "skip-verify", nil
+	case "verify-ca", "verify-full":
+		if cfg.SSLRootCert == "" {
+			return "true", nil
+		}
+		pem, err := os.ReadFile(cfg.SSLRootCert)
+		if err != nil {
+			return "", fmt.Errorf("read DB_SSLROOTCERT: %w", err)
+		}
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			return "", errors.New("DB_SSLROOTCERT contains no certificates")
+		}
+		err = mysqldriver.RegisterTLSConfig(mysqlTLSConfigName, &tls.Config{
+			RootCAs:            rootCAs,
+			InsecureSkipVerify: cfg.SSLMode == "verify-ca",
+			VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+				if cfg.SSLMode != "verify-ca" {
+					return nil
+				}
+				return verifyChain(rawCerts, rootCAs)
+			},
+		})
+		if err != nil {
+			return "", err
+		}
+		return mysqlTLSConfigName, nil
+	}
+	return "", fmt.Errorf("unsupported DB_SSLMODE %q", cfg.SSLMode)
+}
+
+// verifyChain checks the server certificate against rootCAs without matching
+// the host name, which is what verify-ca means.
+func verifyChain(rawCerts [][]byte, rootCAs *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return errors.New("server sent no certificate")
+	}
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		certs = append(certs, cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{Roots: rootCAs, Intermediates: intermediates})
+	return err
+}
+
+// NewDB connects to the configured database, retrying with exponential
+// backoff so the API survives a database that starts after it, and applies
+// the pool settings.
+func NewDB(cfg DatabaseConfig) (*gorm.DB, error) {
+	dialector, err := Dialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := max(cfg.ConnectAttempts, 1)
+	backoff := cfg.ConnectBackoff
+	var db *gorm.DB
+	for attempt := 1; ; attempt++ {
+		db, err = gorm.Open(dialector, &gorm.Config{
+			Logger: logging.NewGormLogger(nil, cfg.SlowQueryThreshold),
+		})
+		if err == nil {
+			break
+		}
+		if attempt >= attempts {
+			return nil, fmt.Errorf("connect to database after %d attempt(s): %w", attempt, err)
+		}
+
+		slog.Warn("database connection failed, retrying", "attempt", attempt, "attempts", attempts, "backoff", backoff, "error", err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, max(cfg.ConnectMaxBackoff, cfg.ConnectBackoff))
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	configurePool(sqlDB, cfg)
+
+	slog.Info("database connected", "driver", cfg.DriverName())
+	return db, nil
+}
+
+// NewReplicaDB opens a read replica at replicaUrl with the primary's pool
+// settings. It does not wait for the replica to be reachable; an unreachable
+// replica is simply kept out of rotation by the health checks.
+func NewReplicaDB(cfg DatabaseConfig, replicaUrl string) (*gorm.DB, error) {
+	cfg.Driver = cfg.DriverName()
+	cfg.Url = replicaUrl
+
+	dialector, err := Dialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:               logging.NewGormLogger(nil, cfg.SlowQueryThreshold),
+		DisableAutomaticPing: true,
 	})
+	if err != nil {
+		return nil, err
+	}
 
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatal("Connected Fail:", err)
+		return nil, err
+	}
+	configurePool(sqlDB, cfg)
+
+	return db, nil
+}
+
+func configurePool(sqlDB *sql.DB, cfg DatabaseConfig) {
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+
+	// an in-memory SQLite database lives only as long as one of its
+	// connections, so they must never be recycled
+	if cfg.DriverName() == DriverSQLite && strings.Contains(sqliteDSN(cfg), ":memory:") {
+		return
 	}
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
 
-	log.Println("Database Connected")
-	DB = db
-	return db
+// PoolStats returns the connection pool statistics of db.
+func PoolStats(db *gorm.DB) (sql.DBStats, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return sql.DBStats{}, err
+	}
+	return sqlDB.Stats(), nil
 }
@@ -0,0 +1,6 @@
+package config
+
+// Version is the build version, set at link time:
+//
+//	go build -ldflags "-X todo-app-api/config.Version=v1.2.3"
+var Version = "dev"
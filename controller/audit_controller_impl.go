@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"fmt"
+	"todo-app-api/helper"
+	"todo-app-api/models/web"
+	"todo-app-api/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditControllerImpl struct {
+	auditService service.AuditService
+}
+
+func NewAuditController(auditService service.AuditService) AuditController {
+	return &AuditControllerImpl{
+		auditService: auditService,
+	}
+}
+
+func (controller *AuditControllerImpl) FindAll(c *fiber.Ctx) (err error) {
+	auditLogFilterRequest := web.AuditLogFilterRequest{}
+	if err := c.QueryParser(&auditLogFilterRequest); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	pageResponse := controller.auditService.FindAll(c.UserContext(), auditLogFilterRequest)
+	return helper.ResponseSuccess(c, pageResponse)
+}
@@ -0,0 +1,10 @@
+package controller
+
+import "github.com/gofiber/fiber/v2"
+
+type CalendarController interface {
+	CreateToken(c *fiber.Ctx) error
+	DeleteToken(c *fiber.Ctx) error
+	Feed(c *fiber.Ctx) error
+	Upload(c *fiber.Ctx) error
+}
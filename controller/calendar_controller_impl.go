@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"todo-app-api/helper"
+	"todo-app-api/models/web"
+	"todo-app-api/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CalendarControllerImpl struct {
+	calendarService service.CalendarService
+}
+
+func NewCalendarController(calendarService service.CalendarService) CalendarController {
+	return &CalendarControllerImpl{
+		calendarService: calendarService,
+	}
+}
+
+func (controller *CalendarControllerImpl) CreateToken(c *fiber.Ctx) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	tokenResponse := controller.calendarService.CreateToken(c.UserContext())
+	return helper.ResponseSuccess(c, tokenResponse)
+}
+
+func (controller *CalendarControllerImpl) DeleteToken(c *fiber.Ctx) (err error) {
+	tokenId := c.Params("tokenId")
+	id, errConv := strconv.Atoi(tokenId)
+	if errConv != nil {
+		return helper.BadRequest(c, "tokenId must be a number")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	controller.calendarService.DeleteToken(c.UserContext(), id)
+	return c.Status(fiber.StatusOK).JSON(helper.Envelope(c.UserContext(), web.WebResponse{
+		Code:   200,
+		Status: "Success",
+	}))
+}
+
+func (controller *CalendarControllerImpl) Feed(c *fiber.Ctx) (err error) {
+	token := c.Query("token")
+	if token == "" {
+		return helper.BadRequest(c, "token is required")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	feed := controller.calendarService.Feed(c.UserContext(), token)
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `inline; filename="todos.ics"`)
+	return c.Send(feed)
+}
+
+func (controller *CalendarControllerImpl) Upload(c *fiber.Ctx) (err error) {
+	token := c.Query("token")
+	if token == "" {
+		return helper.BadRequest(c, "token is required")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	uploadResponse := controller.calendarService.Upload(c.UserContext(), token, c.Body())
+	return helper.ResponseSuccess(c, uploadResponse)
+}
@@ -0,0 +1,12 @@
+package controller
+
+import "github.com/gofiber/fiber/v2"
+
+type CommentController interface {
+	Create(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+	FindAll(c *fiber.Ctx) error
+	History(c *fiber.Ctx) error
+	React(c *fiber.Ctx) error
+}
@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"todo-app-api/helper"
+	"todo-app-api/models/web"
+	"todo-app-api/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CommentControllerImpl struct {
+	commentService service.CommentService
+}
+
+func NewCommentController(commentService service.CommentService) CommentController {
+	return &CommentControllerImpl{
+		commentService: commentService,
+	}
+}
+
+func (controller *CommentControllerImpl) Create(c *fiber.Ctx) (err error) {
+	commentCreateRequest := web.CommentCreateRequest{}
+	if err := helper.ReadFromRequestBody(c, &commentCreateRequest); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	todoId, errConv := strconv.Atoi(c.Params("todoId"))
+	if errConv != nil {
+		return helper.BadRequest(c, "todoId must be a number")
+	}
+
+	commentCreateRequest.TodoId = todoId
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	commentResponse := controller.commentService.Create(c.UserContext(), commentCreateRequest)
+	return helper.ResponseSuccess(c, commentResponse)
+}
+
+func (controller *CommentControllerImpl) Update(c *fiber.Ctx) (err error) {
+	commentUpdateRequest := web.CommentUpdateRequest{}
+	if err := helper.ReadFromRequestBody(c, &commentUpdateRequest); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	todoId, errConv := strconv.Atoi(c.Params("todoId"))
+	if errConv != nil {
+		return helper.BadRequest(c, "todoId must be a number")
+	}
+	commentId, errConv := strconv.Atoi(c.Params("commentId"))
+	if errConv != nil {
+		return helper.BadRequest(c, "commentId must be a number")
+	}
+
+	commentUpdateRequest.TodoId = todoId
+	commentUpdateRequest.Id = commentId
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	commentResponse := controller.commentService.Update(c.UserContext(), commentUpdateRequest)
+	return helper.ResponseSuccess(c, commentResponse)
+}
+
+func (controller *CommentControllerImpl) Delete(c *fiber.Ctx) (err error) {
+	todoId, errConv := strconv.Atoi(c.Params("todoId"))
+	if errConv != nil {
+		return helper.BadRequest(c, "todoId must be a number")
+	}
+	commentId, errConv := strconv.Atoi(c.Params("commentId"))
+	if errConv != nil {
+		return helper.BadRequest(c, "commentId must be a number")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	controller.commentService.Delete(c.UserContext(), todoId, commentId)
+	return c.Status(fiber.StatusOK).JSON(helper.Envelope(c.UserContext(), web.WebResponse{
+		Code:   200,
+		Status: "Success",
+	}))
+}
+
+func (controller *CommentControllerImpl) FindAll(c *fiber.Ctx) (err error) {
+	commentFilterRequest := web.CommentFilterRequest{}
+	if err := c.QueryParser(&commentFilterRequest); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	todoId, errConv := strconv.Atoi(c.Params("todoId"))
+	if errConv != nil {
+		return helper.BadRequest(c, "todoId must be a number")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	pageResponse := controller.commentService.FindAll(c.UserContext(), todoId, commentFilterRequest)
+	return helper.ResponseSuccess(c, pageResponse)
+}
+
+func (controller *CommentControllerImpl) History(c *fiber.Ctx) (err error) {
+	todoId, errConv := strconv.Atoi(c.Params("todoId"))
+	if errConv != nil {
+		return helper.BadRequest(c, "todoId must be a number")
+	}
+	commentId, errConv := strconv.Atoi(c.Params("commentId"))
+	if errConv != nil {
+		return helper.BadRequest(c, "commentId must be a number")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	editResponses := controller.commentService.History(c.UserContext(), todoId, commentId)
+	return helper.ResponseSuccess(c, editResponses)
+}
+
+func (controller *CommentControllerImpl) React(c *fiber.Ctx) (err error) {
+	commentReactionRequest := web.CommentReactionRequest{}
+	if err := helper.ReadFromRequestBody(c, &commentReactionRequest); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	todoId, errConv := strconv.Atoi(c.Params("todoId"))
+	if errConv != nil {
+		return helper.BadRequest(c, "todoId must be a number")
+	}
+	commentId, errConv := strconv.Atoi(c.Params("commentId"))
+	if errConv != nil {
+		return helper.BadRequest(c, "commentId must be a number")
+	}
+
+	commentReactionRequest.TodoId = todoId
+	commentReactionRequest.Id = commentId
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	commentResponse := controller.commentService.React(c.UserContext(), commentReactionRequest)
+	return helper.ResponseSuccess(c, commentResponse)
+}
@@ -0,0 +1,11 @@
+package controller
+
+import "github.com/gofiber/fiber/v2"
+
+type DocsController interface {
+	Spec(c *fiber.Ctx) error
+	Reference(c *fiber.Ctx) error
+	// Script and Style serve the files of package assets the pages load.
+	Script(c *fiber.Ctx) error
+	Style(c *fiber.Ctx) error
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"encoding/json"
+	"strings"
+	"todo-app-api/assets"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// referencePage renders the document with Redoc, served from assets like
+// every script the pages load.
+const referencePage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Todo App API</title>
+</head>
+<body>
+<redoc spec-url="/openapi.json"></redoc>
+<script src="/assets/scripts/redoc.standalone.js"></script>
+</body>
+</html>
+`
+
+type DocsControllerImpl struct {
+	spec []byte
+}
+
+// NewDocsController serves document, which is encoded once up front.
+func NewDocsController(document map[string]any) DocsController {
+	spec, err := json.Marshal(document)
+	if err != nil {
+		panic(err)
+	}
+
+	return &DocsControllerImpl{
+		spec: spec,
+	}
+}
+
+func (controller *DocsControllerImpl) Spec(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(controller.spec)
+}
+
+func (controller *DocsControllerImpl) Reference(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(referencePage)
+}
+
+func (controller *DocsControllerImpl) Script(c *fiber.Ctx) error {
+	return sendAsset(c, ".js", fiber.MIMETextJavaScriptCharsetUTF8)
+}
+
+func (controller *DocsControllerImpl) Style(c *fiber.Ctx) error {
+	return sendAsset(c, ".css", "text/css; charset=utf-8")
+}
+
+func sendAsset(c *fiber.Ctx, extension string, contentType string) error {
+	name := c.Params("name")
+	data, ok := assets.Read(name)
+	if !ok || !strings.HasSuffix(name, extension) {
+		return fiber.NewError(fiber.StatusNotFound, "asset not found")
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	// the files only change with an upgrade of the pinned versions
+	c.Set(fiber.HeaderCacheControl, "public, max-age=86400")
+	return c.Send(data)
+}
@@ -0,0 +1,9 @@
+package controller
+
+import "github.com/gofiber/fiber/v2"
+
+type GraphQLController interface {
+	Query(c *fiber.Ctx) error
+	WebSocket(c *fiber.Ctx) error
+	Playground(c *fiber.Ctx) error
+}
@@ -0,0 +1,268 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+	"todo-app-api/graph"
+	"todo-app-api/helper"
+	"todo-app-api/models/web"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// graphQLProtocol is the graphql-transport-ws subprotocol spoken by
+// graphql-ws and GraphiQL.
+const graphQLProtocol = "graphql-transport-ws"
+
+// close codes defined by graphql-transport-ws
+const (
+	closeInvalidMessage    = 4400
+	closeUnauthorized      = 4401
+	closeInitTimeout       = 4408
+	closeSubscriberExists  = 4409
+	closeTooManyInitialize = 4429
+)
+
+// playgroundPage renders GraphiQL against /graphql and /graphql/ws, with the
+// scripts and styles of package assets.
+const playgroundPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Todo App API GraphiQL</title>
+<link rel="stylesheet" href="/assets/styles/graphiql.min.css">
+<style>body { margin: 0; height: 100vh; } #graphiql { height: 100vh; }</style>
+</head>
+<body>
+<div id="graphiql"></div>
+<script src="/assets/scripts/react.production.min.js"></script>
+<script src="/assets/scripts/react-dom.production.min.js"></script>
+<script src="/assets/scripts/graphql-ws.min.js"></script>
+<script src="/assets/scripts/graphiql.min.js"></script>
+<script>
+const scheme = location.protocol === "https:" ? "wss:" : "ws:";
+const fetcher = GraphiQL.createFetcher({
+  url: "/graphql",
+  wsClient: graphqlWs.createClient({ url: scheme + "//" + location.host + "/graphql/ws" }),
+});
+ReactDOM.createRoot(document.getElementById("graphiql")).render(React.createElement(GraphiQL, { fetcher }));
+</script>
+</body>
+</html>
+`
+
+type GraphQLControllerImpl struct {
+	executor    *graph.Executor
+	playground  bool
+	initTimeout time.Duration
+	websocket   fiber.Handler
+}
+
+// NewGraphQLController serves executor. The playground is only served when
+// playground is set, which main does in development.
+func NewGraphQLController(executor *graph.Executor, playground bool) GraphQLController {
+	controller := &GraphQLControllerImpl{
+		executor:    executor,
+		playground:  playground,
+		initTimeout: 10 * time.Second,
+	}
+	controller.websocket = websocket.New(controller.serveWebSocket, websocket.Config{
+		Subprotocols: []string{graphQLProtocol},
+	})
+	return controller
+}
+
+// Query answers with 200 whenever the request could be read, GraphQL
+// reports what went wrong in the errors of the result.
+func (controller *GraphQLControllerImpl) Query(c *fiber.Ctx) error {
+	request := web.GraphQLRequest{}
+	if err := helper.ReadFromRequestBody(c, &request); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+	if request.Query == "" {
+		return helper.BadRequest(c, "query is required")
+	}
+
+	return c.JSON(controller.executor.Execute(c.UserContext(), request))
+}
+
+func (controller *GraphQLControllerImpl) Playground(c *fiber.Ctx) error {
+	if !controller.playground {
+		return fiber.ErrNotFound
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(playgroundPage)
+}
+
+func (controller *GraphQLControllerImpl) WebSocket(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.ErrUpgradeRequired
+	}
+	// the connection outlives the handler chain, so it keeps the actor and
+	// the rest of the request context through a local
+	c.Locals("context", c.UserContext())
+	return controller.websocket(c)
+}
+
+type graphQLMessage struct {
+	Id      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type graphQLConnection struct {
+	conn    *websocket.Conn
+	writing sync.Mutex
+}
+
+func (connection *graphQLConnection) send(id string, messageType string, payload interface{}) error {
+	message := graphQLMessage{Id: id, Type: messageType}
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		message.Payload = encoded
+	}
+
+	connection.writing.Lock()
+	defer connection.writing.Unlock()
+	if err := connection.conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+		return err
+	}
+	return connection.conn.WriteJSON(message)
+}
+
+func (connection *graphQLConnection) close(code int, reason string) {
+	connection.writing.Lock()
+	defer connection.writing.Unlock()
+	connection.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+}
+
+func (controller *GraphQLControllerImpl) serveWebSocket(conn *websocket.Conn) {
+	defer conn.Close()
+
+	parent, ok := conn.Locals("context").(context.Context)
+	if !ok {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	connection := &graphQLConnection{conn: conn}
+
+	var mutex sync.Mutex
+	operations := map[string]context.CancelFunc{}
+	acknowledged := false
+
+	timer := time.AfterFunc(controller.initTimeout, func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if !acknowledged {
+			connection.close(closeInitTimeout, "Connection initialisation timeout")
+			conn.Close()
+		}
+	})
+	defer timer.Stop()
+
+	for {
+		var message graphQLMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			return
+		}
+
+		switch message.Type {
+		case "connection_init":
+			mutex.Lock()
+			again := acknowledged
+			acknowledged = true
+			mutex.Unlock()
+			if again {
+				connection.close(closeTooManyInitialize, "Too many initialisation requests")
+				return
+			}
+			if connection.send("", "connection_ack", nil) != nil {
+				return
+			}
+
+		case "ping":
+			if connection.send("", "pong", nil) != nil {
+				return
+			}
+
+		case "pong":
+
+		case "subscribe":
+			mutex.Lock()
+			ready := acknowledged
+			_, exists := operations[message.Id]
+			mutex.Unlock()
+
+			request := web.GraphQLRequest{}
+			switch {
+			case !ready:
+				connection.close(closeUnauthorized, "Unauthorized")
+				return
+			case message.Id == "" || json.Unmarshal(message.Payload, &request) != nil || request.Query == "":
+				connection.close(closeInvalidMessage, "Invalid subscribe message")
+				return
+			case exists:
+				connection.close(closeSubscriberExists, "Subscriber for "+message.Id+" already exists")
+				return
+			}
+
+			operationCtx, stop := context.WithCancel(ctx)
+			results, errs := controller.executor.Subscribe(operationCtx, request)
+			if errs != nil {
+				stop()
+				if connection.send(message.Id, "error", errs) != nil {
+					return
+				}
+				continue
+			}
+
+			mutex.Lock()
+			operations[message.Id] = stop
+			mutex.Unlock()
+
+			go func(id string) {
+				for result := range results {
+					mutex.Lock()
+					_, active := operations[id]
+					mutex.Unlock()
+					if active {
+						connection.send(id, "next", result)
+					}
+				}
+
+				mutex.Lock()
+				_, active := operations[id]
+				delete(operations, id)
+				mutex.Unlock()
+				stop()
+
+				// the client already knows when it completed the operation
+				if active {
+					connection.send(id, "complete", nil)
+				}
+			}(message.Id)
+
+		case "complete":
+			mutex.Lock()
+			stop, ok := operations[message.Id]
+			delete(operations, message.Id)
+			mutex.Unlock()
+			if ok {
+				stop()
+			}
+
+		default:
+			connection.close(closeInvalidMessage, "Invalid message type "+message.Type)
+			return
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package controller
+
+import "github.com/gofiber/fiber/v2"
+
+type HealthController interface {
+	Liveness(c *fiber.Ctx) error
+	Readiness(c *fiber.Ctx) error
+	Version(c *fiber.Ctx) error
+	DatabaseStats(c *fiber.Ctx) error
+}
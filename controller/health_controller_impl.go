@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"todo-app-api/models/web"
+	"todo-app-api/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HealthControllerImpl struct {
+	HealthService service.HealthService
+}
+
+func NewHealthController(healthService service.HealthService) HealthController {
+	return &HealthControllerImpl{
+		HealthService: healthService,
+	}
+}
+
+func healthStatus(response web.HealthResponse) (int, string) {
+	if response.Status != service.HealthStatusUp {
+		return fiber.StatusServiceUnavailable, "SERVICE UNAVAILABLE"
+	}
+	return fiber.StatusOK, "Success"
+}
+
+func (controller *HealthControllerImpl) Liveness(c *fiber.Ctx) error {
+	healthResponse := controller.HealthService.Liveness(c.UserContext())
+	code, status := healthStatus(healthResponse)
+
+	return c.Status(code).JSON(web.WebResponse{
+		Code:   code,
+		Status: status,
+		Data:   healthResponse,
+	})
+}
+
+func (controller *HealthControllerImpl) Readiness(c *fiber.Ctx) error {
+	healthResponse := controller.HealthService.Readiness(c.UserContext())
+	code, status := healthStatus(healthResponse)
+
+	return c.Status(code).JSON(web.WebResponse{
+		Code:   code,
+		Status: status,
+		Data:   healthResponse,
+	})
+}
+
+func (controller *HealthControllerImpl) Version(c *fiber.Ctx) error {
+	versionResponse := controller.HealthService.Version(c.UserContext())
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:   200,
+		Status: "Success",
+		Data:   versionResponse,
+	})
+}
+
+func (controller *HealthControllerImpl) DatabaseStats(c *fiber.Ctx) error {
+	statsResponse := controller.HealthService.DatabaseStats(c.UserContext())
+
+	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+		Code:   200,
+		Status: "Success",
+		Data:   statsResponse,
+	})
+}
@@ -0,0 +1,8 @@
+package controller
+
+import "github.com/gofiber/fiber/v2"
+
+type StreamController interface {
+	Events(c *fiber.Ctx) error
+	WebSocket(c *fiber.Ctx) error
+}
@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"todo-app-api/event"
+	"todo-app-api/helper"
+	"todo-app-api/stream"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	streamResetEvent = "reset"
+	// streamWriteTimeout bounds a single WebSocket write, so a client that
+	// stopped reading cannot hold its goroutine forever.
+	streamWriteTimeout = 10 * time.Second
+)
+
+type StreamControllerImpl struct {
+	hub       *stream.Hub
+	heartbeat time.Duration
+	websocket fiber.Handler
+}
+
+func NewStreamController(hub *stream.Hub, heartbeat time.Duration) StreamController {
+	controller := &StreamControllerImpl{
+		hub:       hub,
+		heartbeat: heartbeat,
+	}
+	controller.websocket = websocket.New(controller.serveWebSocket)
+	return controller
+}
+
+type streamMessage struct {
+	Id    uint64       `json:"id"`
+	Type  string       `json:"type"`
+	Event *event.Event `json:"event,omitempty"`
+}
+
+// subscribe reads the resume position and optional todo_id filter shared by
+// both transports.
+func (controller *StreamControllerImpl) subscribe(lastEventIdParam string, todoIdParam string) (*stream.Subscription, []stream.Message, bool, error) {
+	var lastEventId uint64
+	if lastEventIdParam != "" {
+		id, err := strconv.ParseUint(lastEventIdParam, 10, 64)
+		if err != nil {
+			return nil, nil, false, errors.New("Last-Event-ID must be a number")
+		}
+		lastEventId = id
+	}
+
+	var filter func(evt event.Event) bool
+	if todoIdParam != "" {
+		todoId, err := strconv.Atoi(todoIdParam)
+		if err != nil {
+			return nil, nil, false, errors.New("todo_id must be a number")
+		}
+		filter = func(evt event.Event) bool { return evt.TodoId == todoId }
+	}
+
+	subscription, backlog, complete := controller.hub.Subscribe(lastEventId, filter)
+	return subscription, backlog, complete, nil
+}
+
+func (controller *StreamControllerImpl) Events(c *fiber.Ctx) error {
+	lastEventId := c.Get("Last-Event-ID", c.Query("last_event_id"))
+	subscription, backlog, complete, err := controller.subscribe(lastEventId, c.Query("todo_id"))
+	if err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer controller.hub.Unsubscribe(subscription)
+
+		if !complete {
+			fmt.Fprintf(w, "event: %s\ndata: {}\n\n", streamResetEvent)
+		}
+		for _, message := range backlog {
+			writeServerSentEvent(w, message)
+		}
+		if w.Flush() != nil {
+			return
+		}
+
+		ticker := time.NewTicker(controller.heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case message := <-subscription.Messages:
+				writeServerSentEvent(w, message)
+			case <-ticker.C:
+				fmt.Fprint(w, ": ping\n\n")
+			case <-subscription.Lagged:
+				fmt.Fprintf(w, "event: %s\ndata: {}\n\n", streamResetEvent)
+				w.Flush()
+				return
+			}
+			if w.Flush() != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func writeServerSentEvent(w *bufio.Writer, message stream.Message) {
+	data, _ := json.Marshal(message.Event)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", message.Id, message.Event.Type, data)
+}
+
+func (controller *StreamControllerImpl) WebSocket(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.ErrUpgradeRequired
+	}
+	return controller.websocket(c)
+}
+
+func (controller *StreamControllerImpl) serveWebSocket(conn *websocket.Conn) {
+	defer conn.Close()
+
+	subscription, backlog, complete, err := controller.subscribe(conn.Query("last_event_id"), conn.Query("todo_id"))
+	if err != nil {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseUnsupportedData, err.Error()))
+		return
+	}
+	defer controller.hub.Unsubscribe(subscription)
+
+	// the read loop only exists to notice the client going away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	if !complete && writeWebSocketJSON(conn, streamMessage{Type: streamResetEvent}) != nil {
+		return
+	}
+	for _, message := range backlog {
+		if writeWebSocketMessage(conn, message) != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(controller.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case message := <-subscription.Messages:
+			if writeWebSocketMessage(conn, message) != nil {
+				return
+			}
+		case <-ticker.C:
+			if conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)) != nil {
+				return
+			}
+		case <-subscription.Lagged:
+			writeWebSocketJSON(conn, streamMessage{Type: streamResetEvent})
+			return
+		case <-closed:
+			return
+		}
+	}
+}
+
+func writeWebSocketMessage(conn *websocket.Conn, message stream.Message) error {
+	evt := message.Event
+	return writeWebSocketJSON(conn, streamMessage{Id: message.Id, Type: evt.Type, Event: &evt})
+}
+
+func writeWebSocketJSON(conn *websocket.Conn, message streamMessage) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+		return err
+	}
+	return conn.WriteJSON(message)
+}
@@ -4,8 +4,13 @@ import "github.com/gofiber/fiber/v2"
 
 type TodoController interface {
 	Create(c *fiber.Ctx) error
+	Import(c *fiber.Ctx) error
 	Update(c *fiber.Ctx) error
 	Delete(c *fiber.Ctx) error
+	SetTask(c *fiber.Ctx) error
 	FindById(c *fiber.Ctx) error
 	FindAll(c *fiber.Ctx) error
+	Export(c *fiber.Ctx) error
+	History(c *fiber.Ctx) error
+	Revert(c *fiber.Ctx) error
 }
@@ -1,7 +1,10 @@
 package controller
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strconv"
 	"todo-app-api/helper"
 	"todo-app-api/models/web"
@@ -26,10 +29,31 @@ func (controller *TodoControllerImpl) Create(c *fiber.Ctx) error {
 		return helper.BadRequest(c, err.Error())
 	}
 
-	todoResponse := controller.todoService.Create(c.Context(), todoCreateRequest)
+	todoResponse := controller.todoService.Create(c.UserContext(), todoCreateRequest)
 	return helper.ResponseSuccess(c, todoResponse)
 }
 
+func (controller *TodoControllerImpl) Import(c *fiber.Ctx) (err error) {
+	todoImportRequest := web.TodoImportRequest{}
+	if err := helper.ReadFromRequestBody(c, &todoImportRequest); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	todoImportResponse := controller.todoService.Import(c.UserContext(), todoImportRequest)
+	return helper.ResponseSuccess(c, todoImportResponse)
+}
+
 func (controller *TodoControllerImpl) Update(c *fiber.Ctx) (err error) {
 	todoUpdateRequest := web.TodoUpdateRequest{}
 	if err := helper.ReadFromRequestBody(c, &todoUpdateRequest); err != nil {
@@ -55,10 +79,44 @@ func (controller *TodoControllerImpl) Update(c *fiber.Ctx) (err error) {
 		}
 	}()
 
-	todoResponse := controller.todoService.Update(c.Context(), todoUpdateRequest)
+	todoResponse := controller.todoService.Update(c.UserContext(), todoUpdateRequest)
 	return helper.ResponseSuccess(c, todoResponse)
 }
 
+func (controller *TodoControllerImpl) SetTask(c *fiber.Ctx) (err error) {
+	todoTaskRequest := web.TodoTaskRequest{}
+	if err := helper.ReadFromRequestBody(c, &todoTaskRequest); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	id, errConv := strconv.Atoi(c.Params("todoId"))
+	if errConv != nil {
+		return helper.BadRequest(c, "todoId must be a number")
+	}
+	task, errConv := strconv.Atoi(c.Params("task"))
+	if errConv != nil {
+		return helper.BadRequest(c, "task must be a number")
+	}
+
+	todoTaskRequest.Id = id
+	todoTaskRequest.Task = task
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	// the caller renders checkboxes, so it gets the rendered description back
+	todoResponse := controller.todoService.SetTask(c.UserContext(), todoTaskRequest)
+	return helper.ResponseSuccess(c, helper.RenderTodoResponse(todoResponse))
+}
+
 func (controller *TodoControllerImpl) Delete(c *fiber.Ctx) (err error) {
 	todoId := c.Params("todoId")
 	id, errConv := strconv.Atoi(todoId)
@@ -77,11 +135,11 @@ func (controller *TodoControllerImpl) Delete(c *fiber.Ctx) (err error) {
 		}
 	}()
 
-	controller.todoService.Delete(c.Context(), id)
-	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+	controller.todoService.Delete(c.UserContext(), id)
+	return c.Status(fiber.StatusOK).JSON(helper.Envelope(c.UserContext(), web.WebResponse{
 		Code:   200,
 		Status: "Success",
-	})
+	}))
 }
 
 func (controller *TodoControllerImpl) FindById(c *fiber.Ctx) (err error) {
@@ -90,6 +148,37 @@ func (controller *TodoControllerImpl) FindById(c *fiber.Ctx) (err error) {
 	if errConv != nil {
 		return helper.BadRequest(c, "todoId must a be number")
 	}
+	todoRenderRequest := web.TodoRenderRequest{}
+	if err := c.QueryParser(&todoRenderRequest); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	todoResponse := controller.todoService.FindById(c.UserContext(), id)
+	if todoRenderRequest.Render == "html" {
+		todoResponse = helper.RenderTodoResponse(todoResponse)
+	}
+	if todoRenderRequest.Include == "comment_count" {
+		todoResponse = helper.IncludeCommentCount(todoResponse)
+	}
+	return helper.ResponseSuccess(c, todoResponse)
+}
+
+func (controller *TodoControllerImpl) FindAll(c *fiber.Ctx) (err error) {
+	todoFilterRequest := web.TodoFilterRequest{}
+	if err := c.QueryParser(&todoFilterRequest); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -102,11 +191,123 @@ func (controller *TodoControllerImpl) FindById(c *fiber.Ctx) (err error) {
 		}
 	}()
 
-	todoResponse := controller.todoService.FindById(c.Context(), id)
+	todoResponse := controller.todoService.FindAll(c.UserContext(), todoFilterRequest)
+	if todoFilterRequest.Render == "html" {
+		todoResponse = helper.RenderTodoResponses(todoResponse)
+	}
+	if todoFilterRequest.Include == "comment_count" {
+		todoResponse = helper.IncludeCommentCounts(todoResponse)
+	}
 	return helper.ResponseSuccess(c, todoResponse)
 }
 
-func (controller *TodoControllerImpl) FindAll(c *fiber.Ctx) error {
-	todoResponse := controller.todoService.FindAll(c.Context())
+// Export streams the todos straight from the database cursor, so the status
+// and headers are sent before the first row is read. A failure past that
+// point can only cut the download short; it is logged.
+func (controller *TodoControllerImpl) Export(c *fiber.Ctx) (err error) {
+	todoExportRequest := web.TodoExportRequest{}
+	if err := c.QueryParser(&todoExportRequest); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+	if todoExportRequest.Format == "" {
+		todoExportRequest.Format = "csv"
+	}
+	contentType, ok := helper.TodoExportContentTypes[todoExportRequest.Format]
+	if !ok {
+		return helper.BadRequest(c, "format must be one of csv, json, ndjson")
+	}
+	columns, errColumns := helper.ParseExportColumns(todoExportRequest.Columns)
+	if errColumns != nil {
+		return helper.BadRequest(c, errColumns.Error())
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	ctx := c.UserContext()
+	export := controller.todoService.Export(ctx, web.TodoFilterRequest{
+		Status:  todoExportRequest.Status,
+		Mention: todoExportRequest.Mention,
+		Link:    todoExportRequest.Link,
+	})
+
+	c.Attachment("todos." + todoExportRequest.Format)
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		exporter := helper.NewTodoExporter(todoExportRequest.Format, columns, w)
+		var writeErr error
+		err := export(func(todo web.TodoResponse) bool {
+			writeErr = exporter.Write(todo)
+			return writeErr == nil
+		})
+		err = errors.Join(err, writeErr)
+		if err == nil {
+			err = exporter.Close()
+		}
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "export todos", "error", err)
+		}
+	})
+	return nil
+}
+
+func (controller *TodoControllerImpl) History(c *fiber.Ctx) (err error) {
+	todoId := c.Params("todoId")
+	id, errConv := strconv.Atoi(todoId)
+	if errConv != nil {
+		return helper.BadRequest(c, "todoId must be a number")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	revisionResponses := controller.todoService.History(c.UserContext(), id)
+	return helper.ResponseSuccess(c, revisionResponses)
+}
+
+func (controller *TodoControllerImpl) Revert(c *fiber.Ctx) (err error) {
+	todoId := c.Params("todoId")
+	id, errConv := strconv.Atoi(todoId)
+	if errConv != nil {
+		return helper.BadRequest(c, "todoId must be a number")
+	}
+
+	revisionParam := c.Params("revision")
+	revision, errConv := strconv.Atoi(revisionParam)
+	if errConv != nil {
+		return helper.BadRequest(c, "revision must be a number")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	todoResponse := controller.todoService.Revert(c.UserContext(), id, revision)
 	return helper.ResponseSuccess(c, todoResponse)
 }
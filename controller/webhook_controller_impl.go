@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"todo-app-api/helper"
+	"todo-app-api/models/web"
+	"todo-app-api/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebhookControllerImpl struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookController(webhookService service.WebhookService) WebhookController {
+	return &WebhookControllerImpl{
+		webhookService: webhookService,
+	}
+}
+
+func (controller *WebhookControllerImpl) Create(c *fiber.Ctx) error {
+	webhookCreateRequest := web.WebhookCreateRequest{}
+	if err := helper.ReadFromRequestBody(c, &webhookCreateRequest); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	webhookResponse := controller.webhookService.Create(c.UserContext(), webhookCreateRequest)
+	return helper.ResponseSuccess(c, webhookResponse)
+}
+
+func (controller *WebhookControllerImpl) Update(c *fiber.Ctx) (err error) {
+	webhookUpdateRequest := web.WebhookUpdateRequest{}
+	if err := helper.ReadFromRequestBody(c, &webhookUpdateRequest); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	webhookId := c.Params("webhookId")
+	id, errConv := strconv.Atoi(webhookId)
+	if errConv != nil {
+		return helper.BadRequest(c, "webhookId must be a number")
+	}
+
+	webhookUpdateRequest.Id = id
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	webhookResponse := controller.webhookService.Update(c.UserContext(), webhookUpdateRequest)
+	return helper.ResponseSuccess(c, webhookResponse)
+}
+
+func (controller *WebhookControllerImpl) Delete(c *fiber.Ctx) (err error) {
+	webhookId := c.Params("webhookId")
+	id, errConv := strconv.Atoi(webhookId)
+	if errConv != nil {
+		return helper.BadRequest(c, "webhookId must be a number")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	controller.webhookService.Delete(c.UserContext(), id)
+	return c.Status(fiber.StatusOK).JSON(helper.Envelope(c.UserContext(), web.WebResponse{
+		Code:   200,
+		Status: "Success",
+	}))
+}
+
+func (controller *WebhookControllerImpl) FindById(c *fiber.Ctx) (err error) {
+	webhookId := c.Params("webhookId")
+	id, errConv := strconv.Atoi(webhookId)
+	if errConv != nil {
+		return helper.BadRequest(c, "webhookId must be a number")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	webhookResponse := controller.webhookService.FindById(c.UserContext(), id)
+	return helper.ResponseSuccess(c, webhookResponse)
+}
+
+func (controller *WebhookControllerImpl) FindAll(c *fiber.Ctx) error {
+	webhookResponses := controller.webhookService.FindAll(c.UserContext())
+	return helper.ResponseSuccess(c, webhookResponses)
+}
+
+func (controller *WebhookControllerImpl) Deliveries(c *fiber.Ctx) (err error) {
+	webhookId := c.Params("webhookId")
+	id, errConv := strconv.Atoi(webhookId)
+	if errConv != nil {
+		return helper.BadRequest(c, "webhookId must be a number")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	deliveryResponses := controller.webhookService.Deliveries(c.UserContext(), id)
+	return helper.ResponseSuccess(c, deliveryResponses)
+}
@@ -0,0 +1,41 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+type Handler func(ctx context.Context, evt Event) error
+
+// Bus is the in-process Publisher: every published event is handed
+// synchronously to each subscribed handler.
+type Bus struct {
+	mutex    sync.RWMutex
+	handlers []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+func (bus *Bus) Subscribe(handler Handler) {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	bus.handlers = append(bus.handlers, handler)
+}
+
+func (bus *Bus) Publish(ctx context.Context, evt Event) error {
+	bus.mutex.RLock()
+	handlers := bus.handlers
+	bus.mutex.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, evt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,54 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo-app-api/models/web"
+)
+
+const (
+	TodoCreated   = "todo.created"
+	TodoUpdated   = "todo.updated"
+	TodoCompleted = "todo.completed"
+	TodoDeleted   = "todo.deleted"
+)
+
+var TodoEventTypes = []string{TodoCreated, TodoUpdated, TodoCompleted, TodoDeleted}
+
+type Event struct {
+	Id         string           `json:"id"`
+	Type       string           `json:"type"`
+	TodoId     int              `json:"todo_id"`
+	Data       web.TodoResponse `json:"data"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	// Sequence is the id of the outbox message the event was relayed from.
+	// Unlike a counter of the process it survives restarts and is the same
+	// on every instance.
+	Sequence uint64 `json:"-"`
+}
+
+// Publisher hands an event to a downstream consumer. A non-nil error means
+// the event was not accepted and will be offered again.
+type Publisher interface {
+	Publish(ctx context.Context, evt Event) error
+}
+
+// Publishers fans an event out to every publisher in order.
+type Publishers []Publisher
+
+func (publishers Publishers) Publish(ctx context.Context, evt Event) error {
+	var errs []error
+	for _, publisher := range publishers {
+		if err := publisher.Publish(ctx, evt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type NopPublisher struct{}
+
+func (NopPublisher) Publish(ctx context.Context, evt Event) error {
+	return nil
+}
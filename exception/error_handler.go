@@ -1,27 +1,61 @@
 package exception
 
 import (
+	"todo-app-api/helper"
 	"todo-app-api/models/web"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// traceId names the trace of the failed request so a client can quote it
+// when reporting the error.
+func traceId(c *fiber.Ctx) string {
+	spanContext := trace.SpanContextFromContext(c.UserContext())
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
 func NewErrorHandler(c *fiber.Ctx, err error) error {
+	traceId := traceId(c)
+
 	if validationError, ok := err.(validator.ValidationErrors); ok {
-		return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
-			Code:   fiber.StatusBadRequest,
-			Status: "BAD REQUEST",
-			Data:   validationError.Error(),
-		})
+		return c.Status(fiber.StatusBadRequest).JSON(helper.Envelope(c.UserContext(), web.WebResponse{
+			Code:    fiber.StatusBadRequest,
+			Status:  "BAD REQUEST",
+			Data:    validationError.Error(),
+			TraceId: traceId,
+		}))
 	}
 
 	if notFound, ok := err.(NotFoundError); ok {
-		return c.Status(fiber.StatusNotFound).JSON(web.WebResponse{
-			Code:   fiber.StatusNotFound,
-			Status: "NOT FOUND",
-			Data:   notFound.Error(),
-		})
+		return c.Status(fiber.StatusNotFound).JSON(helper.Envelope(c.UserContext(), web.WebResponse{
+			Code:    fiber.StatusNotFound,
+			Status:  "NOT FOUND",
+			Data:    notFound.Error(),
+			TraceId: traceId,
+		}))
+	}
+
+	if forbidden, ok := err.(ForbiddenError); ok {
+		return c.Status(fiber.StatusForbidden).JSON(helper.Envelope(c.UserContext(), web.WebResponse{
+			Code:    fiber.StatusForbidden,
+			Status:  "FORBIDDEN",
+			Data:    forbidden.Error(),
+			TraceId: traceId,
+		}))
+	}
+
+	if conflict, ok := err.(ConflictError); ok {
+		return c.Status(fiber.StatusConflict).JSON(helper.Envelope(c.UserContext(), web.WebResponse{
+			Code:    fiber.StatusConflict,
+			Status:  "CONFLICT",
+			Data:    conflict.Error(),
+			TraceId: traceId,
+		}))
 	}
 
 	if fiberErr, ok := err.(*fiber.Error); ok {
@@ -37,16 +71,18 @@ func NewErrorHandler(c *fiber.Ctx, err error) error {
 		} else if code == fiber.StatusInternalServerError {
 			statusText = "INTERNAL SERVICE ERROR"
 		}
-		return c.Status(code).JSON(web.WebResponse{
-			Code:   code,
-			Status: statusText,
-			Data:   fiberErr.Message,
-		})
+		return c.Status(code).JSON(helper.Envelope(c.UserContext(), web.WebResponse{
+			Code:    code,
+			Status:  statusText,
+			Data:    fiberErr.Message,
+			TraceId: traceId,
+		}))
 	}
 
-	return c.Status(fiber.StatusInternalServerError).JSON(web.WebResponse{
-		Code:   fiber.StatusInternalServerError,
-		Status: "INTERNAL SERVICE ERROR",
-		Data:   err.Error(),
-	})
+	return c.Status(fiber.StatusInternalServerError).JSON(helper.Envelope(c.UserContext(), web.WebResponse{
+		Code:    fiber.StatusInternalServerError,
+		Status:  "INTERNAL SERVICE ERROR",
+		Data:    err.Error(),
+		TraceId: traceId,
+	}))
 }
@@ -0,0 +1,928 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: todo/v1/comment.proto
+
+package todov1
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Comment keeps its place in the thread once deleted, with an empty body.
+type Comment struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	TodoId int64                  `protobuf:"varint,2,opt,name=todo_id,json=todoId,proto3" json:"todo_id,omitempty"`
+	// unset for a comment that is not a reply
+	ParentId      *int64                 `protobuf:"varint,3,opt,name=parent_id,json=parentId,proto3,oneof" json:"parent_id,omitempty"`
+	Actor         string                 `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	Body          string                 `protobuf:"bytes,5,opt,name=body,proto3" json:"body,omitempty"`
+	Edited        bool                   `protobuf:"varint,6,opt,name=edited,proto3" json:"edited,omitempty"`
+	Deleted       bool                   `protobuf:"varint,7,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Reactions     []*CommentReaction     `protobuf:"bytes,8,rep,name=reactions,proto3" json:"reactions,omitempty"`
+	Replies       []*Comment             `protobuf:"bytes,9,rep,name=replies,proto3" json:"replies,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Comment) Reset() {
+	*x = Comment{}
+	mi := &file_todo_v1_comment_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Comment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Comment) ProtoMessage() {}
+
+func (x *Comment) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_comment_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Comment.ProtoReflect.Descriptor instead.
+func (*Comment) Descriptor() ([]byte, []int) {
+	return file_todo_v1_comment_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Comment) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Comment) GetTodoId() int64 {
+	if x != nil {
+		return x.TodoId
+	}
+	return 0
+}
+
+func (x *Comment) GetParentId() int64 {
+	if x != nil && x.ParentId != nil {
+		return *x.ParentId
+	}
+	return 0
+}
+
+func (x *Comment) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *Comment) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Comment) GetEdited() bool {
+	if x != nil {
+		return x.Edited
+	}
+	return false
+}
+
+func (x *Comment) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *Comment) GetReactions() []*CommentReaction {
+	if x != nil {
+		return x.Reactions
+	}
+	return nil
+}
+
+func (x *Comment) GetReplies() []*Comment {
+	if x != nil {
+		return x.Replies
+	}
+	return nil
+}
+
+func (x *Comment) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Comment) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type CommentReaction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Emoji         string                 `protobuf:"bytes,1,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Actors        []string               `protobuf:"bytes,3,rep,name=actors,proto3" json:"actors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommentReaction) Reset() {
+	*x = CommentReaction{}
+	mi := &file_todo_v1_comment_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommentReaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommentReaction) ProtoMessage() {}
+
+func (x *CommentReaction) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_comment_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommentReaction.ProtoReflect.Descriptor instead.
+func (*CommentReaction) Descriptor() ([]byte, []int) {
+	return file_todo_v1_comment_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CommentReaction) GetEmoji() string {
+	if x != nil {
+		return x.Emoji
+	}
+	return ""
+}
+
+func (x *CommentReaction) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *CommentReaction) GetActors() []string {
+	if x != nil {
+		return x.Actors
+	}
+	return nil
+}
+
+type CommentEdit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Body          string                 `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+	Actor         string                 `protobuf:"bytes,2,opt,name=actor,proto3" json:"actor,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommentEdit) Reset() {
+	*x = CommentEdit{}
+	mi := &file_todo_v1_comment_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommentEdit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommentEdit) ProtoMessage() {}
+
+func (x *CommentEdit) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_comment_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommentEdit.ProtoReflect.Descriptor instead.
+func (*CommentEdit) Descriptor() ([]byte, []int) {
+	return file_todo_v1_comment_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CommentEdit) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *CommentEdit) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *CommentEdit) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ListCommentsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	TodoId int64                  `protobuf:"varint,1,opt,name=todo_id,json=todoId,proto3" json:"todo_id,omitempty"`
+	// from 1, the first page when 0
+	Page int32 `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	// at most 100, the default page size when 0
+	Size          int32 `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCommentsRequest) Reset() {
+	*x = ListCommentsRequest{}
+	mi := &file_todo_v1_comment_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCommentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCommentsRequest) ProtoMessage() {}
+
+func (x *ListCommentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_comment_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCommentsRequest.ProtoReflect.Descriptor instead.
+func (*ListCommentsRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_comment_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListCommentsRequest) GetTodoId() int64 {
+	if x != nil {
+		return x.TodoId
+	}
+	return 0
+}
+
+func (x *ListCommentsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListCommentsRequest) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type ListCommentsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Items []*Comment             `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Page  int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	Size  int32                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	// the number of threads
+	Total         int64 `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCommentsResponse) Reset() {
+	*x = ListCommentsResponse{}
+	mi := &file_todo_v1_comment_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCommentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCommentsResponse) ProtoMessage() {}
+
+func (x *ListCommentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_comment_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCommentsResponse.ProtoReflect.Descriptor instead.
+func (*ListCommentsResponse) Descriptor() ([]byte, []int) {
+	return file_todo_v1_comment_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListCommentsResponse) GetItems() []*Comment {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ListCommentsResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListCommentsResponse) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ListCommentsResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type CreateCommentRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	TodoId int64                  `protobuf:"varint,1,opt,name=todo_id,json=todoId,proto3" json:"todo_id,omitempty"`
+	// replies to another comment of the same todo
+	ParentId      *int64 `protobuf:"varint,2,opt,name=parent_id,json=parentId,proto3,oneof" json:"parent_id,omitempty"`
+	Body          string `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCommentRequest) Reset() {
+	*x = CreateCommentRequest{}
+	mi := &file_todo_v1_comment_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCommentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCommentRequest) ProtoMessage() {}
+
+func (x *CreateCommentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_comment_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCommentRequest.ProtoReflect.Descriptor instead.
+func (*CreateCommentRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_comment_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateCommentRequest) GetTodoId() int64 {
+	if x != nil {
+		return x.TodoId
+	}
+	return 0
+}
+
+func (x *CreateCommentRequest) GetParentId() int64 {
+	if x != nil && x.ParentId != nil {
+		return *x.ParentId
+	}
+	return 0
+}
+
+func (x *CreateCommentRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+type UpdateCommentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TodoId        int64                  `protobuf:"varint,1,opt,name=todo_id,json=todoId,proto3" json:"todo_id,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Body          string                 `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCommentRequest) Reset() {
+	*x = UpdateCommentRequest{}
+	mi := &file_todo_v1_comment_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCommentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCommentRequest) ProtoMessage() {}
+
+func (x *UpdateCommentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_comment_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCommentRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCommentRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_comment_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateCommentRequest) GetTodoId() int64 {
+	if x != nil {
+		return x.TodoId
+	}
+	return 0
+}
+
+func (x *UpdateCommentRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateCommentRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+type DeleteCommentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TodoId        int64                  `protobuf:"varint,1,opt,name=todo_id,json=todoId,proto3" json:"todo_id,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCommentRequest) Reset() {
+	*x = DeleteCommentRequest{}
+	mi := &file_todo_v1_comment_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCommentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCommentRequest) ProtoMessage() {}
+
+func (x *DeleteCommentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_comment_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCommentRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCommentRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_comment_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteCommentRequest) GetTodoId() int64 {
+	if x != nil {
+		return x.TodoId
+	}
+	return 0
+}
+
+func (x *DeleteCommentRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteCommentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCommentResponse) Reset() {
+	*x = DeleteCommentResponse{}
+	mi := &file_todo_v1_comment_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCommentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCommentResponse) ProtoMessage() {}
+
+func (x *DeleteCommentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_comment_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCommentResponse.ProtoReflect.Descriptor instead.
+func (*DeleteCommentResponse) Descriptor() ([]byte, []int) {
+	return file_todo_v1_comment_proto_rawDescGZIP(), []int{8}
+}
+
+type GetCommentHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TodoId        int64                  `protobuf:"varint,1,opt,name=todo_id,json=todoId,proto3" json:"todo_id,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCommentHistoryRequest) Reset() {
+	*x = GetCommentHistoryRequest{}
+	mi := &file_todo_v1_comment_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCommentHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCommentHistoryRequest) ProtoMessage() {}
+
+func (x *GetCommentHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_comment_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCommentHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetCommentHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_comment_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetCommentHistoryRequest) GetTodoId() int64 {
+	if x != nil {
+		return x.TodoId
+	}
+	return 0
+}
+
+func (x *GetCommentHistoryRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetCommentHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Edits         []*CommentEdit         `protobuf:"bytes,1,rep,name=edits,proto3" json:"edits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCommentHistoryResponse) Reset() {
+	*x = GetCommentHistoryResponse{}
+	mi := &file_todo_v1_comment_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCommentHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCommentHistoryResponse) ProtoMessage() {}
+
+func (x *GetCommentHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_comment_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCommentHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetCommentHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_todo_v1_comment_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetCommentHistoryResponse) GetEdits() []*CommentEdit {
+	if x != nil {
+		return x.Edits
+	}
+	return nil
+}
+
+type ReactToCommentRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	TodoId int64                  `protobuf:"varint,1,opt,name=todo_id,json=todoId,proto3" json:"todo_id,omitempty"`
+	Id     int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	// +1, -1, laugh, hooray, confused, heart, rocket or eyes
+	Emoji         string `protobuf:"bytes,3,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	Reacted       bool   `protobuf:"varint,4,opt,name=reacted,proto3" json:"reacted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReactToCommentRequest) Reset() {
+	*x = ReactToCommentRequest{}
+	mi := &file_todo_v1_comment_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReactToCommentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReactToCommentRequest) ProtoMessage() {}
+
+func (x *ReactToCommentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_comment_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReactToCommentRequest.ProtoReflect.Descriptor instead.
+func (*ReactToCommentRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_comment_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ReactToCommentRequest) GetTodoId() int64 {
+	if x != nil {
+		return x.TodoId
+	}
+	return 0
+}
+
+func (x *ReactToCommentRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ReactToCommentRequest) GetEmoji() string {
+	if x != nil {
+		return x.Emoji
+	}
+	return ""
+}
+
+func (x *ReactToCommentRequest) GetReacted() bool {
+	if x != nil {
+		return x.Reacted
+	}
+	return false
+}
+
+var File_todo_v1_comment_proto protoreflect.FileDescriptor
+
+const file_todo_v1_comment_proto_rawDesc = "" +
+	"\n" +
+	"\x15todo/v1/comment.proto\x12\atodo.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x98\x03\n" +
+	"\aComment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\atodo_id\x18\x02 \x01(\x03R\x06todoId\x12 \n" +
+	"\tparent_id\x18\x03 \x01(\x03H\x00R\bparentId\x88\x01\x01\x12\x14\n" +
+	"\x05actor\x18\x04 \x01(\tR\x05actor\x12\x12\n" +
+	"\x04body\x18\x05 \x01(\tR\x04body\x12\x16\n" +
+	"\x06edited\x18\x06 \x01(\bR\x06edited\x12\x18\n" +
+	"\adeleted\x18\a \x01(\bR\adeleted\x126\n" +
+	"\treactions\x18\b \x03(\v2\x18.todo.v1.CommentReactionR\treactions\x12*\n" +
+	"\areplies\x18\t \x03(\v2\x10.todo.v1.CommentR\areplies\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAtB\f\n" +
+	"\n" +
+	"_parent_id\"U\n" +
+	"\x0fCommentReaction\x12\x14\n" +
+	"\x05emoji\x18\x01 \x01(\tR\x05emoji\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\x12\x16\n" +
+	"\x06actors\x18\x03 \x03(\tR\x06actors\"r\n" +
+	"\vCommentEdit\x12\x12\n" +
+	"\x04body\x18\x01 \x01(\tR\x04body\x12\x14\n" +
+	"\x05actor\x18\x02 \x01(\tR\x05actor\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"V\n" +
+	"\x13ListCommentsRequest\x12\x17\n" +
+	"\atodo_id\x18\x01 \x01(\x03R\x06todoId\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x05R\x04size\"|\n" +
+	"\x14ListCommentsResponse\x12&\n" +
+	"\x05items\x18\x01 \x03(\v2\x10.todo.v1.CommentR\x05items\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x05R\x04size\x12\x14\n" +
+	"\x05total\x18\x04 \x01(\x03R\x05total\"s\n" +
+	"\x14CreateCommentRequest\x12\x17\n" +
+	"\atodo_id\x18\x01 \x01(\x03R\x06todoId\x12 \n" +
+	"\tparent_id\x18\x02 \x01(\x03H\x00R\bparentId\x88\x01\x01\x12\x12\n" +
+	"\x04body\x18\x03 \x01(\tR\x04bodyB\f\n" +
+	"\n" +
+	"_parent_id\"S\n" +
+	"\x14UpdateCommentRequest\x12\x17\n" +
+	"\atodo_id\x18\x01 \x01(\x03R\x06todoId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04body\x18\x03 \x01(\tR\x04body\"?\n" +
+	"\x14DeleteCommentRequest\x12\x17\n" +
+	"\atodo_id\x18\x01 \x01(\x03R\x06todoId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\"\x17\n" +
+	"\x15DeleteCommentResponse\"C\n" +
+	"\x18GetCommentHistoryRequest\x12\x17\n" +
+	"\atodo_id\x18\x01 \x01(\x03R\x06todoId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\"G\n" +
+	"\x19GetCommentHistoryResponse\x12*\n" +
+	"\x05edits\x18\x01 \x03(\v2\x14.todo.v1.CommentEditR\x05edits\"p\n" +
+	"\x15ReactToCommentRequest\x12\x17\n" +
+	"\atodo_id\x18\x01 \x01(\x03R\x06todoId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05emoji\x18\x03 \x01(\tR\x05emoji\x12\x18\n" +
+	"\areacted\x18\x04 \x01(\bR\areacted2\xd3\x05\n" +
+	"\x0eCommentService\x12n\n" +
+	"\fListComments\x12\x1c.todo.v1.ListCommentsRequest\x1a\x1d.todo.v1.ListCommentsResponse\"!\x82\xd3\xe4\x93\x02\x1b\x12\x19/todos/{todo_id}/comments\x12f\n" +
+	"\rCreateComment\x12\x1d.todo.v1.CreateCommentRequest\x1a\x10.todo.v1.Comment\"$\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/todos/{todo_id}/comments\x12k\n" +
+	"\rUpdateComment\x12\x1d.todo.v1.UpdateCommentRequest\x1a\x10.todo.v1.Comment\")\x82\xd3\xe4\x93\x02#:\x01*\x1a\x1e/todos/{todo_id}/comments/{id}\x12v\n" +
+	"\rDeleteComment\x12\x1d.todo.v1.DeleteCommentRequest\x1a\x1e.todo.v1.DeleteCommentResponse\"&\x82\xd3\xe4\x93\x02 *\x1e/todos/{todo_id}/comments/{id}\x12\x8a\x01\n" +
+	"\x11GetCommentHistory\x12!.todo.v1.GetCommentHistoryRequest\x1a\".todo.v1.GetCommentHistoryResponse\".\x82\xd3\xe4\x93\x02(\x12&/todos/{todo_id}/comments/{id}/history\x12w\n" +
+	"\x0eReactToComment\x12\x1e.todo.v1.ReactToCommentRequest\x1a\x10.todo.v1.Comment\"3\x82\xd3\xe4\x93\x02-:\x01*\x1a(/todos/{todo_id}/comments/{id}/reactionsB!Z\x1ftodo-app-api/gen/todo/v1;todov1b\x06proto3"
+
+var (
+	file_todo_v1_comment_proto_rawDescOnce sync.Once
+	file_todo_v1_comment_proto_rawDescData []byte
+)
+
+func file_todo_v1_comment_proto_rawDescGZIP() []byte {
+	file_todo_v1_comment_proto_rawDescOnce.Do(func() {
+		file_todo_v1_comment_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_todo_v1_comment_proto_rawDesc), len(file_todo_v1_comment_proto_rawDesc)))
+	})
+	return file_todo_v1_comment_proto_rawDescData
+}
+
+var file_todo_v1_comment_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_todo_v1_comment_proto_goTypes = []any{
+	(*Comment)(nil),                   // 0: todo.v1.Comment
+	(*CommentReaction)(nil),           // 1: todo.v1.CommentReaction
+	(*CommentEdit)(nil),               // 2: todo.v1.CommentEdit
+	(*ListCommentsRequest)(nil),       // 3: todo.v1.ListCommentsRequest
+	(*ListCommentsResponse)(nil),      // 4: todo.v1.ListCommentsResponse
+	(*CreateCommentRequest)(nil),      // 5: todo.v1.CreateCommentRequest
+	(*UpdateCommentRequest)(nil),      // 6: todo.v1.UpdateCommentRequest
+	(*DeleteCommentRequest)(nil),      // 7: todo.v1.DeleteCommentRequest
+	(*DeleteCommentResponse)(nil),     // 8: todo.v1.DeleteCommentResponse
+	(*GetCommentHistoryRequest)(nil),  // 9: todo.v1.GetCommentHistoryRequest
+	(*GetCommentHistoryResponse)(nil), // 10: todo.v1.GetCommentHistoryResponse
+	(*ReactToCommentRequest)(nil),     // 11: todo.v1.ReactToCommentRequest
+	(*timestamppb.Timestamp)(nil),     // 12: google.protobuf.Timestamp
+}
+var file_todo_v1_comment_proto_depIdxs = []int32{
+	1,  // 0: todo.v1.Comment.reactions:type_name -> todo.v1.CommentReaction
+	0,  // 1: todo.v1.Comment.replies:type_name -> todo.v1.Comment
+	12, // 2: todo.v1.Comment.created_at:type_name -> google.protobuf.Timestamp
+	12, // 3: todo.v1.Comment.updated_at:type_name -> google.protobuf.Timestamp
+	12, // 4: todo.v1.CommentEdit.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 5: todo.v1.ListCommentsResponse.items:type_name -> todo.v1.Comment
+	2,  // 6: todo.v1.GetCommentHistoryResponse.edits:type_name -> todo.v1.CommentEdit
+	3,  // 7: todo.v1.CommentService.ListComments:input_type -> todo.v1.ListCommentsRequest
+	5,  // 8: todo.v1.CommentService.CreateComment:input_type -> todo.v1.CreateCommentRequest
+	6,  // 9: todo.v1.CommentService.UpdateComment:input_type -> todo.v1.UpdateCommentRequest
+	7,  // 10: todo.v1.CommentService.DeleteComment:input_type -> todo.v1.DeleteCommentRequest
+	9,  // 11: todo.v1.CommentService.GetCommentHistory:input_type -> todo.v1.GetCommentHistoryRequest
+	11, // 12: todo.v1.CommentService.ReactToComment:input_type -> todo.v1.ReactToCommentRequest
+	4,  // 13: todo.v1.CommentService.ListComments:output_type -> todo.v1.ListCommentsResponse
+	0,  // 14: todo.v1.CommentService.CreateComment:output_type -> todo.v1.Comment
+	0,  // 15: todo.v1.CommentService.UpdateComment:output_type -> todo.v1.Comment
+	8,  // 16: todo.v1.CommentService.DeleteComment:output_type -> todo.v1.DeleteCommentResponse
+	10, // 17: todo.v1.CommentService.GetCommentHistory:output_type -> todo.v1.GetCommentHistoryResponse
+	0,  // 18: todo.v1.CommentService.ReactToComment:output_type -> todo.v1.Comment
+	13, // [13:19] is the sub-list for method output_type
+	7,  // [7:13] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_todo_v1_comment_proto_init() }
+func file_todo_v1_comment_proto_init() {
+	if File_todo_v1_comment_proto != nil {
+		return
+	}
+	file_todo_v1_comment_proto_msgTypes[0].OneofWrappers = []any{}
+	file_todo_v1_comment_proto_msgTypes[5].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_todo_v1_comment_proto_rawDesc), len(file_todo_v1_comment_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_todo_v1_comment_proto_goTypes,
+		DependencyIndexes: file_todo_v1_comment_proto_depIdxs,
+		MessageInfos:      file_todo_v1_comment_proto_msgTypes,
+	}.Build()
+	File_todo_v1_comment_proto = out.File
+	file_todo_v1_comment_proto_goTypes = nil
+	file_todo_v1_comment_proto_depIdxs = nil
+}
@@ -0,0 +1,162 @@
+package graph
+
+import (
+	"context"
+	"todo-app-api/models/web"
+	"todo-app-api/service"
+	"todo-app-api/stream"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+type loadersKey struct{}
+
+type loaders struct {
+	todos     *Loader[int, web.TodoResponse]
+	histories *Loader[int, []web.TodoRevisionResponse]
+}
+
+func newLoaders(ctx context.Context, todoService service.TodoService) loaders {
+	return loaders{
+		todos: NewLoader(func(todoIds []int) map[int]web.TodoResponse {
+			todos := map[int]web.TodoResponse{}
+			for _, todo := range todoService.FindByIds(ctx, todoIds) {
+				todos[todo.Id] = todo
+			}
+			return todos
+		}),
+		histories: NewLoader(func(todoIds []int) map[int][]web.TodoRevisionResponse {
+			return todoService.HistoryByTodoIds(ctx, todoIds)
+		}),
+	}
+}
+
+func withLoaders(ctx context.Context, todoService service.TodoService) context.Context {
+	current := newLoaders(ctx, todoService)
+	return context.WithValue(ctx, loadersKey{}, &current)
+}
+
+// resetLoaders drops what the loaders cached. A subscription calls it before
+// each event since its context, and so its loaders, live as long as it does.
+func resetLoaders(ctx context.Context, todoService service.TodoService) {
+	*loadersFrom(ctx) = newLoaders(ctx, todoService)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// Executor runs GraphQL requests against the todo schema after checking
+// them against its Limits.
+type Executor struct {
+	schema      graphql.Schema
+	limits      Limits
+	todoService service.TodoService
+}
+
+func NewExecutor(todoService service.TodoService, hub *stream.Hub, limits Limits) (*Executor, error) {
+	schema, err := NewSchema(todoService, hub)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Executor{
+		schema:      schema,
+		limits:      limits,
+		todoService: todoService,
+	}, nil
+}
+
+// prepare parses and validates request and selects its operation. The
+// errors are those GraphQL would report without running anything.
+func (executor *Executor) prepare(request web.GraphQLRequest) (*ast.Document, *ast.OperationDefinition, []gqlerrors.FormattedError) {
+	document, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(request.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		return nil, nil, gqlerrors.FormatErrors(err)
+	}
+
+	if validation := graphql.ValidateDocument(&executor.schema, document, nil); !validation.IsValid {
+		return nil, nil, validation.Errors
+	}
+
+	var operation *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		candidate, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if request.OperationName == "" && operation != nil {
+			return nil, nil, []gqlerrors.FormattedError{gqlerrors.NewFormattedError("operationName is required when the document has several operations")}
+		}
+		if request.OperationName == "" || (candidate.Name != nil && candidate.Name.Value == request.OperationName) {
+			operation = candidate
+		}
+	}
+	if operation == nil {
+		return nil, nil, []gqlerrors.FormattedError{gqlerrors.NewFormattedError("unknown operation " + request.OperationName)}
+	}
+
+	if err := executor.limits.Check(executor.schema, document, operation); err != nil {
+		tooComplex := Error{Message: err.Error(), Code: "QUERY_TOO_COMPLEX"}
+		return nil, nil, []gqlerrors.FormattedError{{Message: tooComplex.Message, Extensions: tooComplex.Extensions()}}
+	}
+	return document, operation, nil
+}
+
+// Execute runs a query or mutation. Subscriptions have to go through
+// Subscribe.
+func (executor *Executor) Execute(ctx context.Context, request web.GraphQLRequest) *graphql.Result {
+	document, operation, errs := executor.prepare(request)
+	if errs != nil {
+		return &graphql.Result{Errors: errs}
+	}
+	if operation.Operation == ast.OperationTypeSubscription {
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError("subscriptions are only served over the WebSocket at /graphql/ws")}}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        executor.schema,
+		AST:           document,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       withLoaders(ctx, executor.todoService),
+	})
+}
+
+// Subscribe runs request until ctx ends, or reports why it cannot run it at
+// all. A query or mutation yields a single result. The channel has to be
+// drained until it is closed.
+func (executor *Executor) Subscribe(ctx context.Context, request web.GraphQLRequest) (<-chan *graphql.Result, []gqlerrors.FormattedError) {
+	document, operation, errs := executor.prepare(request)
+	if errs != nil {
+		return nil, errs
+	}
+	if operation.Operation != ast.OperationTypeSubscription {
+		results := make(chan *graphql.Result, 1)
+		results <- executor.Execute(ctx, request)
+		close(results)
+		return results, nil
+	}
+
+	results := make(chan *graphql.Result)
+	go func() {
+		defer close(results)
+		for result := range graphql.ExecuteSubscription(graphql.ExecuteParams{
+			Schema:        executor.schema,
+			AST:           document,
+			OperationName: request.OperationName,
+			Args:          request.Variables,
+			Context:       withLoaders(ctx, executor.todoService),
+		}) {
+			results <- result
+		}
+	}()
+	return results, nil
+}
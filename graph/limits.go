@@ -0,0 +1,201 @@
+package graph
+
+import (
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// listCost is what a list field is assumed to multiply the cost of its
+// selection by, since its length is unknown before it is resolved.
+const listCost = 10
+
+// maxCost is where complexities stop growing. Fragments spreading each other
+// twice over can ask for more than an int holds; anything at maxCost is far
+// past every limit anyway.
+const maxCost = 1 << 40
+
+// Limits bound a single operation before it runs. Every field costs one and
+// the selection of a list field costs listCost times its own cost, so
+// todos { history { todo { title } } } is far more expensive than it looks.
+// The introspection fields __schema and __type are measured the same way but
+// against limits of their own, since the query GraphiQL loads the schema
+// with is deeper and larger than any query for data has to be.
+type Limits struct {
+	MaxDepth                   int
+	MaxComplexity              int
+	MaxIntrospectionDepth      int
+	MaxIntrospectionComplexity int
+}
+
+type measurement struct {
+	schema        graphql.Schema
+	fragments     map[string]*ast.FragmentDefinition
+	visiting      map[string]bool
+	introspection *cost
+	// spreads remembers what a fragment costs on a parent type, so a
+	// fragment spread many times over is walked once
+	spreads map[spreadKey]spreadCost
+}
+
+type spreadKey struct {
+	fragment string
+	parent   string
+}
+
+type spreadCost struct {
+	cost
+	// introspectionComplexity is what the fragment added to the
+	// introspection tally; the depth it reached is already counted
+	introspectionComplexity int
+}
+
+type cost struct {
+	depth      int
+	complexity int
+}
+
+// Check measures operation, which must come from document, and reports the
+// first limit it exceeds.
+func (limits Limits) Check(schema graphql.Schema, document *ast.Document, operation *ast.OperationDefinition) error {
+	m := measurement{
+		schema:        schema,
+		fragments:     map[string]*ast.FragmentDefinition{},
+		visiting:      map[string]bool{},
+		introspection: &cost{},
+		spreads:       map[spreadKey]spreadCost{},
+	}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			m.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	depth, complexity := m.selectionSet(operation.SelectionSet, rootType(schema, operation))
+	if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", depth, limits.MaxDepth)
+	}
+	if limits.MaxComplexity > 0 && complexity > limits.MaxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the limit of %d", complexity, limits.MaxComplexity)
+	}
+	if limits.MaxIntrospectionDepth > 0 && m.introspection.depth > limits.MaxIntrospectionDepth {
+		return fmt.Errorf("introspection depth %d exceeds the limit of %d", m.introspection.depth, limits.MaxIntrospectionDepth)
+	}
+	if limits.MaxIntrospectionComplexity > 0 && m.introspection.complexity > limits.MaxIntrospectionComplexity {
+		return fmt.Errorf("introspection complexity %d exceeds the limit of %d", m.introspection.complexity, limits.MaxIntrospectionComplexity)
+	}
+	return nil
+}
+
+func rootType(schema graphql.Schema, operation *ast.OperationDefinition) graphql.Type {
+	switch operation.Operation {
+	case ast.OperationTypeMutation:
+		return schema.MutationType()
+	case ast.OperationTypeSubscription:
+		return schema.SubscriptionType()
+	}
+	return schema.QueryType()
+}
+
+func (m measurement) selectionSet(selectionSet *ast.SelectionSet, parent graphql.Type) (int, int) {
+	if selectionSet == nil {
+		return 0, 0
+	}
+
+	depth, complexity := 0, 0
+	for _, selection := range selectionSet.Selections {
+		var selectionDepth, selectionComplexity int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			selectionDepth, selectionComplexity = m.field(selection, parent)
+		case *ast.InlineFragment:
+			selectionDepth, selectionComplexity = m.selectionSet(selection.SelectionSet, m.condition(selection.TypeCondition, parent))
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := m.fragments[name]
+			// cycles are reported by validation, not here
+			if !ok || m.visiting[name] {
+				continue
+			}
+			selectionDepth, selectionComplexity = m.spread(name, fragment, parent)
+		}
+		depth = max(depth, selectionDepth)
+		complexity = min(complexity+selectionComplexity, maxCost)
+	}
+	return depth, complexity
+}
+
+func (m measurement) spread(name string, fragment *ast.FragmentDefinition, parent graphql.Type) (int, int) {
+	key := spreadKey{fragment: name}
+	if parent != nil {
+		key.parent = parent.Name()
+	}
+	if known, ok := m.spreads[key]; ok {
+		m.introspection.complexity = min(m.introspection.complexity+known.introspectionComplexity, maxCost)
+		return known.depth, known.complexity
+	}
+
+	introspectionComplexity := m.introspection.complexity
+	m.visiting[name] = true
+	depth, complexity := m.selectionSet(fragment.SelectionSet, m.condition(fragment.TypeCondition, parent))
+	delete(m.visiting, name)
+
+	m.spreads[key] = spreadCost{
+		cost:                    cost{depth: depth, complexity: complexity},
+		introspectionComplexity: m.introspection.complexity - introspectionComplexity,
+	}
+	return depth, complexity
+}
+
+func (m measurement) field(field *ast.Field, parent graphql.Type) (int, int) {
+	var fieldType graphql.Type
+	switch field.Name.Value {
+	case graphql.SchemaMetaFieldDef.Name, graphql.TypeMetaFieldDef.Name:
+		// only offered on the query root, where they are tallied apart from
+		// the data the operation asks for
+		fieldType = graphql.SchemaType
+		if field.Name.Value == graphql.TypeMetaFieldDef.Name {
+			fieldType = graphql.TypeType
+		}
+		depth, complexity := m.selectionSet(field.SelectionSet, fieldType)
+		m.introspection.depth = max(m.introspection.depth, depth+1)
+		m.introspection.complexity = min(m.introspection.complexity+1+complexity, maxCost)
+		return 0, 0
+	case graphql.TypeNameMetaFieldDef.Name:
+		return 1, 1
+	}
+
+	if object, ok := parent.(*graphql.Object); ok {
+		if definition, ok := object.Fields()[field.Name.Value]; ok {
+			fieldType = definition.Type
+		}
+	}
+
+	multiplier := 1
+	for {
+		if nonNull, ok := fieldType.(*graphql.NonNull); ok {
+			fieldType = nonNull.OfType
+			continue
+		}
+		if list, ok := fieldType.(*graphql.List); ok {
+			multiplier = listCost
+			fieldType = list.OfType
+			continue
+		}
+		break
+	}
+
+	depth, complexity := m.selectionSet(field.SelectionSet, fieldType)
+	return depth + 1, min(1+multiplier*complexity, maxCost)
+}
+
+func (m measurement) condition(condition *ast.Named, parent graphql.Type) graphql.Type {
+	if condition == nil || condition.Name == nil {
+		return parent
+	}
+	if named := m.schema.Type(condition.Name.Value); named != nil {
+		return named
+	}
+	return parent
+}
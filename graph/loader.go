@@ -0,0 +1,70 @@
+package graph
+
+import "sync"
+
+// Loader batches the keys requested while one level of a query resolves
+// into a single call, so listing todos with their history costs one query
+// for the todos and one for all histories instead of one per todo. Results
+// are cached, so a Loader must not outlive its request.
+type Loader[K comparable, V any] struct {
+	batch func(keys []K) map[K]V
+	// dispatching keeps a thunk from reading results while another one is
+	// still fetching its key
+	dispatching sync.Mutex
+	mutex       sync.Mutex
+	pending     []K
+	queued      map[K]bool
+	results     map[K]V
+}
+
+func NewLoader[K comparable, V any](batch func(keys []K) map[K]V) *Loader[K, V] {
+	return &Loader[K, V]{
+		batch:   batch,
+		queued:  map[K]bool{},
+		results: map[K]V{},
+	}
+}
+
+// Load queues key and returns a thunk for it. The first thunk called fetches
+// every key queued so far; ok is false when the batch had no value for key.
+func (loader *Loader[K, V]) Load(key K) func() (value V, ok bool) {
+	loader.mutex.Lock()
+	if !loader.queued[key] {
+		loader.queued[key] = true
+		loader.pending = append(loader.pending, key)
+	}
+	loader.mutex.Unlock()
+
+	return func() (V, bool) {
+		loader.dispatch()
+
+		loader.mutex.Lock()
+		defer loader.mutex.Unlock()
+		value, ok := loader.results[key]
+		return value, ok
+	}
+}
+
+func (loader *Loader[K, V]) dispatch() {
+	loader.dispatching.Lock()
+	defer loader.dispatching.Unlock()
+
+	loader.mutex.Lock()
+	keys := loader.pending
+	loader.pending = nil
+	loader.mutex.Unlock()
+
+	if len(keys) == 0 {
+		return
+	}
+
+	values := loader.batch(keys)
+
+	loader.mutex.Lock()
+	defer loader.mutex.Unlock()
+	for _, key := range keys {
+		if value, ok := values[key]; ok {
+			loader.results[key] = value
+		}
+	}
+}
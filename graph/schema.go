@@ -0,0 +1,335 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"todo-app-api/event"
+	"todo-app-api/exception"
+	"todo-app-api/models/web"
+	"todo-app-api/service"
+	"todo-app-api/stream"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/graphql-go/graphql"
+)
+
+// Error carries a machine readable code in the extensions of a GraphQL
+// error, the way the REST API uses the status code.
+type Error struct {
+	Message string
+	Code    string
+}
+
+func (e Error) Error() string {
+	return e.Message
+}
+
+func (e Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
+
+// capture turns the panics TodoService reports errors with into an Error.
+func capture(resolve func() interface{}) (result interface{}, err error) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+
+		var validationErrors validator.ValidationErrors
+		var notFound exception.NotFoundError
+		var conflict exception.ConflictError
+		var fiberError *fiber.Error
+		switch value := recovered.(type) {
+		case error:
+			switch {
+			case errors.As(value, &notFound):
+				err = Error{Message: notFound.Message, Code: "NOT_FOUND"}
+			case errors.As(value, &conflict):
+				err = Error{Message: conflict.Message, Code: "CONFLICT"}
+			case errors.As(value, &validationErrors):
+				err = Error{Message: validationErrors.Error(), Code: "BAD_USER_INPUT"}
+			case errors.As(value, &fiberError) && fiberError.Code == fiber.StatusBadRequest:
+				err = Error{Message: fiberError.Message, Code: "BAD_USER_INPUT"}
+			case errors.As(value, &fiberError) && fiberError.Code == fiber.StatusNotFound:
+				err = Error{Message: fiberError.Message, Code: "NOT_FOUND"}
+			default:
+				err = Error{Message: value.Error(), Code: "INTERNAL_SERVER_ERROR"}
+			}
+		default:
+			err = Error{Message: fmt.Sprint(value), Code: "INTERNAL_SERVER_ERROR"}
+		}
+	}()
+
+	return resolve(), nil
+}
+
+func guard(resolve func(p graphql.ResolveParams) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return capture(func() interface{} { return resolve(p) })
+	}
+}
+
+// timestamp leaves out times the source did not carry, such as those of a
+// todo decoded from an event payload.
+func timestamp(value time.Time) interface{} {
+	if value.IsZero() {
+		return nil
+	}
+	return value
+}
+
+var todoStatus = graphql.NewEnum(graphql.EnumConfig{
+	Name: "TodoStatus",
+	Values: graphql.EnumValueConfigMap{
+		"PENDING": &graphql.EnumValueConfig{Value: "pending"},
+		"DONE":    &graphql.EnumValueConfig{Value: "done"},
+	},
+})
+
+var todoInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "TodoInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"title":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"description": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"status":      &graphql.InputObjectFieldConfig{Type: todoStatus},
+	},
+})
+
+var fieldChangeType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "FieldChange",
+	Fields: graphql.Fields{
+		"field": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"old":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"new":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+// NewSchema builds the schema over todoService, publishing subscription
+// events from hub. Every resolver goes through todoService; nested todos and
+// histories are batched by the loaders Execute puts in the context.
+func NewSchema(todoService service.TodoService, hub *stream.Hub) (graphql.Schema, error) {
+	todoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Todo",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"title":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"status":      &graphql.Field{Type: graphql.NewNonNull(todoStatus)},
+			"createdAt": &graphql.Field{Type: graphql.DateTime, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return timestamp(p.Source.(web.TodoResponse).CreatedAt), nil
+			}},
+			"updatedAt": &graphql.Field{Type: graphql.DateTime, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return timestamp(p.Source.(web.TodoResponse).UpdatedAt), nil
+			}},
+		},
+	})
+
+	revisionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoRevision",
+		Fields: graphql.Fields{
+			"revision": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"todoId": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(web.TodoRevisionResponse).TodoId, nil
+			}},
+			"action":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"actor":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"changes": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(fieldChangeType)))},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(web.TodoRevisionResponse).CreatedAt, nil
+			}},
+			"todo": &graphql.Field{
+				Type:        todoType,
+				Description: "The todo as it is now, null once it has been deleted.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					load := loadersFrom(p.Context).todos.Load(p.Source.(web.TodoRevisionResponse).TodoId)
+					return func() (interface{}, error) {
+						return capture(func() interface{} {
+							if todo, ok := load(); ok {
+								return todo
+							}
+							return nil
+						})
+					}, nil
+				},
+			},
+		},
+	})
+
+	// added once both types exist, they refer to each other
+	todoType.AddFieldConfig("history", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(revisionType))),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			load := loadersFrom(p.Context).histories.Load(p.Source.(web.TodoResponse).Id)
+			return func() (interface{}, error) {
+				return capture(func() interface{} {
+					history, _ := load()
+					if history == nil {
+						return []web.TodoRevisionResponse{}
+					}
+					return history
+				})
+			}, nil
+		},
+	})
+
+	eventType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoEvent",
+		Fields: graphql.Fields{
+			"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"type": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"todoId": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(event.Event).TodoId, nil
+			}},
+			"todo": &graphql.Field{
+				Type:        graphql.NewNonNull(todoType),
+				Description: "The todo as the event left it.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(event.Event).Data, nil
+				},
+			},
+			"occurredAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(event.Event).OccurredAt, nil
+			}},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"todos": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(todoType))),
+				Resolve: guard(func(p graphql.ResolveParams) interface{} {
+					return todoService.FindAll(p.Context, web.TodoFilterRequest{})
+				}),
+			},
+			"todo": &graphql.Field{
+				Type: todoType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: guard(func(p graphql.ResolveParams) interface{} {
+					return todoService.FindById(p.Context, p.Args["id"].(int))
+				}),
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(todoInput)},
+				},
+				Resolve: guard(func(p graphql.ResolveParams) interface{} {
+					title, description, status := input(p.Args["input"])
+					return todoService.Create(p.Context, web.TodoCreateRequest{Title: title, Description: description, Status: status})
+				}),
+			},
+			"updateTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(todoInput)},
+				},
+				Resolve: guard(func(p graphql.ResolveParams) interface{} {
+					title, description, status := input(p.Args["input"])
+					return todoService.Update(p.Context, web.TodoUpdateRequest{Id: p.Args["id"].(int), Title: title, Description: description, Status: status})
+				}),
+			},
+			"deleteTodo": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: guard(func(p graphql.ResolveParams) interface{} {
+					todoService.Delete(p.Context, p.Args["id"].(int))
+					return true
+				}),
+			},
+			"revertTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"id":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"revision": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: guard(func(p graphql.ResolveParams) interface{} {
+					return todoService.Revert(p.Context, p.Args["id"].(int), p.Args["revision"].(int))
+				}),
+			},
+		},
+	})
+
+	subscription := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"todoEvents": &graphql.Field{
+				Type:        graphql.NewNonNull(eventType),
+				Description: "Todo events from now on, optionally only those of one todo.",
+				Args: graphql.FieldConfigArgument{
+					"todoId": &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+					return subscribe(p.Context, hub, p.Args["todoId"]), nil
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					// events are resolved one after another, so nothing
+					// still reads the previous loaders
+					resetLoaders(p.Context, todoService)
+					return p.Source, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:        query,
+		Mutation:     mutation,
+		Subscription: subscription,
+	})
+}
+
+func input(value interface{}) (title string, description string, status string) {
+	fields := value.(map[string]interface{})
+	title, _ = fields["title"].(string)
+	description, _ = fields["description"].(string)
+	status, _ = fields["status"].(string)
+	return title, description, status
+}
+
+// subscribe forwards hub events until ctx ends or the subscriber lags behind,
+// in which case the subscription completes and the client resubscribes.
+func subscribe(ctx context.Context, hub *stream.Hub, todoId interface{}) chan interface{} {
+	var filter func(evt event.Event) bool
+	if id, ok := todoId.(int); ok {
+		filter = func(evt event.Event) bool { return evt.TodoId == id }
+	}
+	subscription, _, _ := hub.Subscribe(0, filter)
+
+	events := make(chan interface{})
+	go func() {
+		defer close(events)
+		defer hub.Unsubscribe(subscription)
+
+		for {
+			select {
+			case message := <-subscription.Messages:
+				select {
+				case events <- message.Event:
+				case <-ctx.Done():
+					return
+				}
+			case <-subscription.Lagged:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events
+}
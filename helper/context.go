@@ -0,0 +1,79 @@
+package helper
+
+import "context"
+
+type actorKey struct{}
+
+const AnonymousActor = "anonymous"
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
+
+type requestIdKey struct{}
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+func RequestIdFromContext(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdKey{}).(string)
+	return requestId
+}
+
+type apiVersionKey struct{}
+
+// WithAPIVersion records the API version a request negotiated, which decides
+// the shape of its response.
+func WithAPIVersion(ctx context.Context, version int) context.Context {
+	return context.WithValue(ctx, apiVersionKey{}, version)
+}
+
+// APIVersionFromContext defaults to version 1, the contract that predates
+// versioning.
+func APIVersionFromContext(ctx context.Context) int {
+	if version, ok := ctx.Value(apiVersionKey{}).(int); ok {
+		return version
+	}
+	return 1
+}
+
+type clientKey struct{}
+
+// WithClient names the client a request comes from, used to keep a client
+// reading its own writes.
+func WithClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+func ClientFromContext(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
+
+// AuditTarget is filled in by the service layer while a request is handled so
+// the audit middleware can name the action and resource it affected.
+type AuditTarget struct {
+	Action   string
+	Resource string
+}
+
+type auditTargetKey struct{}
+
+func WithAuditTarget(ctx context.Context, target *AuditTarget) context.Context {
+	return context.WithValue(ctx, auditTargetKey{}, target)
+}
+
+func SetAuditTarget(ctx context.Context, action string, resource string) {
+	if target, ok := ctx.Value(auditTargetKey{}).(*AuditTarget); ok {
+		target.Action = action
+		target.Resource = resource
+	}
+}
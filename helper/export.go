@@ -0,0 +1,200 @@
+package helper
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app-api/models/web"
+)
+
+// TodoExportColumns are the columns of an export, in their default order.
+var TodoExportColumns = []string{"id", "title", "description", "status", "created_at", "updated_at"}
+
+// TodoExportContentTypes maps each export format to its media type.
+var TodoExportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"json":   "application/json",
+	"ndjson": "application/x-ndjson",
+}
+
+// ParseExportColumns reads a comma separated column list, falling back to
+// every column when it is empty.
+func ParseExportColumns(columns string) ([]string, error) {
+	if strings.TrimSpace(columns) == "" {
+		return TodoExportColumns, nil
+	}
+
+	var result []string
+	for _, column := range strings.Split(columns, ",") {
+		column = strings.TrimSpace(column)
+		if !slices.Contains(TodoExportColumns, column) {
+			return nil, fmt.Errorf("unknown column %q, expected some of %s", column, strings.Join(TodoExportColumns, ","))
+		}
+		if slices.Contains(result, column) {
+			return nil, fmt.Errorf("column %q is listed twice", column)
+		}
+		result = append(result, column)
+	}
+	return result, nil
+}
+
+// TodoExporter writes todos one at a time in an export format. Close ends the
+// document and must be called once all todos are written.
+type TodoExporter interface {
+	Write(todo web.TodoResponse) error
+	Close() error
+}
+
+func NewTodoExporter(format string, columns []string, w io.Writer) TodoExporter {
+	switch format {
+	case "json":
+		return &jsonExporter{w: w, columns: columns}
+	case "ndjson":
+		return &ndjsonExporter{w: w, columns: columns}
+	default:
+		writer := csv.NewWriter(w)
+		// RFC 4180 ends records with CRLF
+		writer.UseCRLF = true
+		return &csvExporter{writer: writer, columns: columns}
+	}
+}
+
+func exportValue(todo web.TodoResponse, column string) any {
+	switch column {
+	case "id":
+		return todo.Id
+	case "title":
+		return todo.Title
+	case "description":
+		return todo.Description
+	case "status":
+		return todo.Status
+	case "created_at":
+		return todo.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case "updated_at":
+		return todo.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return nil
+}
+
+type csvExporter struct {
+	writer  *csv.Writer
+	columns []string
+	started bool
+}
+
+// header writes the header row ahead of the first record, or alone for an
+// empty export.
+func (exporter *csvExporter) header() error {
+	if exporter.started {
+		return nil
+	}
+	exporter.started = true
+	return exporter.writer.Write(exporter.columns)
+}
+
+func (exporter *csvExporter) Write(todo web.TodoResponse) error {
+	if err := exporter.header(); err != nil {
+		return err
+	}
+
+	record := make([]string, len(exporter.columns))
+	for i, column := range exporter.columns {
+		switch value := exportValue(todo, column).(type) {
+		case int:
+			record[i] = strconv.Itoa(value)
+		case string:
+			record[i] = csvText(value)
+		}
+	}
+	return exporter.writer.Write(record)
+}
+
+// csvText keeps a spreadsheet from evaluating text as a formula: a cell
+// starting with one of these characters is prefixed with a quote, which
+// Excel and Sheets show as plain text.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (exporter *csvExporter) Close() error {
+	if err := exporter.header(); err != nil {
+		return err
+	}
+	exporter.writer.Flush()
+	return exporter.writer.Error()
+}
+
+// writeExportObject writes todo as a JSON object whose keys follow the order
+// of columns.
+func writeExportObject(w io.Writer, todo web.TodoResponse, columns []string) error {
+	var object strings.Builder
+	object.WriteByte('{')
+	for i, column := range columns {
+		if i > 0 {
+			object.WriteByte(',')
+		}
+		value, err := json.Marshal(exportValue(todo, column))
+		if err != nil {
+			return err
+		}
+		object.WriteString(strconv.Quote(column))
+		object.WriteByte(':')
+		object.Write(value)
+	}
+	object.WriteByte('}')
+	_, err := io.WriteString(w, object.String())
+	return err
+}
+
+type jsonExporter struct {
+	w       io.Writer
+	columns []string
+	count   int
+}
+
+func (exporter *jsonExporter) Write(todo web.TodoResponse) error {
+	separator := ",\n"
+	if exporter.count == 0 {
+		separator = "[\n"
+	}
+	exporter.count++
+	if _, err := io.WriteString(exporter.w, separator); err != nil {
+		return err
+	}
+	return writeExportObject(exporter.w, todo, exporter.columns)
+}
+
+func (exporter *jsonExporter) Close() error {
+	end := "\n]\n"
+	if exporter.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(exporter.w, end)
+	return err
+}
+
+type ndjsonExporter struct {
+	w       io.Writer
+	columns []string
+}
+
+func (exporter *ndjsonExporter) Write(todo web.TodoResponse) error {
+	if err := writeExportObject(exporter.w, todo, exporter.columns); err != nil {
+		return err
+	}
+	_, err := io.WriteString(exporter.w, "\n")
+	return err
+}
+
+func (exporter *ndjsonExporter) Close() error {
+	return nil
+}
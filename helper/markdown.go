@@ -0,0 +1,20 @@
+package helper
+
+import (
+	"todo-app-api/markdown"
+	"todo-app-api/models/web"
+)
+
+// RenderTodoResponse fills in the sanitized HTML of the Markdown
+// description.
+func RenderTodoResponse(todo web.TodoResponse) web.TodoResponse {
+	todo.DescriptionHtml = markdown.Render(todo.Description)
+	return todo
+}
+
+func RenderTodoResponses(todos []web.TodoResponse) []web.TodoResponse {
+	for i := range todos {
+		todos[i] = RenderTodoResponse(todos[i])
+	}
+	return todos
+}
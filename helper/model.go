@@ -1,6 +1,7 @@
 package helper
 
 import (
+	"database/sql"
 	"todo-app-api/models/domain"
 	"todo-app-api/models/web"
 )
@@ -11,6 +12,8 @@ func ToTodoResponse(todo domain.Todo) web.TodoResponse {
 		Title:       todo.Title,
 		Description: todo.Description,
 		Status:      todo.Status,
+		CreatedAt:   todo.CreatedAt,
+		UpdatedAt:   todo.UpdatedAt,
 	}
 }
 
@@ -22,3 +25,197 @@ func ToTodoResponses(todos []domain.Todo) []web.TodoResponse {
 
 	return todoResponses
 }
+
+// IncludeCommentCount shows the comment count in the v1 shape of todo.
+func IncludeCommentCount(todo web.TodoResponse) web.TodoResponse {
+	commentCount := todo.CommentCount
+	todo.IncludedCommentCount = &commentCount
+	return todo
+}
+
+func IncludeCommentCounts(todos []web.TodoResponse) []web.TodoResponse {
+	for i := range todos {
+		todos[i] = IncludeCommentCount(todos[i])
+	}
+	return todos
+}
+
+func ToTodoResponseV2(todo web.TodoResponse) web.TodoResponseV2 {
+	return web.TodoResponseV2{
+		Id:              todo.Id,
+		Title:           todo.Title,
+		Description:     todo.Description,
+		Status:          todo.Status,
+		DescriptionHtml: todo.DescriptionHtml,
+		CreatedAt:       todo.CreatedAt,
+		UpdatedAt:       todo.UpdatedAt,
+		CommentCount:    todo.CommentCount,
+	}
+}
+
+func ToTodoResponsesV2(todos []web.TodoResponse) []web.TodoResponseV2 {
+	todoResponses := []web.TodoResponseV2{}
+	for _, todo := range todos {
+		todoResponses = append(todoResponses, ToTodoResponseV2(todo))
+	}
+
+	return todoResponses
+}
+
+func ToTodoRevisionResponse(revision domain.TodoRevision) web.TodoRevisionResponse {
+	changes := []web.FieldChangeResponse{}
+	for _, change := range revision.Changes {
+		changes = append(changes, web.FieldChangeResponse{
+			Field: change.Field,
+			Old:   change.Old,
+			New:   change.New,
+		})
+	}
+
+	return web.TodoRevisionResponse{
+		Revision:  revision.Revision,
+		TodoId:    revision.TodoId,
+		Action:    revision.Action,
+		Actor:     revision.Actor,
+		Changes:   changes,
+		CreatedAt: revision.CreatedAt,
+	}
+}
+
+func ToTodoRevisionResponses(revisions []domain.TodoRevision) []web.TodoRevisionResponse {
+	var revisionResponses []web.TodoRevisionResponse
+	for _, revision := range revisions {
+		revisionResponses = append(revisionResponses, ToTodoRevisionResponse(revision))
+	}
+
+	return revisionResponses
+}
+
+func ToAuditLogResponse(auditLog domain.AuditLog) web.AuditLogResponse {
+	return web.AuditLogResponse{
+		Id:         auditLog.Id,
+		Actor:      auditLog.Actor,
+		Ip:         auditLog.Ip,
+		UserAgent:  auditLog.UserAgent,
+		RequestId:  auditLog.RequestId,
+		Method:     auditLog.Method,
+		Path:       auditLog.Path,
+		Action:     auditLog.Action,
+		Resource:   auditLog.Resource,
+		Outcome:    auditLog.Outcome,
+		StatusCode: auditLog.StatusCode,
+		Hash:       auditLog.Hash,
+		CreatedAt:  auditLog.CreatedAt,
+	}
+}
+
+func ToAuditLogResponses(auditLogs []domain.AuditLog) []web.AuditLogResponse {
+	auditLogResponses := []web.AuditLogResponse{}
+	for _, auditLog := range auditLogs {
+		auditLogResponses = append(auditLogResponses, ToAuditLogResponse(auditLog))
+	}
+
+	return auditLogResponses
+}
+
+func ToWebhookResponse(subscription domain.WebhookSubscription) web.WebhookResponse {
+	return web.WebhookResponse{
+		Id:        subscription.Id,
+		Url:       subscription.Url,
+		Events:    subscription.Events,
+		Active:    subscription.Active,
+		CreatedAt: subscription.CreatedAt,
+	}
+}
+
+func ToWebhookResponses(subscriptions []domain.WebhookSubscription) []web.WebhookResponse {
+	var webhookResponses []web.WebhookResponse
+	for _, subscription := range subscriptions {
+		webhookResponses = append(webhookResponses, ToWebhookResponse(subscription))
+	}
+
+	return webhookResponses
+}
+
+func ToWebhookDeliveryResponse(delivery domain.WebhookDelivery) web.WebhookDeliveryResponse {
+	return web.WebhookDeliveryResponse{
+		Id:            delivery.Id,
+		EventId:       delivery.EventId,
+		EventType:     delivery.EventType,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		ResponseCode:  delivery.ResponseCode,
+		LastError:     delivery.LastError,
+		NextAttemptAt: delivery.NextAttemptAt,
+		DeliveredAt:   delivery.DeliveredAt,
+		CreatedAt:     delivery.CreatedAt,
+	}
+}
+
+func ToWebhookDeliveryResponses(deliveries []domain.WebhookDelivery) []web.WebhookDeliveryResponse {
+	deliveryResponses := []web.WebhookDeliveryResponse{}
+	for _, delivery := range deliveries {
+		deliveryResponses = append(deliveryResponses, ToWebhookDeliveryResponse(delivery))
+	}
+
+	return deliveryResponses
+}
+
+// ToCommentResponse converts a comment with its reactions, grouped by emoji in
+// the order they were first given. Replies are left to the caller.
+func ToCommentResponse(comment domain.Comment, reactions []domain.CommentReaction) web.CommentResponse {
+	commentResponse := web.CommentResponse{
+		Id:        comment.Id,
+		TodoId:    comment.TodoId,
+		ParentId:  comment.ParentId,
+		Actor:     comment.Actor,
+		Body:      comment.Body,
+		Edited:    comment.EditedAt != nil,
+		Deleted:   comment.DeletedAt != nil,
+		Reactions: []web.CommentReactionResponse{},
+		Replies:   []web.CommentResponse{},
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	}
+
+	positions := map[string]int{}
+	for _, reaction := range reactions {
+		position, ok := positions[reaction.Emoji]
+		if !ok {
+			position = len(commentResponse.Reactions)
+			positions[reaction.Emoji] = position
+			commentResponse.Reactions = append(commentResponse.Reactions, web.CommentReactionResponse{Emoji: reaction.Emoji, Actors: []string{}})
+		}
+		commentResponse.Reactions[position].Count++
+		commentResponse.Reactions[position].Actors = append(commentResponse.Reactions[position].Actors, reaction.Actor)
+	}
+
+	return commentResponse
+}
+
+func ToCommentEditResponses(edits []domain.CommentEdit) []web.CommentEditResponse {
+	editResponses := []web.CommentEditResponse{}
+	for _, edit := range edits {
+		editResponses = append(editResponses, web.CommentEditResponse{
+			Body:      edit.Body,
+			Actor:     edit.Actor,
+			CreatedAt: edit.CreatedAt,
+		})
+	}
+
+	return editResponses
+}
+
+func ToDatabaseStatsResponse(stats sql.DBStats) web.DatabaseStatsResponse {
+	return web.DatabaseStatsResponse{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
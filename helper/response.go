@@ -1,23 +1,47 @@
 package helper
 
 import (
+	"context"
 	"todo-app-api/models/web"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// Envelope returns response in the shape of the API version negotiated for
+// ctx. Version 2 lowercases the envelope and adds timestamps to todos.
+func Envelope(ctx context.Context, response web.WebResponse) interface{} {
+	if APIVersionFromContext(ctx) < 2 {
+		return response
+	}
+
+	data := response.Data
+	switch value := data.(type) {
+	case web.TodoResponse:
+		data = ToTodoResponseV2(value)
+	case []web.TodoResponse:
+		data = ToTodoResponsesV2(value)
+	}
+
+	return web.WebResponseV2{
+		Code:    response.Code,
+		Status:  response.Status,
+		Data:    data,
+		TraceId: response.TraceId,
+	}
+}
+
 func BadRequest(c *fiber.Ctx, message string) error {
-	return c.Status(fiber.StatusBadRequest).JSON(web.WebResponse{
+	return c.Status(fiber.StatusBadRequest).JSON(Envelope(c.UserContext(), web.WebResponse{
 		Code:   fiber.StatusBadRequest,
 		Status: "BAD REQUEST",
 		Data:   message,
-	})
+	}))
 }
 
 func ResponseSuccess(c *fiber.Ctx, data interface{}) error {
-	return c.Status(fiber.StatusOK).JSON(web.WebResponse{
+	return c.Status(fiber.StatusOK).JSON(Envelope(c.UserContext(), web.WebResponse{
 		Code:   200,
 		Status: "Success",
 		Data:   data,
-	})
+	}))
 }
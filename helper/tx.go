@@ -4,10 +4,17 @@ import (
 	"gorm.io/gorm"
 )
 
-func CommitOrRollback(tx *gorm.DB) {
+// CommitOrRollback must be deferred right after Begin. afterCommit callbacks
+// only run once the transaction has been committed successfully.
+func CommitOrRollback(tx *gorm.DB, afterCommit ...func()) {
 	if r := recover(); r != nil {
 		tx.Rollback()
 		panic(r)
 	}
-	tx.Commit()
+	if tx.Commit().Error != nil {
+		return
+	}
+	for _, fn := range afterCommit {
+		fn()
+	}
 }
@@ -0,0 +1,261 @@
+// Package ical reads and writes the subset of iCalendar (RFC 5545) the
+// calendar feed needs: components made of properties, with line folding and
+// TEXT escaping. Values are kept as they appear on the wire; Text and
+// AddText convert TEXT values.
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// TimeFormat is the UTC form of DATE-TIME values.
+const TimeFormat = "20060102T150405Z"
+
+// maxLineLength is in octets, without the CRLF.
+const maxLineLength = 75
+
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+type Component struct {
+	Name       string
+	Properties []Property
+	Components []*Component
+}
+
+func NewComponent(name string) *Component {
+	return &Component{Name: name}
+}
+
+func (component *Component) Add(name string, value string) {
+	component.Properties = append(component.Properties, Property{Name: name, Value: value})
+}
+
+func (component *Component) AddText(name string, text string) {
+	component.Add(name, EscapeText(text))
+}
+
+func (component *Component) AddTime(name string, t time.Time) {
+	component.Add(name, t.UTC().Format(TimeFormat))
+}
+
+// Get returns the first property called name.
+func (component *Component) Get(name string) (Property, bool) {
+	for _, property := range component.Properties {
+		if strings.EqualFold(property.Name, name) {
+			return property, true
+		}
+	}
+	return Property{}, false
+}
+
+// Text returns the unescaped TEXT value of name, empty when it is missing.
+func (component *Component) Text(name string) string {
+	property, _ := component.Get(name)
+	return UnescapeText(property.Value)
+}
+
+// Children returns the nested components called name.
+func (component *Component) Children(name string) []*Component {
+	var children []*Component
+	for _, child := range component.Components {
+		if strings.EqualFold(child.Name, name) {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func EscapeText(text string) string {
+	return textEscaper.Replace(text)
+}
+
+func UnescapeText(value string) string {
+	var text strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i == len(value)-1 {
+			text.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			text.WriteByte('\n')
+		default:
+			text.WriteByte(value[i])
+		}
+	}
+	return text.String()
+}
+
+// Encode writes component with CRLF line endings, folding lines longer than
+// 75 octets without splitting a UTF-8 sequence.
+func Encode(w io.Writer, component *Component) error {
+	writer := bufio.NewWriter(w)
+	encode(writer, component)
+	return writer.Flush()
+}
+
+func encode(w *bufio.Writer, component *Component) {
+	writeLine(w, "BEGIN:"+component.Name)
+	for _, property := range component.Properties {
+		var line strings.Builder
+		line.WriteString(property.Name)
+		for _, name := range slices.Sorted(maps.Keys(property.Params)) {
+			value := property.Params[name]
+			if strings.ContainsAny(value, ":;,") {
+				value = `"` + value + `"`
+			}
+			line.WriteString(";" + name + "=" + value)
+		}
+		line.WriteString(":" + property.Value)
+		writeLine(w, line.String())
+	}
+	for _, child := range component.Components {
+		encode(w, child)
+	}
+	writeLine(w, "END:"+component.Name)
+}
+
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// the leading space of a continuation line counts towards its length
+		limit = maxLineLength - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+// Decode reads the first component of r, usually a VCALENDAR.
+func Decode(r io.Reader) (*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var stack []*Component
+	var root *Component
+	for number, line := range lines {
+		if line == "" {
+			continue
+		}
+		property, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number+1, err)
+		}
+
+		switch strings.ToUpper(property.Name) {
+		case "BEGIN":
+			component := NewComponent(strings.ToUpper(property.Value))
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, component)
+			} else if root != nil {
+				return root, nil
+			} else {
+				root = component
+			}
+			stack = append(stack, component)
+		case "END":
+			if len(stack) == 0 || !strings.EqualFold(stack[len(stack)-1].Name, property.Value) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", number+1, property.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: %s is outside of a component", number+1, property.Name)
+			}
+			current := stack[len(stack)-1]
+			current.Properties = append(current.Properties, property)
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("no component found")
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("%s is not closed", stack[len(stack)-1].Name)
+	}
+	return root, nil
+}
+
+// unfold joins continuation lines, which start with a space or a tab, to the
+// line before them.
+func unfold(r io.Reader) ([]string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// parseLine splits name;param=value:value, where quoted parameter values may
+// contain the delimiters.
+func parseLine(line string) (Property, error) {
+	property := Property{}
+	quoted := false
+	start := 0
+	var parts []string
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				parts = append(parts, line[start:i])
+				start = i + 1
+			}
+		case ':':
+			if !quoted {
+				parts = append(parts, line[start:i])
+				property.Value = line[i+1:]
+				property.Name = strings.ToUpper(parts[0])
+				for _, param := range parts[1:] {
+					name, value, _ := strings.Cut(param, "=")
+					if property.Params == nil {
+						property.Params = map[string]string{}
+					}
+					property.Params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+				}
+				if property.Name == "" {
+					return property, errors.New("property has no name")
+				}
+				return property, nil
+			}
+		}
+	}
+	return property, fmt.Errorf("%q is not a content line", line)
+}
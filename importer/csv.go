@@ -0,0 +1,119 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// table is a CSV file read with its header, whose columns are found by name
+// regardless of case.
+type table struct {
+	header  map[string]int
+	records [][]string
+	lines   []int
+}
+
+func readTable(content []byte) (*table, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+
+	columns, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("the CSV file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read CSV header: %w", err)
+	}
+
+	result := &table{header: map[string]int{}}
+	for i, column := range columns {
+		result.header[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		result.records = append(result.records, record)
+		result.lines = append(result.lines, line)
+	}
+}
+
+// column returns the index of name, or -1 when the header lacks it.
+func (table *table) column(name string) int {
+	if i, ok := table.header[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return i
+	}
+	return -1
+}
+
+func (table *table) value(record []string, column int) string {
+	if column < 0 || column >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[column])
+}
+
+func parseCSV(content []byte, mapping map[string]string) ([]Row, error) {
+	table, err := readTable(content)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for _, field := range Fields {
+		columns[field] = table.column(source(mapping, field))
+		if columns[field] < 0 && mapping[field] != "" {
+			return nil, fmt.Errorf("mapping.%s: the CSV header has no column %q", field, mapping[field])
+		}
+	}
+	if columns["title"] < 0 {
+		return nil, errors.New(`the CSV header has no "title" column, name the column to use in mapping.title`)
+	}
+
+	rows := make([]Row, len(table.records))
+	for i, record := range table.records {
+		rows[i].Line = table.lines[i]
+		rows[i].Todo.Title = table.value(record, columns["title"])
+		rows[i].Todo.Description = table.value(record, columns["description"])
+		rows[i].Todo.Status = strings.ToLower(table.value(record, columns["status"]))
+	}
+	return rows, nil
+}
+
+// parseTodoist reads the CSV template Todoist exports a project as. Only
+// rows of TYPE task are todos; sections, notes and the metadata rows are
+// left out. Todoist exports open tasks only, so every todo is pending.
+func parseTodoist(content []byte) ([]Row, error) {
+	table, err := readTable(content)
+	if err != nil {
+		return nil, err
+	}
+
+	typeColumn, contentColumn := table.column("TYPE"), table.column("CONTENT")
+	if typeColumn < 0 || contentColumn < 0 {
+		return nil, errors.New("not a Todoist export: the TYPE and CONTENT columns are missing")
+	}
+	descriptionColumn := table.column("DESCRIPTION")
+
+	var rows []Row
+	for i, record := range table.records {
+		if !strings.EqualFold(table.value(record, typeColumn), "task") {
+			continue
+		}
+		rows = append(rows, Row{
+			Line: table.lines[i],
+			Todo: todo(table.value(record, contentColumn), table.value(record, descriptionColumn), false),
+		})
+	}
+	return rows, nil
+}
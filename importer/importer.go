@@ -0,0 +1,71 @@
+// Package importer reads todos out of files exported by this API or by other
+// todo apps. It only parses; validating and storing the todos is left to
+// service.TodoService.Import.
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"todo-app-api/models/web"
+)
+
+// Formats lists every format Parse understands.
+var Formats = []string{"csv", "json", "ndjson", "todoist", "trello", "mstodo"}
+
+// Fields are the todo fields a mapping may point at another column or key.
+var Fields = []string{"title", "description", "status"}
+
+// Row is one item read from a file. Line locates it for error reports: the
+// line for CSV and NDJSON, the position in the list for JSON. Error is set
+// when the item itself could not be read, Skip when the source marks it as
+// not to be imported, such as an archived Trello card.
+type Row struct {
+	Line  int
+	Todo  web.TodoCreateRequest
+	Error string
+	Skip  string
+}
+
+// Parse reads the rows of content. mapping names, per field, the column or
+// key holding it in the csv, json and ndjson formats; unmapped fields are
+// read from the column or key of the same name. The exports of other apps
+// have a fixed layout and ignore mapping.
+func Parse(format string, content []byte, mapping map[string]string) ([]Row, error) {
+	switch format {
+	case "csv":
+		return parseCSV(content, mapping)
+	case "json":
+		return parseJSON(content, mapping)
+	case "ndjson":
+		return parseNDJSON(content, mapping)
+	case "todoist":
+		return parseTodoist(content)
+	case "trello":
+		return parseTrello(content)
+	case "mstodo":
+		return parseMicrosoftToDo(content)
+	}
+	return nil, fmt.Errorf("unknown import format %q", format)
+}
+
+// source returns the column or key field is read from.
+func source(mapping map[string]string, field string) string {
+	if name, ok := mapping[field]; ok && name != "" {
+		return name
+	}
+	return field
+}
+
+// todo builds the todo of an app export, which only knows whether an item
+// is done.
+func todo(title string, description string, done bool) web.TodoCreateRequest {
+	status := "pending"
+	if done {
+		status = "done"
+	}
+	return web.TodoCreateRequest{
+		Title:       strings.TrimSpace(title),
+		Description: strings.TrimSpace(description),
+		Status:      status,
+	}
+}
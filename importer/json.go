@@ -0,0 +1,145 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// field reads key from object, matching its case loosely. Numbers and
+// booleans are taken as their JSON text, null as empty.
+func field(object map[string]any, key string) string {
+	value, ok := object[key]
+	if !ok {
+		for name, candidate := range object {
+			if strings.EqualFold(name, key) {
+				value = candidate
+				break
+			}
+		}
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(value)
+	case json.Number, bool:
+		return fmt.Sprint(value)
+	default:
+		text, _ := json.Marshal(value)
+		return string(text)
+	}
+}
+
+func objectRow(line int, object map[string]any, mapping map[string]string) Row {
+	row := Row{Line: line}
+	row.Todo.Title = field(object, source(mapping, "title"))
+	row.Todo.Description = field(object, source(mapping, "description"))
+	row.Todo.Status = strings.ToLower(field(object, source(mapping, "status")))
+	return row
+}
+
+func decode(content []byte, value any) error {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
+
+func parseJSON(content []byte, mapping map[string]string) ([]Row, error) {
+	var items []json.RawMessage
+	if err := decode(content, &items); err != nil {
+		return nil, fmt.Errorf("read JSON: expected an array of objects: %w", err)
+	}
+
+	rows := make([]Row, len(items))
+	for i, item := range items {
+		var object map[string]any
+		if err := decode(item, &object); err != nil || object == nil {
+			rows[i] = Row{Line: i + 1, Error: "not a JSON object"}
+			continue
+		}
+		rows[i] = objectRow(i+1, object, mapping)
+	}
+	return rows, nil
+}
+
+func parseNDJSON(content []byte, mapping map[string]string) ([]Row, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, len(content)+1)
+
+	var rows []Row
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var object map[string]any
+		if err := decode(text, &object); err != nil || object == nil {
+			rows = append(rows, Row{Line: line, Error: "not a JSON object"})
+			continue
+		}
+		rows = append(rows, objectRow(line, object, mapping))
+	}
+	return rows, scanner.Err()
+}
+
+// parseTrello reads the JSON export of a Trello board. Cards are todos,
+// done once their due date is marked complete; archived cards are skipped.
+func parseTrello(content []byte) ([]Row, error) {
+	var board struct {
+		Cards *[]struct {
+			Name        string `json:"name"`
+			Desc        string `json:"desc"`
+			Closed      bool   `json:"closed"`
+			DueComplete bool   `json:"dueComplete"`
+		} `json:"cards"`
+	}
+	if err := decode(content, &board); err != nil {
+		return nil, fmt.Errorf("read Trello export: %w", err)
+	}
+	if board.Cards == nil {
+		return nil, errors.New("not a Trello board export: the cards are missing")
+	}
+
+	rows := make([]Row, len(*board.Cards))
+	for i, card := range *board.Cards {
+		rows[i] = Row{Line: i + 1, Todo: todo(card.Name, card.Desc, card.DueComplete)}
+		if card.Closed {
+			rows[i].Skip = "archived"
+		}
+	}
+	return rows, nil
+}
+
+// parseMicrosoftToDo reads Microsoft To Do tasks as the Graph API lists
+// them, either the whole {"value": [...]} response or the bare list.
+func parseMicrosoftToDo(content []byte) ([]Row, error) {
+	type task struct {
+		Title  string `json:"title"`
+		Status string `json:"status"`
+		Body   struct {
+			Content string `json:"content"`
+		} `json:"body"`
+	}
+
+	var tasks []task
+	if err := decode(content, &tasks); err != nil {
+		var list struct {
+			Value *[]task `json:"value"`
+		}
+		if err := decode(content, &list); err != nil || list.Value == nil {
+			return nil, errors.New("not a Microsoft To Do export: expected a list of tasks or a {\"value\": [...]} response")
+		}
+		tasks = *list.Value
+	}
+
+	rows := make([]Row, len(tasks))
+	for i, task := range tasks {
+		rows[i] = Row{Line: i + 1, Todo: todo(task.Title, task.Body.Content, task.Status == "completed")}
+	}
+	return rows, nil
+}
@@ -0,0 +1,114 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Lifecycle owns the background workers and tears everything down in order:
+// readiness fails first, then the HTTP server drains, then workers flush,
+// then the database pool is closed.
+type Lifecycle struct {
+	shuttingDown atomic.Bool
+	ctx          context.Context
+	cancel       context.CancelFunc
+	workers      sync.WaitGroup
+	mutex        sync.Mutex
+	hooks        []func()
+	running      map[string]bool
+}
+
+func New() *Lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Lifecycle{ctx: ctx, cancel: cancel, running: map[string]bool{}}
+}
+
+// Go starts a named background worker. fn must return once ctx is cancelled;
+// returning earlier marks the worker as stopped in Workers.
+func (lifecycle *Lifecycle) Go(name string, fn func(ctx context.Context)) {
+	lifecycle.mutex.Lock()
+	lifecycle.running[name] = true
+	lifecycle.mutex.Unlock()
+
+	lifecycle.workers.Add(1)
+	go func() {
+		defer lifecycle.workers.Done()
+		defer func() {
+			lifecycle.mutex.Lock()
+			lifecycle.running[name] = false
+			lifecycle.mutex.Unlock()
+		}()
+		fn(lifecycle.ctx)
+	}()
+}
+
+// Workers reports, by name, whether each worker started with Go is still
+// running.
+func (lifecycle *Lifecycle) Workers() map[string]bool {
+	lifecycle.mutex.Lock()
+	defer lifecycle.mutex.Unlock()
+
+	workers := make(map[string]bool, len(lifecycle.running))
+	for name, running := range lifecycle.running {
+		workers[name] = running
+	}
+	return workers
+}
+
+// OnShutdown registers fn to run as soon as shutdown begins, before the
+// server stops, e.g. to end long-lived streams that would never drain.
+func (lifecycle *Lifecycle) OnShutdown(fn func()) {
+	lifecycle.mutex.Lock()
+	defer lifecycle.mutex.Unlock()
+
+	lifecycle.hooks = append(lifecycle.hooks, fn)
+}
+
+func (lifecycle *Lifecycle) ShuttingDown() bool {
+	return lifecycle.shuttingDown.Load()
+}
+
+// Shutdown stops the application within ctx's deadline. Whatever has not
+// finished by then is abandoned, but the database pool is always closed.
+func (lifecycle *Lifecycle) Shutdown(ctx context.Context, app *fiber.App, db *gorm.DB) error {
+	lifecycle.shuttingDown.Store(true)
+
+	lifecycle.mutex.Lock()
+	hooks := lifecycle.hooks
+	lifecycle.mutex.Unlock()
+	for _, hook := range hooks {
+		hook()
+	}
+
+	var errs []error
+	if err := app.ShutdownWithContext(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	lifecycle.cancel()
+	flushed := make(chan struct{})
+	go func() {
+		lifecycle.workers.Wait()
+		close(flushed)
+	}()
+	select {
+	case <-flushed:
+	case <-ctx.Done():
+		slog.Warn("shutdown: background workers did not finish in time")
+		errs = append(errs, ctx.Err())
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// GormLogger sends GORM's logs to slog. Failed queries are logged as errors
+// and queries slower than SlowThreshold as warnings; every query is logged at
+// debug level.
+type GormLogger struct {
+	Logger        *slog.Logger
+	SlowThreshold time.Duration
+	Level         logger.LogLevel
+}
+
+// NewGormLogger logs through base, which is looked up on every call when nil
+// so slog.SetDefault takes effect for connections opened earlier.
+func NewGormLogger(base *slog.Logger, slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{
+		Logger:        base,
+		SlowThreshold: slowThreshold,
+		Level:         logger.Warn,
+	}
+}
+
+func (gormLogger *GormLogger) logger() *slog.Logger {
+	if gormLogger.Logger == nil {
+		return slog.Default()
+	}
+	return gormLogger.Logger
+}
+
+func (gormLogger *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *gormLogger
+	copied.Level = level
+	return &copied
+}
+
+func (gormLogger *GormLogger) Info(ctx context.Context, message string, args ...interface{}) {
+	if gormLogger.Level >= logger.Info {
+		gormLogger.logger().InfoContext(ctx, fmt.Sprintf(message, args...))
+	}
+}
+
+func (gormLogger *GormLogger) Warn(ctx context.Context, message string, args ...interface{}) {
+	if gormLogger.Level >= logger.Warn {
+		gormLogger.logger().WarnContext(ctx, fmt.Sprintf(message, args...))
+	}
+}
+
+func (gormLogger *GormLogger) Error(ctx context.Context, message string, args ...interface{}) {
+	if gormLogger.Level >= logger.Error {
+		gormLogger.logger().ErrorContext(ctx, fmt.Sprintf(message, args...))
+	}
+}
+
+func (gormLogger *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if gormLogger.Level <= logger.Silent {
+		return
+	}
+
+	base := gormLogger.logger()
+	elapsed := time.Since(begin)
+	attrs := func() []any {
+		sql, rows := fc()
+		return []any{
+			slog.String("sql", sql),
+			slog.Int64("rows", rows),
+			slog.Float64("elapsed_ms", float64(elapsed.Microseconds())/1000),
+		}
+	}
+
+	switch {
+	// not found is an expected outcome the service layer turns into a 404
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && gormLogger.Level >= logger.Error:
+		base.ErrorContext(ctx, "query failed", append(attrs(), slog.String("error", err.Error()))...)
+	case gormLogger.SlowThreshold > 0 && elapsed > gormLogger.SlowThreshold && gormLogger.Level >= logger.Warn:
+		base.WarnContext(ctx, "slow query", append(attrs(), slog.Duration("threshold", gormLogger.SlowThreshold))...)
+	case base.Enabled(ctx, slog.LevelDebug):
+		base.DebugContext(ctx, "query", attrs()...)
+	}
+}
@@ -1,19 +1,46 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"todo-app-api/command"
 	"todo-app-api/config"
 	"todo-app-api/controller"
+	"todo-app-api/event"
 	"todo-app-api/exception"
+	"todo-app-api/graph"
+	"todo-app-api/lifecycle"
+	"todo-app-api/logging"
+	"todo-app-api/metrics"
+	"todo-app-api/middleware"
+	"todo-app-api/migration"
+	"todo-app-api/openapi"
+	"todo-app-api/outbox"
+	"todo-app-api/replica"
 	"todo-app-api/repository"
 	"todo-app-api/routes"
+	"todo-app-api/rpc"
 	"todo-app-api/service"
+	"todo-app-api/stream"
+	"todo-app-api/tracing"
+	"todo-app-api/webhook"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/joho/godotenv"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -22,21 +49,283 @@ func main() {
 		log.Println("No .env file found")
 	}
 
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	slog.SetDefault(logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format))
+
+	if len(os.Args) == 3 && os.Args[1] == "config" && os.Args[2] == "print" {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fatal("print config", err)
+		}
+		return
+	}
+
+	db, err := config.NewDB(cfg.Database)
+	if err != nil {
+		fatal("connect to database", err)
+	}
+	if err := db.Use(tracing.GormPlugin()); err != nil {
+		fatal("install tracing", err)
+	}
+	validate := validator.New()
+
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		fatal("load migrations", err)
+	}
+
+	auditLogRepository := repository.NewAuditLogRepository(db)
+	auditService := service.NewAuditService(auditLogRepository, db, validate)
+
+	if len(os.Args) > 1 {
+		switch {
+		case len(os.Args) == 3 && os.Args[1] == "audit" && os.Args[2] == "verify":
+			err = command.AuditVerify(context.Background(), auditService, os.Stdout)
+		case os.Args[1] == "migrate":
+			err = command.Migrate(context.Background(), migrator, os.Args[2:], os.Stdout)
+		default:
+			err = fmt.Errorf("unknown command: %v", os.Args[1:])
+		}
+		if err != nil {
+			fatal("command failed", err)
+		}
+		return
+	}
+
+	if err := command.EnsureSchema(context.Background(), migrator, cfg.App.AutoMigrate, os.Stdout); err != nil {
+		fatal("check schema", err)
+	}
+
+	appLifecycle := lifecycle.New()
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.File, cfg.Tracing.ServiceName, config.Version)
+	if err != nil {
+		fatal("set up tracing", err)
+	}
+
+	appMetrics := metrics.New()
+	if cfg.Metrics.Enabled {
+		err := errors.Join(
+			db.Use(appMetrics.GormPlugin()),
+			appMetrics.RegisterDB(db, "primary"),
+			appMetrics.RegisterTodos(db),
+		)
+		if err != nil {
+			fatal("register metrics", err)
+		}
+	}
+
+	document := routes.OpenAPI(config.Version)
+	apiValidator, err := openapi.NewValidator(document)
+	if err != nil {
+		fatal("load OpenAPI document", err)
+	}
+
 	app := fiber.New(fiber.Config{
-		ErrorHandler: exception.NewErrorHandler,
+		ErrorHandler:          exception.NewErrorHandler,
+		DisableStartupMessage: true,
 	})
 
 	app.Use(recover.New())
+	app.Use(middleware.Trace())
+	app.Use(middleware.RequestId())
+	app.Use(middleware.APIVersion(routes.Resources, config.Date(cfg.API.V1DeprecatedAt), config.Date(cfg.API.V1Sunset)))
+	if cfg.Metrics.Enabled {
+		app.Use(appMetrics.Middleware())
+	}
+	app.Use(middleware.AccessLog(slog.Default()))
+	app.Use(middleware.Actor())
+	app.Use(middleware.Client())
+	app.Use(middleware.Audit(auditService))
+	app.Use(middleware.Validate(apiValidator, cfg.App.Env == "test"))
 
-	db := config.NewDB()
-	validate := validator.New()
+	webhookSubscriptionRepository := repository.NewWebhookSubscriptionRepository(db)
+	webhookDeliveryRepository := repository.NewWebhookDeliveryRepository(db)
+	webhookService := service.NewWebhookService(webhookSubscriptionRepository, webhookDeliveryRepository, db, validate)
+	webhookDispatcher := webhook.NewDispatcher(webhookSubscriptionRepository, webhookDeliveryRepository, db)
+	webhookDispatcher.MaxAttempts = cfg.Webhook.MaxAttempts
+	webhookDispatcher.BaseBackoff = cfg.Webhook.BaseBackoff
+	webhookDispatcher.MaxBackoff = cfg.Webhook.MaxBackoff
+	webhookDispatcher.PollInterval = cfg.Webhook.PollInterval
+	webhookDispatcher.Client.Timeout = cfg.Webhook.Timeout
+	webhookDispatcher.ClaimTimeout = cfg.Webhook.ClaimTimeout
+	webhookDispatcher.AllowPrivateTargets = cfg.Webhook.AllowPrivateTargets
+	appLifecycle.Go("webhook_dispatcher", webhookDispatcher.Run)
+
+	eventBus := event.NewBus()
+	eventBus.Subscribe(webhookDispatcher.Publish)
+
+	outboxRepository := repository.NewOutboxRepository(db)
+
+	// the hub reads the outbox itself, since the relay of another instance
+	// may be the one publishing; the bus only makes it look right away
+	streamHub := stream.NewHub(cfg.Stream.HistorySize, cfg.Stream.BufferSize)
+	streamTailer := stream.NewTailer(outboxRepository, db, streamHub)
+	streamTailer.PollInterval = cfg.Stream.PollInterval
+	streamTailer.GapTimeout = cfg.Stream.GapTimeout
+	eventBus.Subscribe(streamTailer.Publish)
+	appLifecycle.Go("stream_tailer", streamTailer.Run)
+	appLifecycle.OnShutdown(streamHub.Close)
+
+	outboxRelay := outbox.NewRelay(outboxRepository, db, eventBus)
+	outboxRelay.PollInterval = cfg.Outbox.PollInterval
+	outboxRelay.BatchSize = cfg.Outbox.BatchSize
+	outboxRelay.MaxAttempts = cfg.Outbox.MaxAttempts
+	outboxRelay.BaseBackoff = cfg.Outbox.BaseBackoff
+	outboxRelay.MaxBackoff = cfg.Outbox.MaxBackoff
+	outboxRelay.ClaimTimeout = cfg.Outbox.ClaimTimeout
+	appLifecycle.Go("outbox_relay", outboxRelay.Run)
+
+	var replicaDBs []*gorm.DB
+	for i, replicaUrl := range cfg.Database.ReplicaUrls {
+		replicaDB, err := config.NewReplicaDB(cfg.Database, replicaUrl)
+		if err != nil {
+			fatal("open read replica", err)
+		}
+		if err := replicaDB.Use(tracing.GormPlugin()); err != nil {
+			fatal("install tracing", err)
+		}
+		if cfg.Metrics.Enabled {
+			if err := errors.Join(replicaDB.Use(appMetrics.GormPlugin()), appMetrics.RegisterDB(replicaDB, fmt.Sprintf("replica_%d", i))); err != nil {
+				fatal("register metrics", err)
+			}
+		}
+		replicaDBs = append(replicaDBs, replicaDB)
+	}
+	replicaRouter := replica.NewRouter(db, replicaDBs)
+	replicaRouter.ReadYourWrites = cfg.Database.ReadYourWrites
+	replicaRouter.HealthInterval = cfg.Database.ReplicaHealthInterval
+	if len(replicaDBs) > 0 {
+		appLifecycle.Go("replica_health", replicaRouter.Run)
+	}
 
 	todoRepository := repository.NewTodoRepository(db)
-	todoService := service.NewTodoService(todoRepository, db, validate)
+	todoRevisionRepository := repository.NewTodoRevisionRepository(db)
+	todoReferenceRepository := repository.NewTodoReferenceRepository(db)
+	commentRepository := repository.NewCommentRepository(db)
+	todoService := service.NewTodoService(todoRepository, todoRevisionRepository, todoReferenceRepository, commentRepository, outboxRepository, db, replicaRouter, validate)
 	todoController := controller.NewTodoController(todoService)
+	auditController := controller.NewAuditController(auditService)
+	webhookController := controller.NewWebhookController(webhookService)
+	streamController := controller.NewStreamController(streamHub, cfg.Stream.Heartbeat)
+	healthService := service.NewHealthService(db, migrator, appLifecycle, cfg.Health)
+	healthController := controller.NewHealthController(healthService)
+
+	docsController := controller.NewDocsController(document)
+
+	graphqlExecutor, err := graph.NewExecutor(todoService, streamHub, graph.Limits{
+		MaxDepth:                   cfg.GraphQL.MaxDepth,
+		MaxComplexity:              cfg.GraphQL.MaxComplexity,
+		MaxIntrospectionDepth:      cfg.GraphQL.MaxIntrospectionDepth,
+		MaxIntrospectionComplexity: cfg.GraphQL.MaxIntrospectionComplexity,
+	})
+	if err != nil {
+		fatal("build GraphQL schema", err)
+	}
+	graphqlController := controller.NewGraphQLController(graphqlExecutor, cfg.App.Env == "development")
+
+	calendarService := service.NewCalendarService(repository.NewCalendarTokenRepository(db), repository.NewCalendarObjectRepository(db), todoRepository, todoRevisionRepository, todoService, db, validate)
+	calendarController := controller.NewCalendarController(calendarService)
+	commentService := service.NewCommentService(commentRepository, repository.NewCommentEditRepository(db), repository.NewCommentReactionRepository(db), todoRepository, db, validate)
+	commentController := controller.NewCommentController(commentService)
+
+	if cfg.GRPC.Port != 0 {
+		grpcServer, grpcHealth := rpc.NewServer(rpc.NewTodoServer(todoService, validate), rpc.NewCommentServer(commentService, validate), auditService)
+		grpcListener, err := net.Listen("tcp", ":"+strconv.Itoa(cfg.GRPC.Port))
+		if err != nil {
+			fatal("listen for gRPC", err)
+		}
+		appLifecycle.OnShutdown(grpcHealth.Shutdown)
+
+		appLifecycle.Go("grpc_server", func(ctx context.Context) {
+			go func() {
+				<-ctx.Done()
+				grpcServer.GracefulStop()
+			}()
+			slog.Info("gRPC server listening", "port", cfg.GRPC.Port)
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				slog.Error("gRPC server stopped", "error", err)
+			}
+		})
+	}
+	if cfg.GRPC.GatewayPort != 0 {
+		gatewayConn, err := grpc.NewClient("localhost:"+strconv.Itoa(cfg.GRPC.Port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			fatal("connect the gateway to gRPC", err)
+		}
+		gateway, err := rpc.NewGateway(context.Background(), gatewayConn)
+		if err != nil {
+			fatal("register the gateway", err)
+		}
+		gatewayServer := &http.Server{Addr: ":" + strconv.Itoa(cfg.GRPC.GatewayPort), Handler: gateway}
+
+		appLifecycle.Go("grpc_gateway", func(ctx context.Context) {
+			go func() {
+				<-ctx.Done()
+				gatewayServer.Shutdown(context.Background())
+				gatewayConn.Close()
+			}()
+			slog.Info("gRPC-Gateway listening", "port", cfg.GRPC.GatewayPort)
+			if err := gatewayServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("gRPC-Gateway stopped", "error", err)
+			}
+		})
+	}
+
+	routes.NewRouter(app, todoController, auditController, webhookController, streamController, healthController, docsController, graphqlController, calendarController, commentController)
+
+	if cfg.Metrics.Enabled && cfg.Metrics.AdminPort == 0 {
+		routes.NewMetricsRouter(app, appMetrics.Handler())
+	}
+	if cfg.Metrics.Enabled && cfg.Metrics.AdminPort != 0 {
+		admin := fiber.New(fiber.Config{DisableStartupMessage: true})
+		routes.NewMetricsRouter(admin, appMetrics.Handler())
+
+		// stays up until the API has drained so the last scrapes still work
+		appLifecycle.Go("admin_server", func(ctx context.Context) {
+			go func() {
+				<-ctx.Done()
+				admin.Shutdown()
+			}()
+			slog.Info("admin server listening", "port", cfg.Metrics.AdminPort)
+			if err := admin.Listen(":" + strconv.Itoa(cfg.Metrics.AdminPort)); err != nil {
+				slog.Error("admin server stopped", "error", err)
+			}
+		})
+	}
 
-	routes.NewRouter(app, todoController)
+	signals, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	app.Listen(":" + os.Getenv("APP_PORT"))
+	go func() {
+		slog.Info("server listening", "port", cfg.App.Port, "version", config.Version)
+		if err := app.Listen(":" + strconv.Itoa(cfg.App.Port)); err != nil {
+			slog.Error("server stopped", "error", err)
+			stop()
+		}
+	}()
+
+	<-signals.Done()
+	slog.Info("shutting down")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.App.ShutdownTimeout)
+	defer cancel()
+
+	if err := appLifecycle.Shutdown(ctx, app, db); err != nil {
+		slog.Error("shutdown", "error", err)
+	}
+	if err := replicaRouter.Close(); err != nil {
+		slog.Error("shutdown", "error", err)
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("shutdown", "error", err)
+	}
+}
 
+func fatal(message string, err error) {
+	slog.Error(message, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,48 @@
+// Package markdown renders todo descriptions, written in CommonMark with
+// the GitHub extensions, to sanitized HTML and reads the task list, links
+// and mentions out of them.
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+var converter = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// policy is applied on top of goldmark, which already leaves raw HTML out:
+// it keeps the formatting Markdown can produce, links only to http, https
+// and mailto URLs, and marks every link nofollow.
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	policy.AllowURLSchemes("http", "https", "mailto")
+	policy.RequireParseableURLs(true)
+	policy.RequireNoFollowOnLinks(true)
+	policy.RequireNoReferrerOnLinks(true)
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+	// task list items render a disabled checkbox
+	policy.AllowAttrs("type").Matching(bluemonday.SpaceSeparatedTokens).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+	return policy
+}
+
+// Render converts source to HTML that is safe to embed in a page.
+func Render(source string) string {
+	var html bytes.Buffer
+	if err := converter.Convert([]byte(source), &html); err != nil {
+		// goldmark only fails when the writer does
+		panic(err)
+	}
+	return policy.Sanitize(html.String())
+}
+
+func parse(source []byte) ast.Node {
+	return converter.Parser().Parse(text.NewReader(source))
+}
@@ -0,0 +1,83 @@
+package markdown
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// mention is @name, not preceded by anything that makes it part of a word or
+// an email address.
+var mention = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9][A-Za-z0-9_-]{0,38})`)
+
+// Links returns the distinct http, https and mailto URLs source links to,
+// bare URLs included, in the order they first appear.
+func Links(source string) []string {
+	content := []byte(source)
+	var links []string
+	ast.Walk(parse(content), func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := node.(type) {
+		case *ast.Link:
+			links = append(links, string(node.Destination))
+		case *ast.AutoLink:
+			url := string(node.URL(content))
+			if node.AutoLinkType == ast.AutoLinkEmail && !strings.HasPrefix(url, "mailto:") {
+				url = "mailto:" + url
+			}
+			links = append(links, url)
+		}
+		return ast.WalkContinue, nil
+	})
+	return distinct(slices.DeleteFunc(links, func(link string) bool {
+		scheme, _, found := strings.Cut(strings.ToLower(link), ":")
+		return !found || (scheme != "http" && scheme != "https" && scheme != "mailto")
+	}))
+}
+
+// Mentions returns the distinct lowercased names mentioned with @name in
+// source. Mentions in code and inside links do not count.
+func Mentions(source string) []string {
+	content := []byte(source)
+	var prose strings.Builder
+	ast.Walk(parse(content), func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			if node.Type() == ast.TypeBlock {
+				prose.WriteByte('\n')
+			}
+			return ast.WalkContinue, nil
+		}
+		switch node := node.(type) {
+		case *ast.CodeSpan, *ast.Link, *ast.AutoLink, *ast.RawHTML, *ast.CodeBlock, *ast.FencedCodeBlock, *ast.HTMLBlock, *east.TaskCheckBox:
+			prose.WriteByte(' ')
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			prose.Write(node.Segment.Value(content))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				prose.WriteByte('\n')
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	var mentions []string
+	for _, match := range mention.FindAllStringSubmatch(prose.String(), -1) {
+		mentions = append(mentions, strings.ToLower(match[1]))
+	}
+	return distinct(mentions)
+}
+
+func distinct(values []string) []string {
+	var result []string
+	for _, value := range values {
+		if value != "" && !slices.Contains(result, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+var ErrTaskNotFound = errors.New("task not found")
+
+// Task is an item of a task list. Tasks are numbered from 1 in the order
+// they appear, nested lists included.
+type Task struct {
+	Number  int
+	Checked bool
+	Text    string
+	// offset is the position of the box state, the x or space between the
+	// brackets, in the source.
+	offset int
+}
+
+// Tasks lists the task list items of source. Brackets in code blocks and
+// other text that only looks like a task are left out.
+func Tasks(source string) []Task {
+	content := []byte(source)
+	var tasks []Task
+	ast.Walk(parse(content), func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		checkBox, ok := node.(*east.TaskCheckBox)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		// the box is the start of the first line of its list item
+		lines := checkBox.Parent().Lines()
+		if lines.Len() == 0 {
+			return ast.WalkContinue, nil
+		}
+		line := lines.At(0)
+		start := bytes.IndexByte(content[line.Start:line.Stop], '[')
+		if start < 0 {
+			return ast.WalkContinue, nil
+		}
+		offset := line.Start + start + 1
+		tasks = append(tasks, Task{
+			Number:  len(tasks) + 1,
+			Checked: checkBox.IsChecked,
+			Text:    string(bytes.TrimSpace(content[offset+2 : line.Stop])),
+			offset:  offset,
+		})
+		return ast.WalkContinue, nil
+	})
+	return tasks
+}
+
+// SetTask checks or unchecks task number of source, leaving every other
+// byte as it was.
+func SetTask(source string, number int, checked bool) (string, error) {
+	tasks := Tasks(source)
+	if number < 1 || number > len(tasks) {
+		return "", ErrTaskNotFound
+	}
+
+	task := tasks[number-1]
+	if task.Checked == checked {
+		return source, nil
+	}
+	state := " "
+	if checked {
+		state = "x"
+	}
+	return source[:task.offset] + state + source[task.offset+1:], nil
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+// unmatchedRoute labels requests no route handled, so arbitrary paths cannot
+// blow up the number of series.
+const unmatchedRoute = "unmatched"
+
+// Metrics owns the Prometheus registry of the application and the collectors
+// fed by the HTTP middleware and the GORM plugin.
+type Metrics struct {
+	Registry        *prometheus.Registry
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	dbQueryDuration *prometheus.HistogramVec
+	routesOnce      sync.Once
+	routes          map[string]bool
+}
+
+func New() *Metrics {
+	metrics := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, route template and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method, route template and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		dbQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Database query latency by operation and table.",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"operation", "table"}),
+	}
+
+	metrics.Registry.MustRegister(
+		metrics.httpRequests,
+		metrics.httpDuration,
+		metrics.dbQueryDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return metrics
+}
+
+// Middleware records every request under its route template, e.g.
+// /todos/:todoId. Errors are rendered here so the recorded status is final.
+func (metrics *Metrics) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		if err := c.Next(); err != nil {
+			if handlerErr := c.App().Config().ErrorHandler(c, err); handlerErr != nil {
+				return handlerErr
+			}
+		}
+
+		route := c.Route().Path
+		// the last handler was middleware, so no route matched the path
+		if !metrics.isRoute(c.App(), c.Route()) {
+			route = unmatchedRoute
+		}
+
+		labels := prometheus.Labels{
+			// fiber reuses the request buffer the method string points into
+			"method": utils.CopyString(c.Method()),
+			"route":  route,
+			"status": strconv.Itoa(c.Response().StatusCode()),
+		}
+		metrics.httpRequests.With(labels).Inc()
+		metrics.httpDuration.With(labels).Observe(time.Since(start).Seconds())
+		return nil
+	}
+}
+
+func (metrics *Metrics) isRoute(app *fiber.App, route *fiber.Route) bool {
+	// routes are registered after the middleware, so collect them lazily
+	metrics.routesOnce.Do(func() {
+		metrics.routes = map[string]bool{}
+		for _, registered := range app.GetRoutes(true) {
+			metrics.routes[registered.Method+" "+registered.Path] = true
+		}
+	})
+	return metrics.routes[route.Method+" "+route.Path]
+}
+
+// Handler serves the registry in the Prometheus text format.
+func (metrics *Metrics) Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+}
+
+// RegisterDB exports the connection pool statistics of db as go_sql_*
+// gauges labeled with name.
+func (metrics *Metrics) RegisterDB(db *gorm.DB, name string) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return metrics.Registry.Register(collectors.NewDBStatsCollector(sqlDB, name))
+}
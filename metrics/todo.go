@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"todo-app-api/models/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+var todosDesc = prometheus.NewDesc("todos", "Todos by status.", []string{"status"}, nil)
+
+// todoCollector counts todos by status on every scrape.
+type todoCollector struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+// RegisterTodos exports the number of todos by status, counted in db.
+func (metrics *Metrics) RegisterTodos(db *gorm.DB) error {
+	return metrics.Registry.Register(todoCollector{db: db, timeout: 5 * time.Second})
+}
+
+func (collector todoCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- todosDesc
+}
+
+func (collector todoCollector) Collect(metrics chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collector.timeout)
+	defer cancel()
+
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := collector.db.WithContext(ctx).Model(&domain.Todo{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		slog.Error("metrics: count todos", "error", err)
+		metrics <- prometheus.NewInvalidMetric(todosDesc, err)
+		return
+	}
+
+	for _, row := range rows {
+		metrics <- prometheus.MustNewConstMetric(todosDesc, prometheus.GaugeValue, float64(row.Count), row.Status)
+	}
+}
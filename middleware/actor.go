@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"todo-app-api/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Actor stores the caller named in the X-Actor header on the user context so
+// the service layer can attribute changes to it.
+func Actor() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.SetUserContext(helper.WithActor(c.UserContext(), c.Get("X-Actor")))
+		return c.Next()
+	}
+}
+
+// Client identifies the caller by X-Actor, falling back to its IP address.
+func Client() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		client := c.Get("X-Actor")
+		if client == "" {
+			client = c.IP()
+		}
+		c.SetUserContext(helper.WithClient(c.UserContext(), client))
+		return c.Next()
+	}
+}
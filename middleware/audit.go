@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"fmt"
+	"todo-app-api/helper"
+	"todo-app-api/models/domain"
+	"todo-app-api/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// next runs the rest of the chain, turning panics into errors so failed
+// requests are audited as well.
+func next(c *fiber.Ctx) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	return c.Next()
+}
+
+// Audit records every mutating request in the audit log. It renders handler
+// errors itself so the logged status code matches what the client receives.
+func Audit(auditService service.AuditService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		default:
+			return c.Next()
+		}
+
+		requestId := helper.RequestIdFromContext(c.UserContext())
+		if requestId == "" {
+			requestId = c.Get(fiber.HeaderXRequestID)
+		}
+		if requestId == "" {
+			requestId = uuid.NewString()
+		}
+
+		target := &helper.AuditTarget{}
+		c.SetUserContext(helper.WithAuditTarget(c.UserContext(), target))
+
+		if err := next(c); err != nil {
+			if handlerErr := c.App().Config().ErrorHandler(c, err); handlerErr != nil {
+				return handlerErr
+			}
+		}
+
+		statusCode := c.Response().StatusCode()
+		outcome := service.AuditOutcomeSuccess
+		if statusCode >= fiber.StatusBadRequest {
+			outcome = service.AuditOutcomeFailure
+		}
+
+		// requests rejected before a handler ran have no route of their own
+		action := target.Action
+		if action == "" {
+			action = fmt.Sprintf("%s %s", c.Method(), c.Path())
+		}
+
+		auditService.Record(c.UserContext(), domain.AuditLog{
+			Actor:      helper.ActorFromContext(c.UserContext()),
+			Ip:         c.IP(),
+			UserAgent:  c.Get(fiber.HeaderUserAgent),
+			RequestId:  requestId,
+			Method:     c.Method(),
+			Path:       c.Path(),
+			Action:     action,
+			Resource:   target.Resource,
+			Outcome:    outcome,
+			StatusCode: statusCode,
+		})
+
+		return nil
+	}
+}
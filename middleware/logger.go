@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+	"todo-app-api/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const maxRequestIdLength = 128
+
+// RequestId takes the request ID from X-Request-ID, generating one when it is
+// missing or unreasonably long, echoes it in the response and stores it on
+// the user context for the service and repository layers.
+func RequestId() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestId := c.Get(fiber.HeaderXRequestID)
+		if requestId == "" || len(requestId) > maxRequestIdLength {
+			requestId = uuid.NewString()
+		}
+
+		c.Set(fiber.HeaderXRequestID, requestId)
+		c.SetUserContext(helper.WithRequestId(c.UserContext(), requestId))
+		return c.Next()
+	}
+}
+
+// AccessLog writes one record per request. Like Audit it renders handler
+// errors itself so the logged status is the one the client receives.
+func AccessLog(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		if err := next(c); err != nil {
+			if handlerErr := c.App().Config().ErrorHandler(c, err); handlerErr != nil {
+				return handlerErr
+			}
+		}
+
+		status := c.Response().StatusCode()
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Method()),
+			slog.String("path", c.Path()),
+			slog.String("route", c.Route().Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes_in", len(c.Request().Body())),
+			slog.String("ip", c.IP()),
+		}
+		// reading a streamed body would block until the stream ends
+		if !c.Response().IsBodyStream() {
+			attrs = append(attrs, slog.Int("bytes_out", len(c.Response().Body())))
+		}
+
+		logger.LogAttrs(c.UserContext(), level, "request", attrs...)
+		return nil
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"todo-app-api/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace starts a server span per request, continuing the trace named in an
+// incoming traceparent header, and puts it on the user context for the
+// service and repository layers. Like AccessLog it renders handler errors
+// itself so the span records the final status.
+func Trace() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		headers := http.Header{}
+		c.Request().Header.VisitAll(func(key, value []byte) {
+			headers.Add(string(key), string(value))
+		})
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), propagation.HeaderCarrier(headers))
+
+		method := utils.CopyString(c.Method())
+		ctx, span := tracing.Tracer().Start(ctx, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(utils.CopyString(c.Path())),
+				semconv.ClientAddress(c.IP()),
+			),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		if err := next(c); err != nil {
+			span.RecordError(err)
+			if handlerErr := c.App().Config().ErrorHandler(c, err); handlerErr != nil {
+				span.SetStatus(codes.Error, handlerErr.Error())
+				return handlerErr
+			}
+		}
+
+		status := c.Response().StatusCode()
+		span.SetName(method + " " + c.Route().Path)
+		span.SetAttributes(
+			semconv.HTTPRoute(c.Route().Path),
+			semconv.HTTPResponseStatusCode(status),
+		)
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		return nil
+	}
+}
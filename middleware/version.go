@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app-api/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	HeaderAPIVersion  = "API-Version"
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+
+	LatestAPIVersion = 2
+)
+
+var (
+	versionPrefix = regexp.MustCompile(`^/v(\d+)(/|$)`)
+	vendorMedia   = regexp.MustCompile(`^application/vnd\.todo\.v(\d+)\+json$`)
+)
+
+// APIVersion serves the resources under /v1 and /v2. A request for an
+// unversioned resource path such as /todos is rewritten to the version its
+// Accept header asks for (application/vnd.todo.v2+json or
+// application/json; version=2), defaulting to v1 so existing clients keep
+// working. Responses name their version in API-Version, and v1 responses
+// announce its retirement with Deprecation, Sunset and a successor link.
+func APIVersion(resources []string, deprecatedAt time.Time, sunset time.Time) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		path := c.Path()
+
+		var version int
+		if match := versionPrefix.FindStringSubmatch(path); match != nil {
+			version, _ = strconv.Atoi(match[1])
+			if version < 1 || version > LatestAPIVersion {
+				return c.Next()
+			}
+		} else {
+			if !isResource(path, resources) {
+				return c.Next()
+			}
+
+			negotiated, err := negotiate(c.Get(fiber.HeaderAccept))
+			if err != nil {
+				return fiber.NewError(fiber.StatusNotAcceptable, err.Error())
+			}
+			version = negotiated
+			path = fmt.Sprintf("/v%d%s", version, path)
+			c.Path(path)
+			c.Vary(fiber.HeaderAccept)
+		}
+
+		c.SetUserContext(helper.WithAPIVersion(c.UserContext(), version))
+		c.Set(HeaderAPIVersion, strconv.Itoa(version))
+		if version < LatestAPIVersion {
+			if !deprecatedAt.IsZero() {
+				c.Set(HeaderDeprecation, fmt.Sprintf("@%d", deprecatedAt.Unix()))
+			}
+			if !sunset.IsZero() {
+				c.Set(HeaderSunset, sunset.UTC().Format(http.TimeFormat))
+			}
+			successor := fmt.Sprintf("/v%d%s", LatestAPIVersion, versionPrefix.ReplaceAllString(path, "$2"))
+			c.Set(fiber.HeaderLink, fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+		}
+		return c.Next()
+	}
+}
+
+func isResource(path string, resources []string) bool {
+	for _, resource := range resources {
+		if path == resource || strings.HasPrefix(path, resource+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiate picks the API version from the first media range of accept that
+// names one.
+func negotiate(accept string) (int, error) {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+
+		requested := params["version"]
+		if match := vendorMedia.FindStringSubmatch(mediaType); match != nil {
+			requested = match[1]
+		}
+		if requested == "" {
+			continue
+		}
+
+		version, err := strconv.Atoi(strings.TrimPrefix(requested, "v"))
+		if err != nil || version < 1 || version > LatestAPIVersion {
+			return 0, fmt.Errorf("API version %s is not supported, use 1 to %d", requested, LatestAPIVersion)
+		}
+		return version, nil
+	}
+	return 1, nil
+}
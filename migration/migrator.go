@@ -0,0 +1,246 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lockKey identifies this application's migration lock on Postgres
+// (pg_advisory_lock) and MySQL (GET_LOCK).
+const lockKey = 7_301_204_611
+
+var ErrSchemaBehind = errors.New("database schema is behind, run `migrate up` or set AUTO_MIGRATE=true")
+
+var ErrLockTimeout = errors.New("timed out waiting for the migration lock held by another instance")
+
+type SchemaMigration struct {
+	Version   int64     `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"column:name"`
+	Checksum  string    `gorm:"column:checksum"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	DB         *gorm.DB
+	Migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := Load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: db, Migrations: migrations}, nil
+}
+
+// withLock runs fn on a single connection while holding the migration lock,
+// so concurrently starting instances apply migrations one at a time. The
+// lock belongs to the session, so it is released even when ctx is cancelled;
+// otherwise it would stay held on a pooled connection.
+func (migrator *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return migrator.DB.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		unlockConn := conn.WithContext(context.WithoutCancel(ctx))
+		switch conn.Dialector.Name() {
+		case "postgres":
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", lockKey).Error; err != nil {
+				return err
+			}
+			defer unlockConn.Exec("SELECT pg_advisory_unlock(?)", lockKey)
+		case "mysql":
+			// GET_LOCK answers 0 on timeout and NULL on error instead of failing
+			var locked sql.NullInt64
+			if err := conn.Raw("SELECT GET_LOCK(?, 60)", fmt.Sprint(lockKey)).Scan(&locked).Error; err != nil {
+				return err
+			}
+			if !locked.Valid || locked.Int64 != 1 {
+				return ErrLockTimeout
+			}
+			defer unlockConn.Exec("SELECT RELEASE_LOCK(?)", fmt.Sprint(lockKey))
+		}
+
+		if err := conn.AutoMigrate(&SchemaMigration{}); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+func applied(conn *gorm.DB) (map[int64]SchemaMigration, error) {
+	var rows []SchemaMigration
+	if err := conn.Order("version ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := map[int64]SchemaMigration{}
+	for _, row := range rows {
+		result[row.Version] = row
+	}
+	return result, nil
+}
+
+// verify fails when an applied migration was edited after it ran.
+func (migrator *Migrator) verify(appliedMigrations map[int64]SchemaMigration) error {
+	for _, migration := range migrator.Migrations {
+		row, ok := appliedMigrations[migration.Version]
+		if ok && row.Checksum != migration.Checksum {
+			return fmt.Errorf("checksum mismatch for migration %d_%s: applied %s, file %s", migration.Version, migration.Name, row.Checksum, migration.Checksum)
+		}
+	}
+	return nil
+}
+
+func (migrator *Migrator) run(conn *gorm.DB, migration Migration, up bool) error {
+	script := migration.Down
+	if up {
+		script = migration.Up
+	}
+
+	return conn.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements(script) {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+		}
+		if up {
+			return tx.Create(&SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Checksum:  migration.Checksum,
+				AppliedAt: time.Now().UTC(),
+			}).Error
+		}
+		return tx.Delete(&SchemaMigration{}, migration.Version).Error
+	})
+}
+
+// To migrates up or down until version is the latest applied migration.
+// Version 0 reverts everything.
+func (migrator *Migrator) To(ctx context.Context, version int64) ([]Migration, error) {
+	if version != 0 && migrator.find(version) == nil {
+		return nil, fmt.Errorf("unknown migration version %d", version)
+	}
+
+	var ran []Migration
+	err := migrator.withLock(ctx, func(conn *gorm.DB) error {
+		appliedMigrations, err := applied(conn)
+		if err != nil {
+			return err
+		}
+		if err := migrator.verify(appliedMigrations); err != nil {
+			return err
+		}
+
+		for _, migration := range migrator.Migrations {
+			if _, ok := appliedMigrations[migration.Version]; ok || migration.Version > version {
+				continue
+			}
+			if err := migrator.run(conn, migration, true); err != nil {
+				return err
+			}
+			ran = append(ran, migration)
+		}
+
+		for i := len(migrator.Migrations) - 1; i >= 0; i-- {
+			migration := migrator.Migrations[i]
+			if _, ok := appliedMigrations[migration.Version]; !ok || migration.Version <= version {
+				continue
+			}
+			if err := migrator.run(conn, migration, false); err != nil {
+				return err
+			}
+			ran = append(ran, migration)
+		}
+		return nil
+	})
+	return ran, err
+}
+
+func (migrator *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	if len(migrator.Migrations) == 0 {
+		return nil, nil
+	}
+	return migrator.To(ctx, migrator.Migrations[len(migrator.Migrations)-1].Version)
+}
+
+// Down reverts the most recently applied migration.
+func (migrator *Migrator) Down(ctx context.Context) ([]Migration, error) {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous, latest int64
+	for _, status := range statuses {
+		if status.Applied {
+			previous, latest = latest, status.Version
+		}
+	}
+	if latest == 0 {
+		return nil, nil
+	}
+	return migrator.To(ctx, previous)
+}
+
+func (migrator *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := migrator.withLock(ctx, func(conn *gorm.DB) error {
+		appliedMigrations, err := applied(conn)
+		if err != nil {
+			return err
+		}
+		if err := migrator.verify(appliedMigrations); err != nil {
+			return err
+		}
+		for _, migration := range migrator.Migrations {
+			row, ok := appliedMigrations[migration.Version]
+			statuses = append(statuses, Status{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Applied:   ok,
+				AppliedAt: row.AppliedAt,
+			})
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// Pending returns the migrations that have not been applied yet.
+func (migrator *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for i, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, migrator.Migrations[i])
+		}
+	}
+	return pending, nil
+}
+
+func (migrator *Migrator) find(version int64) *Migration {
+	for i := range migrator.Migrations {
+		if migrator.Migrations[i].Version == version {
+			return &migrator.Migrations[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+CREATE TABLE calendar_tokens (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    created_at DATETIME(6)
+);
+CREATE UNIQUE INDEX idx_calendar_tokens_token_hash ON calendar_tokens (token_hash);
+CREATE TABLE calendar_objects (
+    todo_id BIGINT PRIMARY KEY,
+    uid VARCHAR(255) NOT NULL
+);
+CREATE UNIQUE INDEX idx_calendar_objects_uid ON calendar_objects (uid);
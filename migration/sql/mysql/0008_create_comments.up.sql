@@ -0,0 +1,28 @@
+CREATE TABLE comments (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    todo_id BIGINT NOT NULL,
+    parent_id BIGINT,
+    actor VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME(6),
+    updated_at DATETIME(6),
+    edited_at DATETIME(6),
+    deleted_at DATETIME(6)
+);
+CREATE INDEX idx_comments_todo_id ON comments (todo_id, parent_id);
+CREATE TABLE comment_edits (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    comment_id BIGINT NOT NULL,
+    body TEXT NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    created_at DATETIME(6)
+);
+CREATE INDEX idx_comment_edits_comment_id ON comment_edits (comment_id);
+CREATE TABLE comment_reactions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    comment_id BIGINT NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    emoji VARCHAR(20) NOT NULL,
+    created_at DATETIME(6)
+);
+CREATE UNIQUE INDEX idx_comment_reactions_unique ON comment_reactions (comment_id, actor, emoji);
@@ -0,0 +1 @@
+-- the empty statuses are not restored
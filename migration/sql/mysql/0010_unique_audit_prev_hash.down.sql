@@ -0,0 +1 @@
+DROP INDEX idx_audit_logs_prev_hash ON audit_logs;
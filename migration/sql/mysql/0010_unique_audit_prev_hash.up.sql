@@ -0,0 +1,2 @@
+-- two entries claiming the same predecessor would fork the chain
+CREATE UNIQUE INDEX idx_audit_logs_prev_hash ON audit_logs (prev_hash);
@@ -0,0 +1,3 @@
+ALTER TABLE outbox_messages DROP COLUMN failed_at;
+ALTER TABLE outbox_messages DROP COLUMN claimed_until;
+ALTER TABLE outbox_messages DROP COLUMN next_attempt_at;
@@ -0,0 +1,3 @@
+ALTER TABLE outbox_messages ADD COLUMN next_attempt_at DATETIME(6) NULL;
+ALTER TABLE outbox_messages ADD COLUMN claimed_until DATETIME(6) NULL;
+ALTER TABLE outbox_messages ADD COLUMN failed_at DATETIME(6) NULL;
@@ -0,0 +1 @@
+DROP INDEX idx_webhook_deliveries_event ON webhook_deliveries;
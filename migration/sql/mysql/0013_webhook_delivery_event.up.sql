@@ -0,0 +1,3 @@
+-- an event the relay hands out again gets one delivery per subscription
+DELETE duplicate FROM webhook_deliveries duplicate JOIN webhook_deliveries kept ON kept.subscription_id = duplicate.subscription_id AND kept.event_id = duplicate.event_id AND kept.id < duplicate.id;
+CREATE UNIQUE INDEX idx_webhook_deliveries_event ON webhook_deliveries (subscription_id, event_id);
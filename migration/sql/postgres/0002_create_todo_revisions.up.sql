@@ -0,0 +1,11 @@
+CREATE TABLE todo_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    todo_id BIGINT NOT NULL,
+    revision BIGINT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    changes TEXT NOT NULL,
+    snapshot TEXT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX idx_todo_revisions_todo_revision ON todo_revisions (todo_id, revision);
@@ -0,0 +1,15 @@
+CREATE TABLE outbox_messages (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_type VARCHAR(64) NOT NULL,
+    aggregate_id BIGINT NOT NULL,
+    event_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL,
+    published_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX idx_outbox_messages_event_id ON outbox_messages (event_id);
+CREATE INDEX idx_outbox_messages_aggregate ON outbox_messages (aggregate_type, aggregate_id);
+CREATE INDEX idx_outbox_messages_published_at ON outbox_messages (published_at);
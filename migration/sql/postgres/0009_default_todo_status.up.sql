@@ -0,0 +1,2 @@
+-- updates without a status used to store an empty one
+UPDATE todos SET status = 'pending' WHERE status = '' OR status IS NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE outbox_messages ADD COLUMN next_attempt_at TIMESTAMPTZ NULL;
+ALTER TABLE outbox_messages ADD COLUMN claimed_until TIMESTAMPTZ NULL;
+ALTER TABLE outbox_messages ADD COLUMN failed_at TIMESTAMPTZ NULL;
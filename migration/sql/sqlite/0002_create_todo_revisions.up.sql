@@ -0,0 +1,11 @@
+CREATE TABLE todo_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    todo_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    changes TEXT NOT NULL,
+    snapshot TEXT NOT NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_todo_revisions_todo_revision ON todo_revisions (todo_id, revision);
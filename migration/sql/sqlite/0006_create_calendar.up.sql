@@ -0,0 +1,12 @@
+CREATE TABLE calendar_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_calendar_tokens_token_hash ON calendar_tokens (token_hash);
+CREATE TABLE calendar_objects (
+    todo_id INTEGER PRIMARY KEY,
+    uid VARCHAR(255) NOT NULL
+);
+CREATE UNIQUE INDEX idx_calendar_objects_uid ON calendar_objects (uid);
@@ -0,0 +1,8 @@
+CREATE TABLE todo_references (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    todo_id INTEGER NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    value VARCHAR(2048) NOT NULL
+);
+CREATE INDEX idx_todo_references_todo_id ON todo_references (todo_id);
+CREATE INDEX idx_todo_references_kind_value ON todo_references (kind, value);
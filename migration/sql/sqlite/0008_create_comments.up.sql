@@ -0,0 +1,28 @@
+CREATE TABLE comments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    todo_id INTEGER NOT NULL,
+    parent_id INTEGER,
+    actor VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    edited_at DATETIME,
+    deleted_at DATETIME
+);
+CREATE INDEX idx_comments_todo_id ON comments (todo_id, parent_id);
+CREATE TABLE comment_edits (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    comment_id INTEGER NOT NULL,
+    body TEXT NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    created_at DATETIME
+);
+CREATE INDEX idx_comment_edits_comment_id ON comment_edits (comment_id);
+CREATE TABLE comment_reactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    comment_id INTEGER NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    emoji VARCHAR(20) NOT NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_comment_reactions_unique ON comment_reactions (comment_id, actor, emoji);
@@ -0,0 +1,2 @@
+-- entries written so far keep the hash encoding they were written with
+ALTER TABLE audit_logs ADD COLUMN hash_version INTEGER NOT NULL DEFAULT 1;
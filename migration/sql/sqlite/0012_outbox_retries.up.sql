@@ -0,0 +1,3 @@
+ALTER TABLE outbox_messages ADD COLUMN next_attempt_at DATETIME NULL;
+ALTER TABLE outbox_messages ADD COLUMN claimed_until DATETIME NULL;
+ALTER TABLE outbox_messages ADD COLUMN failed_at DATETIME NULL;
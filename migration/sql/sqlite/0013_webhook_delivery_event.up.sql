@@ -0,0 +1,3 @@
+-- an event the relay hands out again gets one delivery per subscription
+DELETE FROM webhook_deliveries WHERE id NOT IN (SELECT MIN(id) FROM webhook_deliveries GROUP BY subscription_id, event_id);
+CREATE UNIQUE INDEX idx_webhook_deliveries_event ON webhook_deliveries (subscription_id, event_id);
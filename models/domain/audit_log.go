@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// AuditLog is one entry of the append-only audit trail. Hash covers the
+// entry's fields plus PrevHash, chaining every entry to the one before it.
+type AuditLog struct {
+	Id         int    `gorm:"column:id;primaryKey"`
+	Actor      string `gorm:"column:actor;index"`
+	Ip         string `gorm:"column:ip"`
+	UserAgent  string `gorm:"column:user_agent"`
+	RequestId  string `gorm:"column:request_id"`
+	Method     string `gorm:"column:method"`
+	Path       string `gorm:"column:path"`
+	Action     string `gorm:"column:action;index"`
+	Resource   string `gorm:"column:resource;index"`
+	Outcome    string `gorm:"column:outcome"`
+	StatusCode int    `gorm:"column:status_code"`
+	PrevHash   string `gorm:"column:prev_hash;uniqueIndex"`
+	Hash       string `gorm:"column:hash;uniqueIndex"`
+	// HashVersion is the encoding of the fields Hash was computed over.
+	HashVersion int       `gorm:"column:hash_version"`
+	CreatedAt   time.Time `gorm:"column:created_at;index"`
+}
+
+type AuditLogFilter struct {
+	Actor    string
+	Action   string
+	Resource string
+	Outcome  string
+	From     time.Time
+	To       time.Time
+	Offset   int
+	Limit    int
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// CalendarToken grants access to the calendar feed. Only the SHA-256 hash of
+// the token is stored; the token itself is shown once, when it is created.
+type CalendarToken struct {
+	Id        int       `gorm:"column:id;primaryKey"`
+	Actor     string    `gorm:"column:actor"`
+	TokenHash string    `gorm:"column:token_hash;uniqueIndex"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+// CalendarObject remembers the UID a calendar app gave a todo it uploaded,
+// so the feed keeps serving it under that UID. Todos created through the API
+// have no row and use their default UID.
+type CalendarObject struct {
+	TodoId int    `gorm:"column:todo_id;primaryKey;autoIncrement:false"`
+	Uid    string `gorm:"column:uid;uniqueIndex"`
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// Comment is a message on a todo, or a reply to another comment of the same
+// todo when ParentId is set. A deleted comment keeps its row, without its
+// body, so the replies below it keep their place in the thread.
+type Comment struct {
+	Id        int        `gorm:"column:id;primaryKey"`
+	TodoId    int        `gorm:"column:todo_id"`
+	ParentId  *int       `gorm:"column:parent_id"`
+	Actor     string     `gorm:"column:actor"`
+	Body      string     `gorm:"column:body"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time  `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	EditedAt  *time.Time `gorm:"column:edited_at"`
+	DeletedAt *time.Time `gorm:"column:deleted_at"`
+}
+
+// CommentEdit keeps the body a comment had before an edit.
+type CommentEdit struct {
+	Id        int       `gorm:"column:id;primaryKey"`
+	CommentId int       `gorm:"column:comment_id"`
+	Body      string    `gorm:"column:body"`
+	Actor     string    `gorm:"column:actor"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+type CommentReaction struct {
+	Id        int       `gorm:"column:id;primaryKey"`
+	CommentId int       `gorm:"column:comment_id"`
+	Actor     string    `gorm:"column:actor"`
+	Emoji     string    `gorm:"column:emoji"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+// CommentFilter pages through the threads of a todo, that is its comments
+// that are not replies.
+type CommentFilter struct {
+	TodoId int
+	Offset int
+	Limit  int
+}
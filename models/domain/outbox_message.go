@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// OutboxMessage is an event written in the same transaction as the change it
+// describes. It stays unpublished until the relay has handed it off, or until
+// it failed too often and is parked with FailedAt set.
+type OutboxMessage struct {
+	Id            int        `gorm:"column:id;primaryKey"`
+	AggregateType string     `gorm:"column:aggregate_type;index:idx_outbox_messages_aggregate"`
+	AggregateId   int        `gorm:"column:aggregate_id;index:idx_outbox_messages_aggregate"`
+	EventId       string     `gorm:"column:event_id;uniqueIndex"`
+	EventType     string     `gorm:"column:event_type"`
+	Payload       string     `gorm:"column:payload;type:text"`
+	Attempts      int        `gorm:"column:attempts"`
+	LastError     string     `gorm:"column:last_error"`
+	PublishedAt   *time.Time `gorm:"column:published_at;index"`
+	NextAttemptAt *time.Time `gorm:"column:next_attempt_at"`
+	ClaimedUntil  *time.Time `gorm:"column:claimed_until"`
+	FailedAt      *time.Time `gorm:"column:failed_at"`
+	CreatedAt     time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
@@ -10,3 +10,10 @@ type Todo struct {
 	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt   time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
 }
+
+type TodoFilter struct {
+	Status string
+	// Mention and Link match the references indexed from the description.
+	Mention string
+	Link    string
+}
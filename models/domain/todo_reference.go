@@ -0,0 +1,15 @@
+package domain
+
+const (
+	TodoReferenceLink    = "link"
+	TodoReferenceMention = "mention"
+)
+
+// TodoReference indexes a link or a mention found in the description of a
+// todo, so todos can be looked up by them.
+type TodoReference struct {
+	Id     int    `gorm:"column:id;primaryKey"`
+	TodoId int    `gorm:"column:todo_id"`
+	Kind   string `gorm:"column:kind"`
+	Value  string `gorm:"column:value"`
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+type TodoSnapshot struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// TodoRevision is an immutable record of a single change to a todo. It has no
+// foreign key to todos so the history outlives the todo itself.
+type TodoRevision struct {
+	Id        int           `gorm:"column:id;primaryKey"`
+	TodoId    int           `gorm:"column:todo_id;index:idx_todo_revisions_todo_revision,unique"`
+	Revision  int           `gorm:"column:revision;index:idx_todo_revisions_todo_revision,unique"`
+	Action    string        `gorm:"column:action"`
+	Actor     string        `gorm:"column:actor"`
+	Changes   []FieldChange `gorm:"column:changes;type:text;serializer:json"`
+	Snapshot  TodoSnapshot  `gorm:"column:snapshot;type:text;serializer:json"`
+	CreatedAt time.Time     `gorm:"column:created_at;autoCreateTime"`
+}
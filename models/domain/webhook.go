@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+type WebhookSubscription struct {
+	Id        int       `gorm:"column:id;primaryKey"`
+	Url       string    `gorm:"column:url"`
+	Secret    string    `gorm:"column:secret"`
+	Events    []string  `gorm:"column:events;type:text;serializer:json"`
+	Active    bool      `gorm:"column:active;default:true"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (subscription WebhookSubscription) Accepts(eventType string) bool {
+	for _, accepted := range subscription.Events {
+		if accepted == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery tracks one event sent to one subscription. Failed attempts
+// stay pending until MaxAttempts is reached and the delivery is marked dead.
+type WebhookDelivery struct {
+	Id             int        `gorm:"column:id;primaryKey"`
+	SubscriptionId int        `gorm:"column:subscription_id;index;uniqueIndex:idx_webhook_deliveries_event"`
+	EventId        string     `gorm:"column:event_id;uniqueIndex:idx_webhook_deliveries_event"`
+	EventType      string     `gorm:"column:event_type"`
+	Payload        string     `gorm:"column:payload;type:text"`
+	Status         string     `gorm:"column:status;index"`
+	Attempts       int        `gorm:"column:attempts"`
+	ResponseCode   int        `gorm:"column:response_code"`
+	LastError      string     `gorm:"column:last_error"`
+	NextAttemptAt  time.Time  `gorm:"column:next_attempt_at;index"`
+	DeliveredAt    *time.Time `gorm:"column:delivered_at"`
+	CreatedAt      time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
@@ -0,0 +1,12 @@
+package web
+
+type AuditLogFilterRequest struct {
+	Actor    string `query:"actor"`
+	Action   string `query:"action"`
+	Resource string `query:"resource"`
+	Outcome  string `query:"outcome" validate:"omitempty,oneof=success failure"`
+	From     string `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To       string `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Page     int    `query:"page" validate:"omitempty,min=1"`
+	Size     int    `query:"size" validate:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,25 @@
+package web
+
+import "time"
+
+type AuditLogResponse struct {
+	Id         int       `json:"id"`
+	Actor      string    `json:"actor"`
+	Ip         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	RequestId  string    `json:"request_id"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Action     string    `json:"action"`
+	Resource   string    `json:"resource"`
+	Outcome    string    `json:"outcome"`
+	StatusCode int       `json:"status_code"`
+	Hash       string    `json:"hash"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type AuditVerifyResponse struct {
+	Valid    bool `json:"valid"`
+	Entries  int  `json:"entries"`
+	BrokenAt int  `json:"broken_at,omitempty"`
+}
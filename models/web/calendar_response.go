@@ -0,0 +1,27 @@
+package web
+
+import "time"
+
+// CalendarTokenResponse carries the token only when it is created; the feed
+// is served at FeedPath with the token as its token query parameter.
+type CalendarTokenResponse struct {
+	Id        int       `json:"id"`
+	Actor     string    `json:"actor"`
+	Token     string    `json:"token"`
+	FeedPath  string    `json:"feed_path"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CalendarUploadResponse struct {
+	Results []CalendarUploadResult `json:"results"`
+}
+
+// CalendarUploadResult reports one uploaded VTODO as created, updated,
+// unchanged, stale (an older SEQUENCE than the stored todo) or invalid.
+type CalendarUploadResult struct {
+	Uid      string   `json:"uid"`
+	Result   string   `json:"result"`
+	TodoId   int      `json:"todo_id,omitempty"`
+	Sequence int      `json:"sequence"`
+	Errors   []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,28 @@
+package web
+
+type CommentCreateRequest struct {
+	TodoId int `json:"-" validate:"required"`
+	// ParentId replies to another comment of the same todo.
+	ParentId *int   `json:"parent_id" validate:"omitempty,min=1"`
+	Body     string `json:"body" validate:"required,max=10000"`
+}
+
+type CommentUpdateRequest struct {
+	Id     int    `json:"-" validate:"required"`
+	TodoId int    `json:"-" validate:"required"`
+	Body   string `json:"body" validate:"required,max=10000"`
+}
+
+type CommentFilterRequest struct {
+	Page int `query:"page" validate:"omitempty,min=1"`
+	Size int `query:"size" validate:"omitempty,min=1,max=100"`
+}
+
+// CommentReactionRequest adds the reaction of the calling actor, or removes
+// it when Reacted is false.
+type CommentReactionRequest struct {
+	Id      int    `json:"-" validate:"required"`
+	TodoId  int    `json:"-" validate:"required"`
+	Emoji   string `json:"emoji" validate:"required,oneof=+1 -1 laugh hooray confused heart rocket eyes"`
+	Reacted *bool  `json:"reacted" validate:"required"`
+}
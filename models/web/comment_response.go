@@ -0,0 +1,32 @@
+package web
+
+import "time"
+
+// CommentResponse is a comment with the replies below it. A deleted comment
+// keeps its place in the thread with an empty body.
+type CommentResponse struct {
+	Id        int                       `json:"id"`
+	TodoId    int                       `json:"todo_id"`
+	ParentId  *int                      `json:"parent_id"`
+	Actor     string                    `json:"actor"`
+	Body      string                    `json:"body"`
+	Edited    bool                      `json:"edited"`
+	Deleted   bool                      `json:"deleted"`
+	Reactions []CommentReactionResponse `json:"reactions"`
+	Replies   []CommentResponse         `json:"replies"`
+	CreatedAt time.Time                 `json:"created_at"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+}
+
+type CommentReactionResponse struct {
+	Emoji  string   `json:"emoji"`
+	Count  int      `json:"count"`
+	Actors []string `json:"actors"`
+}
+
+// CommentEditResponse is a body a comment had before it was edited.
+type CommentEditResponse struct {
+	Body      string    `json:"body"`
+	Actor     string    `json:"actor"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,35 @@
+package web
+
+import "time"
+
+type HealthCheckResponse struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type HealthResponse struct {
+	Status    string                         `json:"status"`
+	Checks    map[string]HealthCheckResponse `json:"checks,omitempty"`
+	CheckedAt time.Time                      `json:"checked_at"`
+}
+
+type VersionResponse struct {
+	Version       string    `json:"version"`
+	Revision      string    `json:"revision,omitempty"`
+	GoVersion     string    `json:"go_version"`
+	StartedAt     time.Time `json:"started_at"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+}
+
+type DatabaseStatsResponse struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	WaitDurationMs     int64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64 `json:"max_lifetime_closed"`
+}
@@ -1,7 +1,7 @@
 package web
 
 type TodoCreateRequest struct {
-	Title       string `validate:"required,min=2,max=200"`
-	Description string `validate:"required"`
-	Status      string `validate:"omitempty,oneof=pending done"`
+	Title       string `json:"title" validate:"required,min=2,max=200"`
+	Description string `json:"description" validate:"required"`
+	Status      string `json:"status" validate:"omitempty,oneof=pending done"`
 }
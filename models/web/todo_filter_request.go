@@ -0,0 +1,29 @@
+package web
+
+// TodoFilterRequest filters the todo list. Mention and Link match the
+// @mentions and links of the Markdown description; Render adds
+// description_html and Include comment_count to every todo.
+type TodoFilterRequest struct {
+	Status  string `query:"status" validate:"omitempty,oneof=pending done"`
+	Mention string `query:"mention"`
+	Link    string `query:"link"`
+	Render  string `query:"render" validate:"omitempty,oneof=html"`
+	Include string `query:"include" validate:"omitempty,oneof=comment_count"`
+}
+
+// TodoRenderRequest asks for the description of a single todo to be
+// rendered, or for its comment count. v2 always has the comment count.
+type TodoRenderRequest struct {
+	Render  string `query:"render" validate:"omitempty,oneof=html"`
+	Include string `query:"include" validate:"omitempty,oneof=comment_count"`
+}
+
+// TodoExportRequest takes the filters of TodoFilterRequest plus the output
+// format and a comma separated list of columns, all of them when empty.
+type TodoExportRequest struct {
+	Status  string `query:"status" validate:"omitempty,oneof=pending done"`
+	Mention string `query:"mention"`
+	Link    string `query:"link"`
+	Format  string `query:"format" validate:"omitempty,oneof=csv json ndjson"`
+	Columns string `query:"columns"`
+}
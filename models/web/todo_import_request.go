@@ -0,0 +1,14 @@
+package web
+
+// TodoImportRequest carries the file to import in Content. Mapping names,
+// per todo field, the column or key to read it from; Duplicates decides
+// whether todos whose title already exists are skipped or imported again.
+// Without Partial a single invalid row stops the whole import.
+type TodoImportRequest struct {
+	Format     string            `json:"format" validate:"required,oneof=csv json ndjson todoist trello mstodo"`
+	Content    string            `json:"content" validate:"required"`
+	Mapping    map[string]string `json:"mapping" validate:"omitempty,dive,keys,oneof=title description status,endkeys,required"`
+	DryRun     bool              `json:"dry_run"`
+	Partial    bool              `json:"partial"`
+	Duplicates string            `json:"duplicates" validate:"omitempty,oneof=skip allow"`
+}
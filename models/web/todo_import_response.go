@@ -0,0 +1,24 @@
+package web
+
+type TodoImportResponse struct {
+	DryRun bool `json:"dry_run"`
+	// Committed tells whether the valid rows were stored: never on a dry
+	// run, and not when a row failed without partial.
+	Committed bool                    `json:"committed"`
+	Total     int                     `json:"total"`
+	Imported  int                     `json:"imported"`
+	Skipped   int                     `json:"skipped"`
+	Failed    int                     `json:"failed"`
+	Rows      []TodoImportRowResponse `json:"rows"`
+}
+
+// TodoImportRowResponse reports one row as created, valid (it would be
+// created), skipped or invalid.
+type TodoImportRowResponse struct {
+	Line   int      `json:"line"`
+	Title  string   `json:"title"`
+	Result string   `json:"result"`
+	TodoId int      `json:"todo_id,omitempty"`
+	Reason string   `json:"reason,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
@@ -1,8 +1,21 @@
 package web
 
+import "time"
+
 type TodoResponse struct {
 	Id          int    `json:"id"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	Status      string `json:"status"`
+	// DescriptionHtml is the description rendered from Markdown, only
+	// filled in when asked for with ?render=html.
+	DescriptionHtml string `json:"description_html,omitempty"`
+	// IncludedCommentCount is CommentCount, only filled in when asked for
+	// with ?include=comment_count.
+	IncludedCommentCount *int `json:"comment_count,omitempty"`
+	// CreatedAt, UpdatedAt and CommentCount are only part of the v2
+	// contract.
+	CreatedAt    time.Time `json:"-"`
+	UpdatedAt    time.Time `json:"-"`
+	CommentCount int       `json:"-"`
 }
@@ -0,0 +1,14 @@
+package web
+
+import "time"
+
+type TodoResponseV2 struct {
+	Id              int       `json:"id"`
+	Title           string    `json:"title"`
+	Description     string    `json:"description"`
+	Status          string    `json:"status"`
+	DescriptionHtml string    `json:"description_html,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	CommentCount    int       `json:"comment_count"`
+}
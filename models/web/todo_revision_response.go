@@ -0,0 +1,18 @@
+package web
+
+import "time"
+
+type FieldChangeResponse struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type TodoRevisionResponse struct {
+	Revision  int                   `json:"revision"`
+	TodoId    int                   `json:"todo_id"`
+	Action    string                `json:"action"`
+	Actor     string                `json:"actor"`
+	Changes   []FieldChangeResponse `json:"changes"`
+	CreatedAt time.Time             `json:"created_at"`
+}
@@ -0,0 +1,9 @@
+package web
+
+// TodoTaskRequest checks or unchecks an item of a task list in the
+// description. Task counts the items from 1 in the order they appear.
+type TodoTaskRequest struct {
+	Id      int   `json:"-" validate:"required"`
+	Task    int   `json:"-" validate:"required,min=1"`
+	Checked *bool `json:"checked" validate:"required"`
+}
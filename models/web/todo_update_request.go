@@ -1,8 +1,8 @@
 package web
 
 type TodoUpdateRequest struct {
-	Id          int    `validate:"required"`
-	Title       string `validate:"required,min=2,max=200"`
-	Description string `validate:"required"`
-	Status      string `validate:"omitempty,oneof=pending done"`
+	Id          int    `json:"-" validate:"required"`
+	Title       string `json:"title" validate:"required,min=2,max=200"`
+	Description string `json:"description" validate:"required"`
+	Status      string `json:"status" validate:"omitempty,oneof=pending done"`
 }
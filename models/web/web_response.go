@@ -4,4 +4,6 @@ type WebResponse struct {
 	Code   int    `json:"code"`
 	Status string `json:"status"`
 	Data   interface{}
+	// TraceId is set on error responses of traced requests.
+	TraceId string `json:"trace_id,omitempty"`
 }
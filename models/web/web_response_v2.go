@@ -0,0 +1,10 @@
+package web
+
+// WebResponseV2 is the envelope of API v2, which names every field in
+// lowercase.
+type WebResponseV2 struct {
+	Code    int         `json:"code"`
+	Status  string      `json:"status"`
+	Data    interface{} `json:"data"`
+	TraceId string      `json:"trace_id,omitempty"`
+}
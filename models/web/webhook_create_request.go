@@ -0,0 +1,7 @@
+package web
+
+type WebhookCreateRequest struct {
+	Url    string   `json:"url" validate:"required,url"`
+	Secret string   `json:"secret" validate:"required,min=16"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=todo.created todo.updated todo.completed todo.deleted"`
+}
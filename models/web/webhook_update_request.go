@@ -0,0 +1,9 @@
+package web
+
+type WebhookUpdateRequest struct {
+	Id     int      `json:"-" validate:"required"`
+	Url    string   `json:"url" validate:"required,url"`
+	Secret string   `json:"secret" validate:"omitempty,min=16"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=todo.created todo.updated todo.completed todo.deleted"`
+	Active bool     `json:"active"`
+}
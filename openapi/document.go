@@ -0,0 +1,235 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"todo-app-api/models/web"
+)
+
+const Version = "3.1.0"
+
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+// Operation describes one route. Request is the body type, Query a struct
+// whose query tags name the query parameters and Response the type carried
+// in the Data field of the success envelope.
+type Operation struct {
+	Method      string
+	Path        string
+	Tag         string
+	Summary     string
+	Description string
+	Query       any
+	Headers     []Parameter
+	// PathParameters documents path parameters that are not numeric ids.
+	PathParameters []Parameter
+	Request        any
+	// RequestContentType documents a raw request body, such as an uploaded
+	// file, in place of the JSON Request.
+	RequestContentType string
+	Response           any
+	// Status defaults to 200. ContentType replaces the JSON envelope for
+	// routes that answer with something else, such as an event stream.
+	Status      int
+	ContentType string
+	Errors      []int
+	// Envelope wraps JSON responses and defaults to web.WebResponse.
+	Envelope   any
+	Deprecated bool
+}
+
+type Parameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+	Schema      Schema `json:"schema"`
+}
+
+// Path returns the OpenAPI form of a Fiber route path: /todos/{todoId}.
+// Group roots, which Fiber registers as /todos/, lose the trailing slash.
+func Path(fiberPath string) string {
+	if len(fiberPath) > 1 {
+		fiberPath = strings.TrimSuffix(fiberPath, "/")
+	}
+	return pathParam.ReplaceAllString(fiberPath, "{$1}")
+}
+
+// Build assembles the OpenAPI document for operations. Every JSON response
+// uses the envelope of its operation; errors share the error schema of that
+// envelope.
+func Build(title string, version string, operations []Operation) map[string]any {
+	schemas := NewSchemas()
+
+	paths := map[string]map[string]any{}
+	for _, operation := range operations {
+		path := Path(operation.Path)
+		if paths[path] == nil {
+			paths[path] = map[string]any{}
+		}
+		paths[path][strings.ToLower(operation.Method)] = buildOperation(schemas, operation)
+	}
+
+	return map[string]any{
+		"openapi": Version,
+		"info": map[string]any{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas.Components,
+		},
+	}
+}
+
+// envelope returns the schema of the envelope of operation, the JSON name of
+// its data field and a reference to its error schema. The error schema of
+// web.WebResponse is ErrorResponse, that of web.WebResponseV2 ErrorResponseV2.
+func envelope(schemas *Schemas, operation Operation) (Schema, string, Schema) {
+	value := operation.Envelope
+	if value == nil {
+		value = web.WebResponse{}
+	}
+
+	t := reflect.TypeOf(value)
+	schema := schemas.Of(value, false)
+	data := "Data"
+	if field, ok := t.FieldByName("Data"); ok {
+		data, _ = jsonName(field)
+	}
+
+	name := "Error" + strings.TrimPrefix(t.Name(), "Web")
+	if _, ok := schemas.Components[name]; !ok {
+		schemas.Components[name] = Schema{
+			"allOf": []Schema{schema, {
+				"properties": map[string]Schema{
+					data:       {"type": "string", "description": "What went wrong."},
+					"trace_id": {"type": "string", "description": "Trace of the failed request, when it was traced."},
+				},
+			}},
+		}
+	}
+	return schema, data, Schema{"$ref": "#/components/schemas/" + name}
+}
+
+func buildOperation(schemas *Schemas, operation Operation) map[string]any {
+	result := map[string]any{
+		"operationId": operationId(operation),
+		"summary":     operation.Summary,
+		"tags":        []string{operation.Tag},
+	}
+	if operation.Description != "" {
+		result["description"] = operation.Description
+	}
+	if operation.Deprecated {
+		result["deprecated"] = true
+	}
+	envelope, data, errorSchema := envelope(schemas, operation)
+
+	// path parameters are numeric ids unless documented otherwise
+	var parameters []Parameter
+	for _, match := range pathParam.FindAllStringSubmatch(operation.Path, -1) {
+		parameter := Parameter{Name: match[1], In: "path", Required: true, Schema: Schema{"type": "integer"}}
+		for _, documented := range operation.PathParameters {
+			if documented.Name == match[1] {
+				parameter = documented
+			}
+		}
+		parameters = append(parameters, parameter)
+	}
+	parameters = append(parameters, queryParameters(schemas, operation.Query)...)
+	parameters = append(parameters, operation.Headers...)
+	if len(parameters) > 0 {
+		result["parameters"] = parameters
+	}
+
+	switch {
+	case operation.RequestContentType != "":
+		result["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				operation.RequestContentType: map[string]any{"schema": Schema{"type": "string"}},
+			},
+		}
+	case operation.Request != nil:
+		result["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": schemas.Of(operation.Request, true)},
+			},
+		}
+	}
+
+	status := operation.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]any{"description": http.StatusText(status)}
+	switch {
+	case operation.ContentType == "application/json":
+		success["content"] = map[string]any{operation.ContentType: map[string]any{"schema": Schema{"type": "object"}}}
+	case operation.ContentType != "":
+		success["content"] = map[string]any{operation.ContentType: map[string]any{"schema": Schema{"type": "string"}}}
+	case status != http.StatusSwitchingProtocols:
+		schema := envelope
+		if operation.Response != nil {
+			schema = Schema{"allOf": []Schema{envelope, {
+				"properties": map[string]Schema{data: schemas.Of(operation.Response, false)},
+			}}}
+		}
+		success["content"] = map[string]any{"application/json": map[string]any{"schema": schema}}
+	}
+
+	responses := map[string]any{strconv.Itoa(status): success}
+	for _, code := range operation.Errors {
+		responses[strconv.Itoa(code)] = map[string]any{
+			"description": http.StatusText(code),
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": errorSchema},
+			},
+		}
+	}
+	result["responses"] = responses
+	return result
+}
+
+func queryParameters(schemas *Schemas, query any) []Parameter {
+	if query == nil {
+		return nil
+	}
+
+	var parameters []Parameter
+	t := reflect.TypeOf(query)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("query")
+		if name == "" {
+			continue
+		}
+
+		validate := field.Tag.Get("validate")
+		schema := schemas.of(field.Type, true)
+		Constrain(schema, field.Type, validate)
+		parameters = append(parameters, Parameter{
+			Name:     name,
+			In:       "query",
+			Required: hasRule(validate, "required"),
+			Schema:   schema,
+		})
+	}
+	return parameters
+}
+
+// operationId derives a stable id such as getTodosTodoIdHistory.
+func operationId(operation Operation) string {
+	id := strings.ToLower(operation.Method)
+	for _, segment := range strings.FieldsFunc(operation.Path, func(r rune) bool { return r == '/' || r == '.' }) {
+		segment = strings.TrimPrefix(segment, ":")
+		id += strings.ToUpper(segment[:1]) + segment[1:]
+	}
+	return id
+}
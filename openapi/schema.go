@@ -0,0 +1,289 @@
+package openapi
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema object as used by OpenAPI 3.1.
+type Schema map[string]any
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Schemas turns Go types into JSON schemas, registering every named struct
+// once under components/schemas and referring to it by $ref afterwards.
+type Schemas struct {
+	Components map[string]Schema
+}
+
+func NewSchemas() *Schemas {
+	return &Schemas{Components: map[string]Schema{}}
+}
+
+// Of returns the schema of value's type. Request bodies only require fields
+// validated as required; response fields are required unless omitempty.
+func (schemas *Schemas) Of(value any, request bool) Schema {
+	if value == nil {
+		return Schema{}
+	}
+	return schemas.of(reflect.TypeOf(value), request)
+}
+
+func (schemas *Schemas) of(t reflect.Type, request bool) Schema {
+	switch {
+	case t == timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Pointer:
+		inner := schemas.of(t.Elem(), request)
+		if ref, ok := inner["$ref"]; ok {
+			return Schema{"oneOf": []Schema{{"$ref": ref}, {"type": "null"}}}
+		}
+		inner["type"] = []any{inner["type"], "null"}
+		return inner
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return Schema{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return Schema{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice:
+		// encoding/json writes a nil slice as null
+		if !request {
+			return Schema{"type": []any{"array", "null"}, "items": schemas.of(t.Elem(), request)}
+		}
+		return Schema{"type": "array", "items": schemas.of(t.Elem(), request)}
+	case reflect.Array:
+		return Schema{"type": "array", "items": schemas.of(t.Elem(), request)}
+	case reflect.Map:
+		// a nil map is written as null and null decodes into a nil map
+		return Schema{"type": []any{"object", "null"}, "additionalProperties": schemas.of(t.Elem(), request)}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := schemas.Components[name]; !ok {
+			// placeholder first so self references terminate
+			schemas.Components[name] = Schema{}
+			schemas.Components[name] = schemas.object(t, request)
+		}
+		return Schema{"$ref": "#/components/schemas/" + name}
+	}
+	// interface{} and anything else accepts any value
+	return Schema{}
+}
+
+func (schemas *Schemas) object(t reflect.Type, request bool) Schema {
+	properties := map[string]Schema{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitEmpty := jsonName(field)
+		if name == "-" {
+			continue
+		}
+
+		validate := field.Tag.Get("validate")
+		property := schemas.of(field.Type, request)
+		Constrain(property, field.Type, validate)
+		properties[name] = property
+
+		isRequired := !omitEmpty && !request
+		if request {
+			isRequired = hasRule(validate, "required")
+		}
+		if isRequired {
+			required = append(required, name)
+		}
+	}
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// jsonName mirrors encoding/json: the tag name when set, else the field name.
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(options, "omitempty")
+}
+
+// hasRule looks at the rules of the field itself, not those after dive.
+func hasRule(validate string, rule string) bool {
+	for _, part := range strings.Split(validate, ",") {
+		if part == "dive" {
+			return false
+		}
+		if part == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Constrain translates validator tags into schema keywords: min/max/len
+// become length, item or value bounds depending on the type, oneof becomes
+// an enum and url, email and RFC 3339 datetime become formats. Rules after
+// dive apply to the items of a slice or the values of a map, whose keys take
+// the rules between keys and endkeys. A required string may not be empty,
+// and omitempty lets the zero value through whatever the other rules say.
+func Constrain(schema Schema, t reflect.Type, validate string) {
+	if validate == "" {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	constraints := Schema{}
+	omitEmpty := false
+	rules := strings.Split(validate, ",")
+rules:
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "omitempty":
+			omitEmpty = true
+		case "required":
+			if t.Kind() == reflect.String {
+				constraints["minLength"] = 1
+			}
+		case "dive":
+			if items, ok := schema["items"].(Schema); ok && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+				Constrain(items, t.Elem(), strings.Join(rules[i+1:], ","))
+			}
+			if values, ok := schema["additionalProperties"].(Schema); ok && t.Kind() == reflect.Map {
+				rest := rules[i+1:]
+				if len(rest) > 0 && rest[0] == "keys" {
+					end := slices.Index(rest, "endkeys")
+					if end < 0 {
+						end = len(rest)
+					}
+					names := Schema{"type": "string"}
+					Constrain(names, t.Key(), strings.Join(rest[1:end], ","))
+					schema["propertyNames"] = names
+					rest = rest[min(end+1, len(rest)):]
+				}
+				Constrain(values, t.Elem(), strings.Join(rest, ","))
+			}
+			break rules
+		case "min", "max", "len", "gte", "lte", "gt", "lt":
+			constrainBound(constraints, t, name, param)
+		case "oneof":
+			var enum []any
+			for _, value := range strings.Fields(param) {
+				enum = append(enum, enumValue(t, value))
+			}
+			constraints["enum"] = enum
+		case "url":
+			constraints["format"] = "uri"
+		case "email":
+			constraints["format"] = "email"
+		case "uuid", "uuid4":
+			constraints["format"] = "uuid"
+		case "datetime":
+			if param == time.RFC3339 {
+				constraints["format"] = "date-time"
+			} else {
+				schema["description"] = "Formatted as " + param
+			}
+		}
+	}
+
+	if zero, ok := zeroValue(t); ok && omitEmpty && len(constraints) > 0 {
+		schema["anyOf"] = []Schema{constraints, {"const": zero}}
+		return
+	}
+	for keyword, value := range constraints {
+		schema[keyword] = value
+	}
+}
+
+// zeroValue is the JSON form of the zero value of scalar types.
+func zeroValue(t reflect.Type) (any, bool) {
+	switch t.Kind() {
+	case reflect.String:
+		return "", true
+	case reflect.Bool:
+		return false, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return 0, true
+	}
+	return nil, false
+}
+
+func constrainBound(schema Schema, t reflect.Type, rule string, param string) {
+	number, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	var lower, upper string
+	switch t.Kind() {
+	case reflect.String:
+		lower, upper = "minLength", "maxLength"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		lower, upper = "minItems", "maxItems"
+		if t.Kind() == reflect.Map {
+			lower, upper = "minProperties", "maxProperties"
+		}
+	default:
+		switch rule {
+		case "gt":
+			schema["exclusiveMinimum"] = number
+		case "lt":
+			schema["exclusiveMaximum"] = number
+		case "min", "gte":
+			schema["minimum"] = number
+		case "max", "lte":
+			schema["maximum"] = number
+		case "len":
+			schema["minimum"], schema["maximum"] = number, number
+		}
+		return
+	}
+
+	count := int(number)
+	switch rule {
+	case "min", "gte":
+		schema[lower] = count
+	case "gt":
+		schema[lower] = count + 1
+	case "max", "lte":
+		schema[upper] = count
+	case "lt":
+		schema[upper] = count - 1
+	case "len":
+		schema[lower], schema[upper] = count, count
+	}
+}
+
+func enumValue(t reflect.Type, value string) any {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if number, err := strconv.Atoi(value); err == nil {
+			return number
+		}
+	}
+	return value
+}
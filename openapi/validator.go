@@ -0,0 +1,572 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// ValidationError lists every way a request or response breaks the document.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+// Request is the part of an HTTP request the document describes.
+type Request struct {
+	Method      string
+	Path        string
+	Query       func(name string) string
+	Header      func(name string) string
+	ContentType string
+	Body        []byte
+}
+
+// Response is the part of an HTTP response the document describes.
+type Response struct {
+	Method      string
+	Path        string
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// Validator checks requests and responses against an OpenAPI document. It
+// understands the JSON Schema keywords Build emits, not the whole standard.
+type Validator struct {
+	schemas map[string]any
+	routes  []route
+}
+
+type route struct {
+	segments   []string
+	operations map[string]any
+}
+
+// NewValidator reads document through its JSON form so it checks exactly
+// what GET /openapi.json serves.
+func NewValidator(document map[string]any) (*Validator, error) {
+	raw, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+
+	var spec struct {
+		Paths      map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return nil, err
+	}
+
+	validator := &Validator{schemas: spec.Components.Schemas}
+	for path, operations := range spec.Paths {
+		validator.routes = append(validator.routes, route{segments: segments(path), operations: operations})
+	}
+	// literal segments win over parameters, so /todos/stream is not a todoId
+	sort.Slice(validator.routes, func(i, j int) bool {
+		return parameterCount(validator.routes[i].segments) < parameterCount(validator.routes[j].segments)
+	})
+	return validator, nil
+}
+
+func segments(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func isParameter(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func parameterCount(segments []string) int {
+	count := 0
+	for _, segment := range segments {
+		if isParameter(segment) {
+			count++
+		}
+	}
+	return count
+}
+
+// find returns the operation serving method and path with the values of its
+// path parameters, or nil when the document does not describe the request.
+func (validator *Validator) find(method string, path string) (map[string]any, map[string]string) {
+	actual := segments(path)
+
+routes:
+	for _, route := range validator.routes {
+		if len(route.segments) != len(actual) {
+			continue
+		}
+
+		params := map[string]string{}
+		for i, segment := range route.segments {
+			switch {
+			case isParameter(segment):
+				params[strings.Trim(segment, "{}")] = actual[i]
+			case segment != actual[i]:
+				continue routes
+			}
+		}
+
+		operation, _ := route.operations[strings.ToLower(method)].(map[string]any)
+		return operation, params
+	}
+	return nil, nil
+}
+
+// ValidateRequest checks the path, query and header parameters and the JSON
+// body of request. Requests the document does not describe pass through.
+func (validator *Validator) ValidateRequest(request Request) error {
+	operation, params := validator.find(request.Method, request.Path)
+	if operation == nil {
+		return nil
+	}
+
+	var problems []string
+	for _, item := range list(operation["parameters"]) {
+		name, _ := item["name"].(string)
+		in, _ := item["in"].(string)
+		schema, _ := item["schema"].(map[string]any)
+		at := in + " " + name
+
+		var value string
+		switch in {
+		case "path":
+			value = params[name]
+		case "query":
+			value = request.Query(name)
+		case "header":
+			value = request.Header(name)
+		}
+		if value == "" {
+			if required, _ := item["required"].(bool); required {
+				problems = append(problems, at+": is required")
+			}
+			continue
+		}
+
+		typed, err := parse(schema, value)
+		if err != nil {
+			problems = append(problems, at+": "+err.Error())
+			continue
+		}
+		problems = append(problems, validator.check(schema, typed, at)...)
+	}
+
+	if body, ok := operation["requestBody"].(map[string]any); ok {
+		problems = append(problems, validator.requestBody(body, request.ContentType, request.Body)...)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func (validator *Validator) requestBody(body map[string]any, contentType string, raw []byte) []string {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		if required, _ := body["required"].(bool); required {
+			return []string{"body: is required"}
+		}
+		return nil
+	}
+
+	content, _ := body["content"].(map[string]any)
+	media, ok := content[mediaType(contentType)].(map[string]any)
+	if !ok {
+		return []string{fmt.Sprintf("body: Content-Type must be %s", strings.Join(keys(content), " or "))}
+	}
+	if mediaType(contentType) != "application/json" {
+		return nil
+	}
+
+	value, err := decode(raw)
+	if err != nil {
+		return []string{"body: is not valid JSON: " + err.Error()}
+	}
+	schema, _ := media["schema"].(map[string]any)
+	return validator.check(schema, value, "body")
+}
+
+// ValidateResponse checks that the status of response is documented for its
+// operation and that a JSON body matches the schema. Server errors may
+// happen anywhere and are not documented per operation.
+func (validator *Validator) ValidateResponse(response Response) error {
+	operation, _ := validator.find(response.Method, response.Path)
+	if operation == nil {
+		return nil
+	}
+
+	responses, _ := operation["responses"].(map[string]any)
+	documented, ok := responses[strconv.Itoa(response.Status)].(map[string]any)
+	if !ok {
+		if response.Status >= 500 {
+			return nil
+		}
+		return &ValidationError{Problems: []string{fmt.Sprintf("status %d is not documented", response.Status)}}
+	}
+
+	content, _ := documented["content"].(map[string]any)
+	if len(content) == 0 {
+		return nil
+	}
+	media, ok := content[mediaType(response.ContentType)].(map[string]any)
+	if !ok {
+		return &ValidationError{Problems: []string{fmt.Sprintf("Content-Type %q is not documented", response.ContentType)}}
+	}
+	if mediaType(response.ContentType) != "application/json" {
+		return nil
+	}
+
+	value, err := decode(response.Body)
+	if err != nil {
+		return &ValidationError{Problems: []string{"response: is not valid JSON: " + err.Error()}}
+	}
+	schema, _ := media["schema"].(map[string]any)
+	if problems := validator.check(schema, value, "response"); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// check validates a decoded JSON value against schema, naming every problem
+// after its location such as body.events[0].
+func (validator *Validator) check(schema map[string]any, value any, at string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, _ := validator.schemas[strings.TrimPrefix(ref, "#/components/schemas/")].(map[string]any)
+		return validator.check(resolved, value, at)
+	}
+
+	// a value of the wrong type breaks every other keyword as well
+	if expected, ok := schema["type"]; ok && !hasType(expected, value) {
+		return []string{at + ": must be " + describeType(expected)}
+	}
+
+	var problems []string
+	for _, sub := range list(schema["allOf"]) {
+		problems = append(problems, validator.check(sub, value, at)...)
+	}
+	// oneOf is treated like anyOf: the alternatives Build emits never overlap
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		if alternatives := list(schema[keyword]); len(alternatives) > 0 {
+			problems = append(problems, validator.checkAny(alternatives, value, at)...)
+		}
+	}
+
+	if expected, ok := schema["const"]; ok && !equal(expected, value) {
+		problems = append(problems, fmt.Sprintf("%s: must be %s", at, display(expected)))
+	}
+	if enum, ok := schema["enum"].([]any); ok && !contains(enum, value) {
+		var names []string
+		for _, item := range enum {
+			names = append(names, fmt.Sprint(item))
+		}
+		problems = append(problems, fmt.Sprintf("%s: must be one of %s", at, strings.Join(names, ", ")))
+	}
+
+	switch value := value.(type) {
+	case string:
+		problems = append(problems, checkString(schema, value, at)...)
+	case json.Number:
+		number, _ := value.Float64()
+		problems = append(problems, checkNumber(schema, number, at)...)
+	case []any:
+		if limit, ok := number(schema["minItems"]); ok && float64(len(value)) < limit {
+			problems = append(problems, fmt.Sprintf("%s: must have at least %v items", at, limit))
+		}
+		if limit, ok := number(schema["maxItems"]); ok && float64(len(value)) > limit {
+			problems = append(problems, fmt.Sprintf("%s: must have at most %v items", at, limit))
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				problems = append(problems, validator.check(items, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	case map[string]any:
+		problems = append(problems, validator.checkObject(schema, value, at)...)
+	}
+	return problems
+}
+
+// checkAny passes when one alternative matches and otherwise reports the
+// alternative that came closest.
+func (validator *Validator) checkAny(alternatives []map[string]any, value any, at string) []string {
+	var closest []string
+	for i, alternative := range alternatives {
+		problems := validator.check(alternative, value, at)
+		if len(problems) == 0 {
+			return nil
+		}
+		if i == 0 || len(problems) < len(closest) {
+			closest = problems
+		}
+	}
+	return closest
+}
+
+func (validator *Validator) checkObject(schema map[string]any, value map[string]any, at string) []string {
+	var problems []string
+	for _, name := range stringList(schema["required"]) {
+		if _, ok := value[name]; !ok {
+			problems = append(problems, at+"."+name+": is required")
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	names, _ := schema["propertyNames"].(map[string]any)
+	for _, name := range keys(value) {
+		if names != nil {
+			if nameProblems := validator.check(names, name, at+"."+name); len(nameProblems) > 0 {
+				problems = append(problems, at+"."+name+": is not an allowed key")
+				continue
+			}
+		}
+		if property, ok := properties[name].(map[string]any); ok {
+			problems = append(problems, validator.check(property, value[name], at+"."+name)...)
+			continue
+		}
+		if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+			problems = append(problems, validator.check(additional, value[name], at+"."+name)...)
+		}
+	}
+	return problems
+}
+
+func checkString(schema map[string]any, value string, at string) []string {
+	var problems []string
+	length := float64(utf8.RuneCountInString(value))
+	if limit, ok := number(schema["minLength"]); ok && length < limit {
+		problems = append(problems, fmt.Sprintf("%s: must be at least %v characters long", at, limit))
+	}
+	if limit, ok := number(schema["maxLength"]); ok && length > limit {
+		problems = append(problems, fmt.Sprintf("%s: must be at most %v characters long", at, limit))
+	}
+
+	format, _ := schema["format"].(string)
+	valid := true
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		valid = err == nil
+	case "uri":
+		parsed, err := url.Parse(value)
+		valid = err == nil && parsed.Scheme != ""
+	case "email":
+		_, err := mail.ParseAddress(value)
+		valid = err == nil
+	case "uuid":
+		_, err := uuid.Parse(value)
+		valid = err == nil
+	}
+	if !valid {
+		problems = append(problems, fmt.Sprintf("%s: must be a valid %s", at, format))
+	}
+	return problems
+}
+
+func checkNumber(schema map[string]any, value float64, at string) []string {
+	var problems []string
+	if limit, ok := number(schema["minimum"]); ok && value < limit {
+		problems = append(problems, fmt.Sprintf("%s: must be at least %v", at, limit))
+	}
+	if limit, ok := number(schema["maximum"]); ok && value > limit {
+		problems = append(problems, fmt.Sprintf("%s: must be at most %v", at, limit))
+	}
+	if limit, ok := number(schema["exclusiveMinimum"]); ok && value <= limit {
+		problems = append(problems, fmt.Sprintf("%s: must be greater than %v", at, limit))
+	}
+	if limit, ok := number(schema["exclusiveMaximum"]); ok && value >= limit {
+		problems = append(problems, fmt.Sprintf("%s: must be less than %v", at, limit))
+	}
+	return problems
+}
+
+// parse turns a parameter into the JSON value its schema expects.
+func parse(schema map[string]any, value string) (any, error) {
+	switch schema["type"] {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return json.Number(value), nil
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return json.Number(value), nil
+	case "boolean":
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean")
+		}
+		return parsed, nil
+	}
+	return value, nil
+}
+
+func decode(raw []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+func mediaType(contentType string) string {
+	media, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return media
+}
+
+func hasType(expected any, value any) bool {
+	if names, ok := expected.([]any); ok {
+		for _, name := range names {
+			if hasType(name, value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch expected {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		parsed, err := number.Float64()
+		return err == nil && parsed == math.Trunc(parsed)
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func describeType(expected any) string {
+	names, ok := expected.([]any)
+	if !ok {
+		names = []any{expected}
+	}
+
+	var described []string
+	for _, name := range names {
+		switch name {
+		case "integer", "array", "object":
+			described = append(described, fmt.Sprintf("an %v", name))
+		case "null":
+			described = append(described, "null")
+		default:
+			described = append(described, fmt.Sprintf("a %v", name))
+		}
+	}
+	return strings.Join(described, " or ")
+}
+
+// number reads a numeric keyword or value, which the document holds as
+// float64 and request bodies as json.Number.
+func number(value any) (float64, bool) {
+	switch value := value.(type) {
+	case float64:
+		return value, true
+	case json.Number:
+		parsed, err := value.Float64()
+		return parsed, err == nil
+	}
+	return 0, false
+}
+
+func equal(expected any, value any) bool {
+	if a, ok := number(expected); ok {
+		b, ok := number(value)
+		return ok && a == b
+	}
+	return reflect.DeepEqual(expected, value)
+}
+
+func contains(enum []any, value any) bool {
+	for _, item := range enum {
+		if equal(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func display(value any) string {
+	raw, _ := json.Marshal(value)
+	return string(raw)
+}
+
+func list(value any) []map[string]any {
+	items, _ := value.([]any)
+	var result []map[string]any
+	for _, item := range items {
+		if item, ok := item.(map[string]any); ok {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func stringList(value any) []string {
+	items, _ := value.([]any)
+	var result []string
+	for _, item := range items {
+		if item, ok := item.(string); ok {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// keys returns the keys of a map in order so problems are reported stably.
+func keys[V any](values map[string]V) []string {
+	result := make([]string, 0, len(values))
+	for key := range values {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,123 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+	"todo-app-api/event"
+	"todo-app-api/helper"
+	"todo-app-api/models/domain"
+	"todo-app-api/repository"
+
+	"gorm.io/gorm"
+)
+
+// Relay moves committed outbox messages to a Publisher. A message is only
+// marked published after the publisher accepted it, so a crash in between
+// leads to a redelivery rather than a lost event (at-least-once). A message
+// that keeps failing is retried with exponential backoff and parked as failed
+// after MaxAttempts, which lets the later events of its todo through.
+type Relay struct {
+	OutboxRepository repository.OutboxRepository
+	DB               *gorm.DB
+	Publisher        event.Publisher
+	PollInterval     time.Duration
+	BatchSize        int
+	MaxAttempts      int
+	BaseBackoff      time.Duration
+	MaxBackoff       time.Duration
+	ClaimTimeout     time.Duration
+	Now              func() time.Time
+}
+
+func NewRelay(outboxRepository repository.OutboxRepository, db *gorm.DB, publisher event.Publisher) *Relay {
+	return &Relay{
+		OutboxRepository: outboxRepository,
+		DB:               db,
+		Publisher:        publisher,
+		PollInterval:     500 * time.Millisecond,
+		BatchSize:        100,
+		MaxAttempts:      10,
+		BaseBackoff:      time.Second,
+		MaxBackoff:       5 * time.Minute,
+		ClaimTimeout:     time.Minute,
+		Now:              time.Now,
+	}
+}
+
+// Run relays messages until ctx is cancelled, then flushes whatever was
+// committed in the meantime before returning.
+func (relay *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(relay.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for relay.RelayBatch(ctx) > 0 {
+		}
+		select {
+		case <-ctx.Done():
+			for relay.RelayBatch(context.WithoutCancel(ctx)) > 0 {
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Backoff returns the delay before the next attempt once attempts have failed.
+func (relay *Relay) Backoff(attempts int) time.Duration {
+	delay := relay.BaseBackoff
+	for i := 1; i < attempts && delay < relay.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > relay.MaxBackoff {
+		delay = relay.MaxBackoff
+	}
+	return delay
+}
+
+// RelayBatch publishes one batch of messages and returns how many of them
+// were published. The batch is claimed in a short transaction of its own and
+// published outside of it, so no row locks are held across network calls.
+func (relay *Relay) RelayBatch(ctx context.Context) (published int) {
+	for _, message := range relay.claim(ctx) {
+		var evt event.Event
+		if err := json.Unmarshal([]byte(message.Payload), &evt); err != nil {
+			// a payload that cannot be decoded never will be
+			relay.OutboxRepository.MarkDead(ctx, relay.DB, message.Id, err.Error(), relay.Now().UTC())
+			continue
+		}
+		evt.Sequence = uint64(message.Id)
+
+		if err := relay.Publisher.Publish(ctx, evt); err != nil {
+			slog.Error("outbox: publish failed", "event_type", evt.Type, "event_id", evt.Id, "attempt", message.Attempts+1, "error", err)
+			relay.fail(ctx, message, err.Error())
+			continue
+		}
+
+		relay.OutboxRepository.MarkPublished(ctx, relay.DB, message.Id, relay.Now().UTC())
+		published++
+	}
+
+	return published
+}
+
+func (relay *Relay) claim(ctx context.Context) []domain.OutboxMessage {
+	tx := relay.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	now := relay.Now().UTC()
+	return relay.OutboxRepository.ClaimUnpublished(ctx, tx, relay.BatchSize, now, now.Add(relay.ClaimTimeout))
+}
+
+func (relay *Relay) fail(ctx context.Context, message domain.OutboxMessage, reason string) {
+	attempts := message.Attempts + 1
+	now := relay.Now().UTC()
+	if attempts >= relay.MaxAttempts {
+		slog.Error("outbox: giving up on message", "outbox_id", message.Id, "event_id", message.EventId, "attempts", attempts)
+		relay.OutboxRepository.MarkDead(ctx, relay.DB, message.Id, reason, now)
+		return
+	}
+	relay.OutboxRepository.MarkFailed(ctx, relay.DB, message.Id, reason, now.Add(relay.Backoff(attempts)))
+}
@@ -0,0 +1,177 @@
+package replica
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+	"todo-app-api/helper"
+
+	"gorm.io/gorm"
+)
+
+const maxPins = 1024
+
+type replicaDB struct {
+	db      *gorm.DB
+	healthy atomic.Bool
+}
+
+// Router sends reads that do not need to see uncommitted data to healthy read
+// replicas in turn and everything else to the primary.
+type Router struct {
+	Primary *gorm.DB
+	// ReadYourWrites is how long a client keeps reading from the primary
+	// after it wrote, hiding replication lag from it. Zero disables pinning.
+	ReadYourWrites time.Duration
+	HealthInterval time.Duration
+	HealthTimeout  time.Duration
+	replicas       []*replicaDB
+	next           atomic.Uint64
+	mutex          sync.Mutex
+	pins           map[string]time.Time
+}
+
+// NewRouter routes between primary and replicas. Replicas only receive reads
+// once a health check has passed, see Run and CheckHealth.
+func NewRouter(primary *gorm.DB, replicas []*gorm.DB) *Router {
+	router := &Router{
+		Primary:        primary,
+		HealthInterval: 10 * time.Second,
+		HealthTimeout:  2 * time.Second,
+		pins:           map[string]time.Time{},
+	}
+	for _, db := range replicas {
+		router.replicas = append(router.replicas, &replicaDB{db: db})
+	}
+	return router
+}
+
+// Reader returns the connection to read from for the client in ctx.
+func (router *Router) Reader(ctx context.Context) *gorm.DB {
+	if len(router.replicas) == 0 || router.pinned(ctx) {
+		return router.Primary
+	}
+
+	start := router.next.Add(1)
+	for i := range router.replicas {
+		replica := router.replicas[(int(start)+i)%len(router.replicas)]
+		if replica.healthy.Load() {
+			return replica.db
+		}
+	}
+	return router.Primary
+}
+
+// Wrote pins the client in ctx to the primary for the read-your-writes window.
+// Call it once the write has committed, so the window covers the time the
+// replicas need to catch up with durable data. At most maxPins clients are
+// pinned; beyond that the expired pins and then the oldest one make room.
+func (router *Router) Wrote(ctx context.Context) {
+	client := helper.ClientFromContext(ctx)
+	if router.ReadYourWrites <= 0 || client == "" {
+		return
+	}
+
+	router.mutex.Lock()
+	defer router.mutex.Unlock()
+
+	now := time.Now()
+	if _, ok := router.pins[client]; !ok && len(router.pins) >= maxPins {
+		oldest := ""
+		for key, until := range router.pins {
+			if now.After(until) {
+				delete(router.pins, key)
+			} else if oldest == "" || until.Before(router.pins[oldest]) {
+				oldest = key
+			}
+		}
+		if len(router.pins) >= maxPins {
+			delete(router.pins, oldest)
+		}
+	}
+	router.pins[client] = now.Add(router.ReadYourWrites)
+}
+
+func (router *Router) pinned(ctx context.Context) bool {
+	client := helper.ClientFromContext(ctx)
+	if router.ReadYourWrites <= 0 || client == "" {
+		return false
+	}
+
+	router.mutex.Lock()
+	defer router.mutex.Unlock()
+
+	until, ok := router.pins[client]
+	return ok && time.Now().Before(until)
+}
+
+// CheckHealth pings every replica and takes the failing ones out of rotation
+// until they answer again.
+func (router *Router) CheckHealth(ctx context.Context) {
+	for i, replica := range router.replicas {
+		healthy := ping(ctx, replica.db, router.HealthTimeout) == nil
+		if replica.healthy.Swap(healthy) != healthy {
+			slog.Warn("replica: health changed", "replica", i, "healthy", healthy)
+		}
+	}
+}
+
+// Healthy reports how many replicas are currently in rotation.
+func (router *Router) Healthy() int {
+	healthy := 0
+	for _, replica := range router.replicas {
+		if replica.healthy.Load() {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+func ping(ctx context.Context, db *gorm.DB, timeout time.Duration) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
+// Run checks replica health immediately and then every HealthInterval until
+// ctx is cancelled.
+func (router *Router) Run(ctx context.Context) {
+	if len(router.replicas) == 0 {
+		return
+	}
+
+	router.CheckHealth(ctx)
+
+	ticker := time.NewTicker(router.HealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			router.CheckHealth(ctx)
+		}
+	}
+}
+
+// Close closes the replica connection pools; the primary is left alone.
+func (router *Router) Close() error {
+	for _, replica := range router.replicas {
+		sqlDB, err := replica.db.DB()
+		if err != nil {
+			return err
+		}
+		if err := sqlDB.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
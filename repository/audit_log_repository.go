@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"todo-app-api/models/domain"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogRepository is append-only: entries are never updated or deleted.
+type AuditLogRepository interface {
+	// Save fails when another entry already follows auditLog.PrevHash.
+	Save(ctx context.Context, tx *gorm.DB, auditLog domain.AuditLog) (domain.AuditLog, error)
+	FindLast(ctx context.Context, tx *gorm.DB) (domain.AuditLog, error)
+	FindAll(ctx context.Context, tx *gorm.DB, filter domain.AuditLogFilter) ([]domain.AuditLog, int64)
+	FindInBatches(ctx context.Context, tx *gorm.DB, batchSize int, fn func(auditLogs []domain.AuditLog) bool)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"todo-app-api/models/domain"
+
+	"gorm.io/gorm"
+)
+
+type AuditLogRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &AuditLogRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *AuditLogRepositoryImpl) Save(ctx context.Context, tx *gorm.DB, auditLog domain.AuditLog) (domain.AuditLog, error) {
+	result := tx.WithContext(ctx).Create(&auditLog)
+	return auditLog, result.Error
+}
+
+func (repository *AuditLogRepositoryImpl) FindLast(ctx context.Context, tx *gorm.DB) (domain.AuditLog, error) {
+	var auditLog domain.AuditLog
+	result := tx.WithContext(ctx).Order("id DESC").First(&auditLog)
+	return auditLog, result.Error
+}
+
+func (repository *AuditLogRepositoryImpl) FindAll(ctx context.Context, tx *gorm.DB, filter domain.AuditLogFilter) ([]domain.AuditLog, int64) {
+	query := tx.WithContext(ctx).Model(&domain.AuditLog{})
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Resource != "" {
+		query = query.Where("resource = ?", filter.Resource)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at <= ?", filter.To)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var auditLogs []domain.AuditLog
+	query.Order("id DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&auditLogs)
+	return auditLogs, total
+}
+
+func (repository *AuditLogRepositoryImpl) FindInBatches(ctx context.Context, tx *gorm.DB, batchSize int, fn func(auditLogs []domain.AuditLog) bool) {
+	lastId := 0
+	for {
+		var auditLogs []domain.AuditLog
+		tx.WithContext(ctx).Where("id > ?", lastId).Order("id ASC").Limit(batchSize).Find(&auditLogs)
+		if len(auditLogs) == 0 || !fn(auditLogs) {
+			return
+		}
+		lastId = auditLogs[len(auditLogs)-1].Id
+	}
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"todo-app-api/models/domain"
+
+	"gorm.io/gorm"
+)
+
+type CalendarTokenRepository interface {
+	Save(ctx context.Context, tx *gorm.DB, token domain.CalendarToken) domain.CalendarToken
+	Delete(ctx context.Context, tx *gorm.DB, token domain.CalendarToken)
+	FindById(ctx context.Context, tx *gorm.DB, tokenId int) (domain.CalendarToken, error)
+	FindByHash(ctx context.Context, tx *gorm.DB, tokenHash string) (domain.CalendarToken, error)
+}
+
+type CalendarObjectRepository interface {
+	// Save reports an error rather than ignoring it, as a taken UID means
+	// another upload of the same VTODO got there first.
+	Save(ctx context.Context, tx *gorm.DB, object domain.CalendarObject) (domain.CalendarObject, error)
+	Delete(ctx context.Context, tx *gorm.DB, object domain.CalendarObject)
+	FindByUid(ctx context.Context, tx *gorm.DB, uid string) (domain.CalendarObject, error)
+	FindByTodoIds(ctx context.Context, tx *gorm.DB, todoIds []int) []domain.CalendarObject
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"todo-app-api/models/domain"
+
+	"gorm.io/gorm"
+)
+
+type CalendarTokenRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewCalendarTokenRepository(db *gorm.DB) CalendarTokenRepository {
+	return &CalendarTokenRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *CalendarTokenRepositoryImpl) Save(ctx context.Context, tx *gorm.DB, token domain.CalendarToken) domain.CalendarToken {
+	tx.WithContext(ctx).Create(&token)
+	return token
+}
+
+func (repository *CalendarTokenRepositoryImpl) Delete(ctx context.Context, tx *gorm.DB, token domain.CalendarToken) {
+	tx.WithContext(ctx).Delete(&token)
+}
+
+func (repository *CalendarTokenRepositoryImpl) FindById(ctx context.Context, tx *gorm.DB, tokenId int) (domain.CalendarToken, error) {
+	var token domain.CalendarToken
+	result := tx.WithContext(ctx).First(&token, tokenId)
+	return token, result.Error
+}
+
+func (repository *CalendarTokenRepositoryImpl) FindByHash(ctx context.Context, tx *gorm.DB, tokenHash string) (domain.CalendarToken, error) {
+	var token domain.CalendarToken
+	result := tx.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token)
+	return token, result.Error
+}
+
+type CalendarObjectRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewCalendarObjectRepository(db *gorm.DB) CalendarObjectRepository {
+	return &CalendarObjectRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *CalendarObjectRepositoryImpl) Save(ctx context.Context, tx *gorm.DB, object domain.CalendarObject) (domain.CalendarObject, error) {
+	err := tx.WithContext(ctx).Create(&object).Error
+	return object, err
+}
+
+func (repository *CalendarObjectRepositoryImpl) Delete(ctx context.Context, tx *gorm.DB, object domain.CalendarObject) {
+	tx.WithContext(ctx).Delete(&object)
+}
+
+func (repository *CalendarObjectRepositoryImpl) FindByUid(ctx context.Context, tx *gorm.DB, uid string) (domain.CalendarObject, error) {
+	var object domain.CalendarObject
+	result := tx.WithContext(ctx).Where("uid = ?", uid).First(&object)
+	return object, result.Error
+}
+
+func (repository *CalendarObjectRepositoryImpl) FindByTodoIds(ctx context.Context, tx *gorm.DB, todoIds []int) []domain.CalendarObject {
+	var objects []domain.CalendarObject
+	tx.WithContext(ctx).Where("todo_id IN ?", todoIds).Find(&objects)
+	return objects
+}
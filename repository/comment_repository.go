@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"todo-app-api/models/domain"
+
+	"gorm.io/gorm"
+)
+
+type CommentRepository interface {
+	Save(ctx context.Context, tx *gorm.DB, comment domain.Comment) domain.Comment
+	Update(ctx context.Context, tx *gorm.DB, comment domain.Comment) domain.Comment
+	FindById(ctx context.Context, tx *gorm.DB, commentId int) (domain.Comment, error)
+	// FindThreads returns a page of the comments of a todo that are not
+	// replies, with their total count.
+	FindThreads(ctx context.Context, tx *gorm.DB, filter domain.CommentFilter) ([]domain.Comment, int64)
+	FindReplies(ctx context.Context, tx *gorm.DB, todoId int) []domain.Comment
+	// CountByTodoIds counts the comments that are not deleted. Todos without
+	// any are left out.
+	CountByTodoIds(ctx context.Context, tx *gorm.DB, todoIds []int) map[int]int
+	// DeleteByTodoId removes the comments of a todo with their edits and
+	// reactions.
+	DeleteByTodoId(ctx context.Context, tx *gorm.DB, todoId int)
+}
+
+type CommentEditRepository interface {
+	Save(ctx context.Context, tx *gorm.DB, edit domain.CommentEdit) domain.CommentEdit
+	FindByCommentId(ctx context.Context, tx *gorm.DB, commentId int) []domain.CommentEdit
+	DeleteByCommentId(ctx context.Context, tx *gorm.DB, commentId int)
+}
+
+type CommentReactionRepository interface {
+	// Save ignores a reaction the actor already gave.
+	Save(ctx context.Context, tx *gorm.DB, reaction domain.CommentReaction)
+	Delete(ctx context.Context, tx *gorm.DB, reaction domain.CommentReaction)
+	FindByCommentIds(ctx context.Context, tx *gorm.DB, commentIds []int) []domain.CommentReaction
+	DeleteByCommentId(ctx context.Context, tx *gorm.DB, commentId int)
+}
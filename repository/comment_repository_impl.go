@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"todo-app-api/models/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CommentRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &CommentRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *CommentRepositoryImpl) Save(ctx context.Context, tx *gorm.DB, comment domain.Comment) domain.Comment {
+	tx.WithContext(ctx).Create(&comment)
+	return comment
+}
+
+func (repository *CommentRepositoryImpl) Update(ctx context.Context, tx *gorm.DB, comment domain.Comment) domain.Comment {
+	tx.WithContext(ctx).Save(&comment)
+	return comment
+}
+
+func (repository *CommentRepositoryImpl) FindById(ctx context.Context, tx *gorm.DB, commentId int) (domain.Comment, error) {
+	var comment domain.Comment
+	result := tx.WithContext(ctx).First(&comment, commentId)
+	return comment, result.Error
+}
+
+func (repository *CommentRepositoryImpl) FindThreads(ctx context.Context, tx *gorm.DB, filter domain.CommentFilter) ([]domain.Comment, int64) {
+	query := tx.WithContext(ctx).Model(&domain.Comment{}).Where("todo_id = ? AND parent_id IS NULL", filter.TodoId)
+
+	var total int64
+	query.Count(&total)
+
+	var comments []domain.Comment
+	query.Order("id ASC").Offset(filter.Offset).Limit(filter.Limit).Find(&comments)
+	return comments, total
+}
+
+func (repository *CommentRepositoryImpl) FindReplies(ctx context.Context, tx *gorm.DB, todoId int) []domain.Comment {
+	var comments []domain.Comment
+	tx.WithContext(ctx).Where("todo_id = ? AND parent_id IS NOT NULL", todoId).Order("id ASC").Find(&comments)
+	return comments
+}
+
+func (repository *CommentRepositoryImpl) CountByTodoIds(ctx context.Context, tx *gorm.DB, todoIds []int) map[int]int {
+	var rows []struct {
+		TodoId int
+		Count  int
+	}
+	tx.WithContext(ctx).Model(&domain.Comment{}).
+		Select("todo_id, COUNT(*) AS count").
+		Where("todo_id IN ? AND deleted_at IS NULL", todoIds).
+		Group("todo_id").
+		Scan(&rows)
+
+	counts := map[int]int{}
+	for _, row := range rows {
+		counts[row.TodoId] = row.Count
+	}
+	return counts
+}
+
+func (repository *CommentRepositoryImpl) DeleteByTodoId(ctx context.Context, tx *gorm.DB, todoId int) {
+	commentIds := tx.Session(&gorm.Session{NewDB: true}).Model(&domain.Comment{}).Select("id").Where("todo_id = ?", todoId)
+	tx.WithContext(ctx).Where("comment_id IN (?)", commentIds).Delete(&domain.CommentEdit{})
+	tx.WithContext(ctx).Where("comment_id IN (?)", commentIds).Delete(&domain.CommentReaction{})
+	tx.WithContext(ctx).Where("todo_id = ?", todoId).Delete(&domain.Comment{})
+}
+
+type CommentEditRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewCommentEditRepository(db *gorm.DB) CommentEditRepository {
+	return &CommentEditRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *CommentEditRepositoryImpl) Save(ctx context.Context, tx *gorm.DB, edit domain.CommentEdit) domain.CommentEdit {
+	tx.WithContext(ctx).Create(&edit)
+	return edit
+}
+
+func (repository *CommentEditRepositoryImpl) FindByCommentId(ctx context.Context, tx *gorm.DB, commentId int) []domain.CommentEdit {
+	var edits []domain.CommentEdit
+	tx.WithContext(ctx).Where("comment_id = ?", commentId).Order("id ASC").Find(&edits)
+	return edits
+}
+
+func (repository *CommentEditRepositoryImpl) DeleteByCommentId(ctx context.Context, tx *gorm.DB, commentId int) {
+	tx.WithContext(ctx).Where("comment_id = ?", commentId).Delete(&domain.CommentEdit{})
+}
+
+type CommentReactionRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewCommentReactionRepository(db *gorm.DB) CommentReactionRepository {
+	return &CommentReactionRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *CommentReactionRepositoryImpl) Save(ctx context.Context, tx *gorm.DB, reaction domain.CommentReaction) {
+	tx.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&reaction)
+}
+
+func (repository *CommentReactionRepositoryImpl) Delete(ctx context.Context, tx *gorm.DB, reaction domain.CommentReaction) {
+	tx.WithContext(ctx).
+		Where("comment_id = ? AND actor = ? AND emoji = ?", reaction.CommentId, reaction.Actor, reaction.Emoji).
+		Delete(&domain.CommentReaction{})
+}
+
+func (repository *CommentReactionRepositoryImpl) FindByCommentIds(ctx context.Context, tx *gorm.DB, commentIds []int) []domain.CommentReaction {
+	var reactions []domain.CommentReaction
+	tx.WithContext(ctx).Where("comment_id IN ?", commentIds).Order("id ASC").Find(&reactions)
+	return reactions
+}
+
+func (repository *CommentReactionRepositoryImpl) DeleteByCommentId(ctx context.Context, tx *gorm.DB, commentId int) {
+	tx.WithContext(ctx).Where("comment_id = ?", commentId).Delete(&domain.CommentReaction{})
+}
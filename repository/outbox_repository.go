@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"todo-app-api/models/domain"
+
+	"gorm.io/gorm"
+)
+
+type OutboxRepository interface {
+	Save(ctx context.Context, tx *gorm.DB, message domain.OutboxMessage) domain.OutboxMessage
+	ClaimUnpublished(ctx context.Context, tx *gorm.DB, limit int, now time.Time, claimedUntil time.Time) []domain.OutboxMessage
+	MarkPublished(ctx context.Context, tx *gorm.DB, messageId int, publishedAt time.Time)
+	MarkFailed(ctx context.Context, tx *gorm.DB, messageId int, reason string, nextAttemptAt time.Time)
+	MarkDead(ctx context.Context, tx *gorm.DB, messageId int, reason string, failedAt time.Time)
+	FindAfter(ctx context.Context, tx *gorm.DB, afterId int, limit int) []domain.OutboxMessage
+	FindByIds(ctx context.Context, tx *gorm.DB, messageIds []int) []domain.OutboxMessage
+	FindLatest(ctx context.Context, tx *gorm.DB, limit int) []domain.OutboxMessage
+}
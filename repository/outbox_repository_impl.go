@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"time"
+	"todo-app-api/models/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &OutboxRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *OutboxRepositoryImpl) Save(ctx context.Context, tx *gorm.DB, message domain.OutboxMessage) domain.OutboxMessage {
+	tx.WithContext(ctx).Create(&message)
+	return message
+}
+
+// ClaimUnpublished leases the oldest due message of each aggregate until
+// claimedUntil, so a later event is never handed out while an earlier one for
+// the same aggregate is still pending. Messages parked as failed no longer
+// hold back the ones after them. On Postgres the rows are selected with
+// FOR UPDATE SKIP LOCKED so concurrent relays split the work; SQLite
+// serializes writers, so plain polling gives the same guarantee there. The
+// lease lets the caller commit right away and publish outside the
+// transaction; a relay that dies mid-publish leaves the lease to expire.
+func (repository *OutboxRepositoryImpl) ClaimUnpublished(ctx context.Context, tx *gorm.DB, limit int, now time.Time, claimedUntil time.Time) []domain.OutboxMessage {
+	var messages []domain.OutboxMessage
+	query := tx.WithContext(ctx).
+		Where("published_at IS NULL AND failed_at IS NULL").
+		Where("(next_attempt_at IS NULL OR next_attempt_at <= ?)", now).
+		Where("(claimed_until IS NULL OR claimed_until <= ?)", now).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM outbox_messages earlier
+			WHERE earlier.aggregate_type = outbox_messages.aggregate_type
+			AND earlier.aggregate_id = outbox_messages.aggregate_id
+			AND earlier.published_at IS NULL
+			AND earlier.failed_at IS NULL
+			AND earlier.id < outbox_messages.id)`).
+		Order("id ASC").
+		Limit(limit)
+
+	if tx.Dialector.Name() == "postgres" {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+	}
+
+	query.Find(&messages)
+	if len(messages) == 0 {
+		return messages
+	}
+
+	messageIds := make([]int, len(messages))
+	for i := range messages {
+		messageIds[i] = messages[i].Id
+		messages[i].ClaimedUntil = &claimedUntil
+	}
+	tx.WithContext(ctx).Model(&domain.OutboxMessage{}).
+		Where("id IN ?", messageIds).
+		Update("claimed_until", claimedUntil)
+	return messages
+}
+
+func (repository *OutboxRepositoryImpl) MarkPublished(ctx context.Context, tx *gorm.DB, messageId int, publishedAt time.Time) {
+	tx.WithContext(ctx).Model(&domain.OutboxMessage{}).
+		Where("id = ?", messageId).
+		Updates(map[string]interface{}{
+			"published_at":  publishedAt,
+			"attempts":      gorm.Expr("attempts + 1"),
+			"last_error":    "",
+			"claimed_until": nil,
+		})
+}
+
+// MarkFailed releases the message for another attempt at nextAttemptAt.
+func (repository *OutboxRepositoryImpl) MarkFailed(ctx context.Context, tx *gorm.DB, messageId int, reason string, nextAttemptAt time.Time) {
+	tx.WithContext(ctx).Model(&domain.OutboxMessage{}).
+		Where("id = ?", messageId).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"last_error":      reason,
+			"next_attempt_at": nextAttemptAt,
+			"claimed_until":   nil,
+		})
+}
+
+// MarkDead parks the message for good; it is kept for inspection but no
+// longer retried and no longer blocks later events of its aggregate.
+func (repository *OutboxRepositoryImpl) MarkDead(ctx context.Context, tx *gorm.DB, messageId int, reason string, failedAt time.Time) {
+	tx.WithContext(ctx).Model(&domain.OutboxMessage{}).
+		Where("id = ?", messageId).
+		Updates(map[string]interface{}{
+			"attempts":      gorm.Expr("attempts + 1"),
+			"last_error":    reason,
+			"failed_at":     failedAt,
+			"claimed_until": nil,
+		})
+}
+
+// FindAfter returns up to limit messages with an id above afterId, whatever
+// their publishing state, oldest first.
+func (repository *OutboxRepositoryImpl) FindAfter(ctx context.Context, tx *gorm.DB, afterId int, limit int) []domain.OutboxMessage {
+	var messages []domain.OutboxMessage
+	tx.WithContext(ctx).Where("id > ?", afterId).Order("id ASC").Limit(limit).Find(&messages)
+	return messages
+}
+
+func (repository *OutboxRepositoryImpl) FindByIds(ctx context.Context, tx *gorm.DB, messageIds []int) []domain.OutboxMessage {
+	var messages []domain.OutboxMessage
+	tx.WithContext(ctx).Where("id IN ?", messageIds).Order("id ASC").Find(&messages)
+	return messages
+}
+
+// FindLatest returns the limit most recent messages, oldest first.
+func (repository *OutboxRepositoryImpl) FindLatest(ctx context.Context, tx *gorm.DB, limit int) []domain.OutboxMessage {
+	var messages []domain.OutboxMessage
+	tx.WithContext(ctx).Order("id DESC").Limit(limit).Find(&messages)
+	slices.Reverse(messages)
+	return messages
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"todo-app-api/models/domain"
+
+	"gorm.io/gorm"
+)
+
+type TodoReferenceRepository interface {
+	// Replace swaps the references of a todo for references, removing them
+	// all when it is empty.
+	Replace(ctx context.Context, tx *gorm.DB, todoId int, references []domain.TodoReference)
+	FindByTodoId(ctx context.Context, tx *gorm.DB, todoId int) []domain.TodoReference
+}
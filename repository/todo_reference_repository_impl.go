@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"todo-app-api/models/domain"
+
+	"gorm.io/gorm"
+)
+
+type TodoReferenceRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewTodoReferenceRepository(db *gorm.DB) TodoReferenceRepository {
+	return &TodoReferenceRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *TodoReferenceRepositoryImpl) Replace(ctx context.Context, tx *gorm.DB, todoId int, references []domain.TodoReference) {
+	tx.WithContext(ctx).Where("todo_id = ?", todoId).Delete(&domain.TodoReference{})
+	if len(references) == 0 {
+		return
+	}
+	for i := range references {
+		references[i].TodoId = todoId
+	}
+	tx.WithContext(ctx).Create(&references)
+}
+
+func (repository *TodoReferenceRepositoryImpl) FindByTodoId(ctx context.Context, tx *gorm.DB, todoId int) []domain.TodoReference {
+	var references []domain.TodoReference
+	tx.WithContext(ctx).Where("todo_id = ?", todoId).Order("id ASC").Find(&references)
+	return references
+}
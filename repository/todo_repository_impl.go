@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"strings"
 	"todo-app-api/models/domain"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TodoRepositoryImpl struct {
@@ -42,8 +44,74 @@ func (repository *TodoRepositoryImpl) FindById(ctx context.Context, tx *gorm.DB,
 	return todo, result.Error
 }
 
-func (repository *TodoRepositoryImpl) FindAll(ctx context.Context, tx *gorm.DB) []domain.Todo {
+// FindByIdForUpdate locks the row with FOR UPDATE; SQLite has no row locks
+// but serializes writers, which gives the same guarantee there.
+func (repository *TodoRepositoryImpl) FindByIdForUpdate(ctx context.Context, tx *gorm.DB, todoId int) (domain.Todo, error) {
+	var todo domain.Todo
+	query := tx.WithContext(ctx)
+	if tx.Dialector.Name() != "sqlite" {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	err := query.First(&todo, todoId).Error
+	return todo, err
+}
+
+func (repository *TodoRepositoryImpl) FindAll(ctx context.Context, tx *gorm.DB, filter domain.TodoFilter) []domain.Todo {
+	var todos []domain.Todo
+	filterTodos(tx.WithContext(ctx), filter).Order("id ASC").Find(&todos)
+	return todos
+}
+
+func (repository *TodoRepositoryImpl) FindEach(ctx context.Context, tx *gorm.DB, filter domain.TodoFilter, fn func(todo domain.Todo) bool) error {
+	query := filterTodos(tx.WithContext(ctx).Model(&domain.Todo{}), filter).Order("id ASC")
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var todo domain.Todo
+		if err := query.ScanRows(rows, &todo); err != nil {
+			return err
+		}
+		if !fn(todo) {
+			return nil
+		}
+	}
+	return rows.Err()
+}
+
+func filterTodos(query *gorm.DB, filter domain.TodoFilter) *gorm.DB {
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Mention != "" {
+		query = query.Where("id IN (?)", referencedTodoIds(query, domain.TodoReferenceMention, strings.ToLower(filter.Mention)))
+	}
+	if filter.Link != "" {
+		query = query.Where("id IN (?)", referencedTodoIds(query, domain.TodoReferenceLink, filter.Link))
+	}
+	return query
+}
+
+func referencedTodoIds(query *gorm.DB, kind string, value string) *gorm.DB {
+	return query.Session(&gorm.Session{NewDB: true}).Model(&domain.TodoReference{}).Select("todo_id").Where("kind = ? AND value = ?", kind, value)
+}
+
+func (repository *TodoRepositoryImpl) FindByIds(ctx context.Context, tx *gorm.DB, todoIds []int) []domain.Todo {
+	var todos []domain.Todo
+	tx.WithContext(ctx).Where("id IN ?", todoIds).Order("id ASC").Find(&todos)
+	return todos
+}
+
+func (repository *TodoRepositoryImpl) FindByTitles(ctx context.Context, tx *gorm.DB, titles []string) []domain.Todo {
+	lowered := make([]string, len(titles))
+	for i, title := range titles {
+		lowered[i] = strings.ToLower(title)
+	}
+
 	var todos []domain.Todo
-	tx.WithContext(ctx).Order("id ASC").Find(&todos)
+	tx.WithContext(ctx).Where("LOWER(title) IN ?", lowered).Order("id ASC").Find(&todos)
 	return todos
 }
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"todo-app-api/models/domain"
+
+	"gorm.io/gorm"
+)
+
+type TodoRevisionRepository interface {
+	Save(ctx context.Context, tx *gorm.DB, revision domain.TodoRevision) (domain.TodoRevision, error)
+	FindByTodoId(ctx context.Context, tx *gorm.DB, todoId int) []domain.TodoRevision
+	FindByTodoIds(ctx context.Context, tx *gorm.DB, todoIds []int) []domain.TodoRevision
+	FindByRevision(ctx context.Context, tx *gorm.DB, todoId int, revision int) (domain.TodoRevision, error)
+	LastRevision(ctx context.Context, tx *gorm.DB, todoId int) int
+	LastRevisions(ctx context.Context, tx *gorm.DB, todoIds []int) map[int]int
+}
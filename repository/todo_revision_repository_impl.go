@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"todo-app-api/models/domain"
+
+	"gorm.io/gorm"
+)
+
+type TodoRevisionRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewTodoRevisionRepository(db *gorm.DB) TodoRevisionRepository {
+	return &TodoRevisionRepositoryImpl{
+		DB: db,
+	}
+}
+
+// Save reports a revision number that is already taken as
+// gorm.ErrDuplicatedKey, whatever the database.
+func (repository *TodoRevisionRepositoryImpl) Save(ctx context.Context, tx *gorm.DB, revision domain.TodoRevision) (domain.TodoRevision, error) {
+	err := tx.WithContext(ctx).Create(&revision).Error
+	if translator, ok := tx.Dialector.(gorm.ErrorTranslator); ok && err != nil {
+		err = translator.Translate(err)
+	}
+	return revision, err
+}
+
+func (repository *TodoRevisionRepositoryImpl) FindByTodoId(ctx context.Context, tx *gorm.DB, todoId int) []domain.TodoRevision {
+	var revisions []domain.TodoRevision
+	tx.WithContext(ctx).Where("todo_id = ?", todoId).Order("revision ASC").Find(&revisions)
+	return revisions
+}
+
+func (repository *TodoRevisionRepositoryImpl) FindByTodoIds(ctx context.Context, tx *gorm.DB, todoIds []int) []domain.TodoRevision {
+	var revisions []domain.TodoRevision
+	tx.WithContext(ctx).Where("todo_id IN ?", todoIds).Order("todo_id ASC, revision ASC").Find(&revisions)
+	return revisions
+}
+
+func (repository *TodoRevisionRepositoryImpl) FindByRevision(ctx context.Context, tx *gorm.DB, todoId int, revision int) (domain.TodoRevision, error) {
+	var todoRevision domain.TodoRevision
+	result := tx.WithContext(ctx).Where("todo_id = ? AND revision = ?", todoId, revision).First(&todoRevision)
+	return todoRevision, result.Error
+}
+
+func (repository *TodoRevisionRepositoryImpl) LastRevision(ctx context.Context, tx *gorm.DB, todoId int) int {
+	var last int
+	tx.WithContext(ctx).Model(&domain.TodoRevision{}).
+		Where("todo_id = ?", todoId).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&last)
+	return last
+}
+
+func (repository *TodoRevisionRepositoryImpl) LastRevisions(ctx context.Context, tx *gorm.DB, todoIds []int) map[int]int {
+	var rows []struct {
+		TodoId   int
+		Revision int
+	}
+	tx.WithContext(ctx).Model(&domain.TodoRevision{}).
+		Where("todo_id IN ?", todoIds).
+		Group("todo_id").
+		Select("todo_id, MAX(revision) AS revision").
+		Scan(&rows)
+
+	last := map[int]int{}
+	for _, row := range rows {
+		last[row.TodoId] = row.Revision
+	}
+	return last
+}
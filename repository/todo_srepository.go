@@ -12,5 +12,14 @@ type TodoRepository interface {
 	Update(ctx context.Context, tx *gorm.DB, todo domain.Todo) domain.Todo
 	Delete(ctx context.Context, tx *gorm.DB, todo domain.Todo)
 	FindById(ctx context.Context, tx *gorm.DB, todoId int) (domain.Todo, error)
-	FindAll(ctx context.Context, tx *gorm.DB) []domain.Todo
+	// FindByIdForUpdate is FindById holding a lock on the row until tx ends,
+	// so the todo cannot be changed or deleted under the caller.
+	FindByIdForUpdate(ctx context.Context, tx *gorm.DB, todoId int) (domain.Todo, error)
+	FindAll(ctx context.Context, tx *gorm.DB, filter domain.TodoFilter) []domain.Todo
+	// FindEach walks the todos matching filter through a cursor, one row at
+	// a time, until fn returns false.
+	FindEach(ctx context.Context, tx *gorm.DB, filter domain.TodoFilter, fn func(todo domain.Todo) bool) error
+	FindByIds(ctx context.Context, tx *gorm.DB, todoIds []int) []domain.Todo
+	// FindByTitles matches titles regardless of case.
+	FindByTitles(ctx context.Context, tx *gorm.DB, titles []string) []domain.Todo
 }
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"todo-app-api/models/domain"
+
+	"gorm.io/gorm"
+)
+
+type WebhookSubscriptionRepository interface {
+	Save(ctx context.Context, tx *gorm.DB, subscription domain.WebhookSubscription) domain.WebhookSubscription
+	Update(ctx context.Context, tx *gorm.DB, subscription domain.WebhookSubscription) domain.WebhookSubscription
+	Delete(ctx context.Context, tx *gorm.DB, subscription domain.WebhookSubscription)
+	FindById(ctx context.Context, tx *gorm.DB, subscriptionId int) (domain.WebhookSubscription, error)
+	FindAll(ctx context.Context, tx *gorm.DB) []domain.WebhookSubscription
+	FindActive(ctx context.Context, tx *gorm.DB) []domain.WebhookSubscription
+}
+
+type WebhookDeliveryRepository interface {
+	Save(ctx context.Context, tx *gorm.DB, delivery domain.WebhookDelivery) (domain.WebhookDelivery, error)
+	Update(ctx context.Context, tx *gorm.DB, delivery domain.WebhookDelivery) domain.WebhookDelivery
+	FindBySubscriptionId(ctx context.Context, tx *gorm.DB, subscriptionId int) []domain.WebhookDelivery
+	ClaimDue(ctx context.Context, tx *gorm.DB, now time.Time, claimedUntil time.Time, limit int) []domain.WebhookDelivery
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"todo-app-api/models/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookSubscriptionRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewWebhookSubscriptionRepository(db *gorm.DB) WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepositoryImpl{
+		DB: db,
+	}
+}
+
+func (repository *WebhookSubscriptionRepositoryImpl) Save(ctx context.Context, tx *gorm.DB, subscription domain.WebhookSubscription) domain.WebhookSubscription {
+	tx.WithContext(ctx).Create(&subscription)
+	return subscription
+}
+
+func (repository *WebhookSubscriptionRepositoryImpl) Update(ctx context.Context, tx *gorm.DB, subscription domain.WebhookSubscription) domain.WebhookSubscription {
+	tx.WithContext(ctx).Save(&subscription)
+	return subscription
+}
+
+func (repository *WebhookSubscriptionRepositoryImpl) Delete(ctx context.Context, tx *gorm.DB, subscription domain.WebhookSubscription) {
+	tx.WithContext(ctx).Delete(&subscription)
+}
+
+func (repository *WebhookSubscriptionRepositoryImpl) FindById(ctx context.Context, tx *gorm.DB, subscriptionId int) (domain.WebhookSubscription, error) {
+	var subscription domain.WebhookSubscription
+	result := tx.WithContext(ctx).First(&subscription, subscriptionId)
+	return subscription, result.Error
+}
+
+func (repository *WebhookSubscriptionRepositoryImpl) FindAll(ctx context.Context, tx *gorm.DB) []domain.WebhookSubscription {
+	var subscriptions []domain.WebhookSubscription
+	tx.WithContext(ctx).Order("id ASC").Find(&subscriptions)
+	return subscriptions
+}
+
+func (repository *WebhookSubscriptionRepositoryImpl) FindActive(ctx context.Context, tx *gorm.DB) []domain.WebhookSubscription {
+	var subscriptions []domain.WebhookSubscription
+	tx.WithContext(ctx).Where("active = ?", true).Order("id ASC").Find(&subscriptions)
+	return subscriptions
+}
+
+type WebhookDeliveryRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewWebhookDeliveryRepository(db *gorm.DB) WebhookDeliveryRepository {
+	return &WebhookDeliveryRepositoryImpl{
+		DB: db,
+	}
+}
+
+// Save skips a delivery of an event the subscription already has one for, so
+// an event the relay hands out again is not sent twice.
+func (repository *WebhookDeliveryRepositoryImpl) Save(ctx context.Context, tx *gorm.DB, delivery domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	err := tx.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&delivery).Error
+	return delivery, err
+}
+
+func (repository *WebhookDeliveryRepositoryImpl) Update(ctx context.Context, tx *gorm.DB, delivery domain.WebhookDelivery) domain.WebhookDelivery {
+	tx.WithContext(ctx).Save(&delivery)
+	return delivery
+}
+
+func (repository *WebhookDeliveryRepositoryImpl) FindBySubscriptionId(ctx context.Context, tx *gorm.DB, subscriptionId int) []domain.WebhookDelivery {
+	var deliveries []domain.WebhookDelivery
+	tx.WithContext(ctx).Where("subscription_id = ?", subscriptionId).Order("id DESC").Find(&deliveries)
+	return deliveries
+}
+
+// ClaimDue leases up to limit due deliveries until claimedUntil by moving
+// their next attempt there, so no other dispatcher sends them meanwhile; a
+// delivery whose dispatcher dies becomes due again once the lease has passed.
+// On Postgres the rows are selected with FOR UPDATE SKIP LOCKED so
+// concurrent dispatchers split the work. Everywhere a delivery only counts as
+// claimed if it was still due when the lease was written.
+func (repository *WebhookDeliveryRepositoryImpl) ClaimDue(ctx context.Context, tx *gorm.DB, now time.Time, claimedUntil time.Time, limit int) []domain.WebhookDelivery {
+	var due []domain.WebhookDelivery
+	query := tx.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", domain.WebhookDeliveryPending, now).
+		Order("id ASC").
+		Limit(limit)
+
+	if tx.Dialector.Name() == "postgres" {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+	}
+
+	query.Find(&due)
+
+	claimed := []domain.WebhookDelivery{}
+	for _, delivery := range due {
+		result := tx.WithContext(ctx).Model(&domain.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND next_attempt_at <= ?", delivery.Id, domain.WebhookDeliveryPending, now).
+			Update("next_attempt_at", claimedUntil)
+		if result.Error == nil && result.RowsAffected == 1 {
+			delivery.NextAttemptAt = claimedUntil
+			claimed = append(claimed, delivery)
+		}
+	}
+	return claimed
+}
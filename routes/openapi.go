@@ -0,0 +1,135 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"todo-app-api/middleware"
+	"todo-app-api/models/web"
+	"todo-app-api/openapi"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var lastEventIdHeader = openapi.Parameter{
+	Name:        "Last-Event-ID",
+	In:          "header",
+	Description: "Resume after this event id.",
+	Schema:      openapi.Schema{"type": "string"},
+}
+
+var assetName = openapi.Parameter{
+	Name:        "name",
+	In:          "path",
+	Description: "File name, such as redoc.standalone.js.",
+	Required:    true,
+	Schema:      openapi.Schema{"type": "string"},
+}
+
+type calendarQuery struct {
+	Token string `query:"token" validate:"required"`
+}
+
+type streamQuery struct {
+	LastEventId string `query:"last_event_id"`
+	TodoId      int    `query:"todo_id"`
+}
+
+// Operations documents every route registered by NewRouter and
+// NewMetricsRouter. TestOpenAPIMatchesRoutes fails when the two drift apart.
+var Operations = append(append(generalOperations, versioned(1, resourceOperations)...), versioned(2, resourceOperations)...)
+
+var generalOperations = []openapi.Operation{
+	{Method: fiber.MethodGet, Path: "/healthz", Tag: "operations", Summary: "Liveness probe", Response: web.HealthResponse{}},
+	{Method: fiber.MethodGet, Path: "/readyz", Tag: "operations", Summary: "Readiness probe with per-dependency checks", Response: web.HealthResponse{}, Errors: []int{http.StatusServiceUnavailable}},
+	{Method: fiber.MethodGet, Path: "/version", Tag: "operations", Summary: "Build version and uptime", Response: web.VersionResponse{}},
+	{Method: fiber.MethodGet, Path: "/stats/database", Tag: "operations", Summary: "Connection pool statistics", Response: web.DatabaseStatsResponse{}},
+	{Method: fiber.MethodGet, Path: "/metrics", Tag: "operations", Summary: "Prometheus metrics", Description: "Served on METRICS_ADMIN_PORT instead when that is set.", ContentType: "text/plain"},
+	{Method: fiber.MethodGet, Path: "/openapi.json", Tag: "operations", Summary: "This OpenAPI document", ContentType: fiber.MIMEApplicationJSON},
+	{Method: fiber.MethodGet, Path: "/docs", Tag: "operations", Summary: "API reference page", ContentType: fiber.MIMETextHTML},
+	{Method: fiber.MethodGet, Path: "/assets/scripts/:name", Tag: "operations", Summary: "Script of the reference page or the GraphiQL playground", PathParameters: []openapi.Parameter{assetName}, ContentType: fiber.MIMETextJavaScript, Errors: []int{http.StatusNotFound}},
+	{Method: fiber.MethodGet, Path: "/assets/styles/:name", Tag: "operations", Summary: "Stylesheet of the GraphiQL playground", PathParameters: []openapi.Parameter{assetName}, ContentType: "text/css", Errors: []int{http.StatusNotFound}},
+
+	{Method: fiber.MethodPost, Path: "/graphql", Tag: "graphql", Summary: "Run a GraphQL query or mutation", Description: "Answers 200 with the GraphQL result, errors included, once the request could be read.", Request: web.GraphQLRequest{}, ContentType: fiber.MIMEApplicationJSON, Errors: []int{http.StatusBadRequest}},
+	{Method: fiber.MethodGet, Path: "/graphql/ws", Tag: "graphql", Summary: "Run GraphQL subscriptions over a WebSocket", Description: "Speaks the graphql-transport-ws protocol.", Status: http.StatusSwitchingProtocols, Errors: []int{http.StatusUpgradeRequired}},
+	{Method: fiber.MethodGet, Path: "/graphql/playground", Tag: "graphql", Summary: "GraphiQL playground", Description: "Only served when APP_ENV is development.", ContentType: fiber.MIMETextHTML, Errors: []int{http.StatusNotFound}},
+
+	{Method: fiber.MethodPost, Path: "/calendar/tokens", Tag: "calendar", Summary: "Create a calendar feed token", Description: "The token belongs to the X-Actor user and is only shown once.", Response: web.CalendarTokenResponse{}, Errors: []int{http.StatusBadRequest}},
+	{Method: fiber.MethodDelete, Path: "/calendar/tokens/:tokenId", Tag: "calendar", Summary: "Revoke a calendar feed token", Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: fiber.MethodGet, Path: "/calendar/todos.ics", Tag: "calendar", Summary: "Subscribe to todos as an iCalendar feed", Description: "Renders every todo as a VTODO with a stable UID and a SEQUENCE that counts its changes.", Query: calendarQuery{}, ContentType: "text/calendar", Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: "/calendar/todos.ics", Tag: "calendar", Summary: "Create or update todos from VTODOs", Description: "Matches VTODOs to todos by UID. Unchanged VTODOs and those older than the stored SEQUENCE leave the todo alone, so uploading the same file twice changes nothing.", Query: calendarQuery{}, RequestContentType: "text/calendar", Response: web.CalendarUploadResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+}
+
+// resourceOperations are written in their v1 form without the version
+// prefix; versioned derives each version from them.
+var resourceOperations = []openapi.Operation{
+	{Method: fiber.MethodGet, Path: "/todos/stream", Tag: "todos", Summary: "Stream todo events as Server-Sent Events", Query: streamQuery{}, Headers: []openapi.Parameter{lastEventIdHeader}, ContentType: "text/event-stream", Errors: []int{http.StatusBadRequest}},
+	{Method: fiber.MethodGet, Path: "/todos/ws", Tag: "todos", Summary: "Stream todo events over a WebSocket", Query: streamQuery{}, Status: http.StatusSwitchingProtocols, Errors: []int{http.StatusUpgradeRequired}},
+	{Method: fiber.MethodGet, Path: "/todos/export", Tag: "todos", Summary: "Download todos as CSV, JSON or NDJSON", Description: "Streams the todos matching the list filters as an attachment. CSV is the default; format=json answers application/json and format=ndjson application/x-ndjson.", Query: web.TodoExportRequest{}, ContentType: "text/csv", Errors: []int{http.StatusBadRequest}},
+	{Method: fiber.MethodGet, Path: "/todos", Tag: "todos", Summary: "List todos", Description: "mention and link match the @mentions and links of the Markdown descriptions. render=html adds description_html, include=comment_count adds comment_count to v1 todos.", Query: web.TodoFilterRequest{}, Response: []web.TodoResponse{}, Errors: []int{http.StatusBadRequest}},
+	{Method: fiber.MethodGet, Path: "/todos/:todoId", Tag: "todos", Summary: "Get a todo", Description: "render=html adds description_html, the description rendered from Markdown and sanitized. include=comment_count adds comment_count to a v1 todo.", Query: web.TodoRenderRequest{}, Response: web.TodoResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: "/todos", Tag: "todos", Summary: "Create a todo", Request: web.TodoCreateRequest{}, Response: web.TodoResponse{}, Errors: []int{http.StatusBadRequest}},
+	{Method: fiber.MethodPost, Path: "/todos/import", Tag: "todos", Summary: "Import todos from a file", Description: "Reads CSV, JSON, NDJSON or the export of Todoist (CSV), Trello (board JSON) or Microsoft To Do (Graph tasks JSON) and reports every row. A dry run only validates; without partial a single invalid row stores nothing.", Request: web.TodoImportRequest{}, Response: web.TodoImportResponse{}, Errors: []int{http.StatusBadRequest}},
+	{Method: fiber.MethodPut, Path: "/todos/:todoId", Tag: "todos", Summary: "Update a todo", Request: web.TodoUpdateRequest{}, Response: web.TodoResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: "/todos/:todoId", Tag: "todos", Summary: "Delete a todo", Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: fiber.MethodPut, Path: "/todos/:todoId/tasks/:task", Tag: "todos", Summary: "Check or uncheck a task list item", Description: "Rewrites the Markdown source of the description. Tasks are numbered from 1 in the order they appear; the response includes description_html.", Request: web.TodoTaskRequest{}, Response: web.TodoResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: fiber.MethodGet, Path: "/todos/:todoId/history", Tag: "todos", Summary: "List the revisions of a todo", Response: []web.TodoRevisionResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: "/todos/:todoId/history/:revision/revert", Tag: "todos", Summary: "Revert a todo to a revision", Response: web.TodoResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: fiber.MethodGet, Path: "/todos/:todoId/comments", Tag: "comments", Summary: "List the comment threads of a todo", Description: "Pages through the comments that are not replies, oldest first, each with its replies nested below it. Deleted comments stay in place with an empty body.", Query: web.CommentFilterRequest{}, Response: commentPage{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: "/todos/:todoId/comments", Tag: "comments", Summary: "Comment on a todo", Description: "The comment is signed by the X-Actor user. parent_id replies to another comment of the same todo.", Request: web.CommentCreateRequest{}, Response: web.CommentResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: fiber.MethodPut, Path: "/todos/:todoId/comments/:commentId", Tag: "comments", Summary: "Edit a comment", Description: "Only the author edits a comment. The previous body is kept in its history.", Request: web.CommentUpdateRequest{}, Response: web.CommentResponse{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: "/todos/:todoId/comments/:commentId", Tag: "comments", Summary: "Delete a comment", Description: "Only the author deletes a comment. Its body, history and reactions are removed; its replies stay.", Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+	{Method: fiber.MethodGet, Path: "/todos/:todoId/comments/:commentId/history", Tag: "comments", Summary: "List the previous bodies of a comment", Response: []web.CommentEditResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: fiber.MethodPut, Path: "/todos/:todoId/comments/:commentId/reactions", Tag: "comments", Summary: "React to a comment", Description: "Adds the emoji reaction of the X-Actor user, or removes it when reacted is false.", Request: web.CommentReactionRequest{}, Response: web.CommentResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+
+	{Method: fiber.MethodGet, Path: "/audit", Tag: "audit", Summary: "Search the audit log", Query: web.AuditLogFilterRequest{}, Response: auditPage{}, Errors: []int{http.StatusBadRequest}},
+
+	{Method: fiber.MethodGet, Path: "/webhooks", Tag: "webhooks", Summary: "List webhook subscriptions", Response: []web.WebhookResponse{}},
+	{Method: fiber.MethodGet, Path: "/webhooks/:webhookId", Tag: "webhooks", Summary: "Get a webhook subscription", Response: web.WebhookResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: "/webhooks", Tag: "webhooks", Summary: "Subscribe a webhook", Request: web.WebhookCreateRequest{}, Response: web.WebhookResponse{}, Errors: []int{http.StatusBadRequest}},
+	{Method: fiber.MethodPut, Path: "/webhooks/:webhookId", Tag: "webhooks", Summary: "Update a webhook subscription", Request: web.WebhookUpdateRequest{}, Response: web.WebhookResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: "/webhooks/:webhookId", Tag: "webhooks", Summary: "Delete a webhook subscription", Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{Method: fiber.MethodGet, Path: "/webhooks/:webhookId/deliveries", Tag: "webhooks", Summary: "List the deliveries of a webhook", Response: []web.WebhookDeliveryResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+}
+
+// versioned prefixes operations with /v<version>, marks them deprecated when
+// a newer version exists and switches v2 to its envelope and todo shape.
+func versioned(version int, operations []openapi.Operation) []openapi.Operation {
+	var result []openapi.Operation
+	for _, operation := range operations {
+		operation.Path = fmt.Sprintf("/v%d%s", version, operation.Path)
+		operation.Deprecated = version < middleware.LatestAPIVersion
+		if version >= 2 {
+			operation.Envelope = web.WebResponseV2{}
+			switch operation.Response.(type) {
+			case web.TodoResponse:
+				operation.Response = web.TodoResponseV2{}
+			case []web.TodoResponse:
+				operation.Response = []web.TodoResponseV2{}
+			}
+		}
+		result = append(result, operation)
+	}
+	return result
+}
+
+// auditPage documents web.PageResponse as returned by GET /audit.
+type auditPage struct {
+	Items []web.AuditLogResponse `json:"items"`
+	Page  int                    `json:"page"`
+	Size  int                    `json:"size"`
+	Total int64                  `json:"total"`
+}
+
+// commentPage documents web.PageResponse as returned by GET
+// /todos/:todoId/comments.
+type commentPage struct {
+	Items []web.CommentResponse `json:"items"`
+	Page  int                   `json:"page"`
+	Size  int                   `json:"size"`
+	Total int64                 `json:"total"`
+}
+
+// OpenAPI returns the OpenAPI document of the API.
+func OpenAPI(version string) map[string]any {
+	return openapi.Build("Todo App API", version, Operations)
+}
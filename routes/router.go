@@ -6,12 +6,73 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func NewRouter(app *fiber.App, todoController controller.TodoController) {
-	todo := app.Group("/todos")
-
-	todo.Get("/", todoController.FindAll)
-	todo.Get("/:todoId", todoController.FindById)
-	todo.Post("/", todoController.Create)
-	todo.Put("/:todoId", todoController.Update)
-	todo.Delete("/:todoId", todoController.Delete)
+// Resources are served under /v1 and /v2. Requests without a version prefix
+// are rewritten by middleware.APIVersion.
+var Resources = []string{"/todos", "/audit", "/webhooks"}
+
+func NewRouter(app *fiber.App, todoController controller.TodoController, auditController controller.AuditController, webhookController controller.WebhookController, streamController controller.StreamController, healthController controller.HealthController, docsController controller.DocsController, graphqlController controller.GraphQLController, calendarController controller.CalendarController, commentController controller.CommentController) {
+	app.Get("/healthz", healthController.Liveness)
+	app.Get("/readyz", healthController.Readiness)
+	app.Get("/version", healthController.Version)
+	app.Get("/stats/database", healthController.DatabaseStats)
+
+	app.Get("/openapi.json", docsController.Spec)
+	app.Get("/docs", docsController.Reference)
+	app.Get("/assets/scripts/:name", docsController.Script)
+	app.Get("/assets/styles/:name", docsController.Style)
+
+	app.Post("/graphql", graphqlController.Query)
+	app.Get("/graphql/ws", graphqlController.WebSocket)
+	app.Get("/graphql/playground", graphqlController.Playground)
+
+	// calendar apps subscribe to a fixed URL, so the feed is not versioned
+	app.Post("/calendar/tokens", calendarController.CreateToken)
+	app.Delete("/calendar/tokens/:tokenId", calendarController.DeleteToken)
+	app.Get("/calendar/todos.ics", calendarController.Feed)
+	app.Post("/calendar/todos.ics", calendarController.Upload)
+
+	// both versions share the controllers; the envelope differs per version
+	for _, version := range []string{"/v1", "/v2"} {
+		api := app.Group(version)
+
+		todo := api.Group("/todos")
+
+		// registered before /:todoId so they are not taken for an id
+		todo.Get("/stream", streamController.Events)
+		todo.Get("/ws", streamController.WebSocket)
+		todo.Get("/export", todoController.Export)
+
+		todo.Get("/", todoController.FindAll)
+		todo.Get("/:todoId", todoController.FindById)
+		todo.Post("/", todoController.Create)
+		todo.Post("/import", todoController.Import)
+		todo.Put("/:todoId", todoController.Update)
+		todo.Delete("/:todoId", todoController.Delete)
+		todo.Put("/:todoId/tasks/:task", todoController.SetTask)
+		todo.Get("/:todoId/history", todoController.History)
+		todo.Post("/:todoId/history/:revision/revert", todoController.Revert)
+		todo.Get("/:todoId/comments", commentController.FindAll)
+		todo.Post("/:todoId/comments", commentController.Create)
+		todo.Put("/:todoId/comments/:commentId", commentController.Update)
+		todo.Delete("/:todoId/comments/:commentId", commentController.Delete)
+		todo.Get("/:todoId/comments/:commentId/history", commentController.History)
+		todo.Put("/:todoId/comments/:commentId/reactions", commentController.React)
+
+		api.Get("/audit", auditController.FindAll)
+
+		webhook := api.Group("/webhooks")
+
+		webhook.Get("/", webhookController.FindAll)
+		webhook.Get("/:webhookId", webhookController.FindById)
+		webhook.Post("/", webhookController.Create)
+		webhook.Put("/:webhookId", webhookController.Update)
+		webhook.Delete("/:webhookId", webhookController.Delete)
+		webhook.Get("/:webhookId/deliveries", webhookController.Deliveries)
+	}
+}
+
+// NewMetricsRouter serves metrics on app, which is either the API itself or
+// the separate admin server.
+func NewMetricsRouter(app *fiber.App, metricsHandler fiber.Handler) {
+	app.Get("/metrics", metricsHandler)
 }
@@ -0,0 +1,147 @@
+package rpc
+
+import (
+	"context"
+	todov1 "todo-app-api/gen/todo/v1"
+	"todo-app-api/models/web"
+	"todo-app-api/service"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// CommentServer serves todo.v1.CommentService on top of
+// service.CommentService, validating requests the way TodoServer does.
+type CommentServer struct {
+	todov1.UnimplementedCommentServiceServer
+
+	commentService service.CommentService
+	validate       *validator.Validate
+}
+
+func NewCommentServer(commentService service.CommentService, validate *validator.Validate) *CommentServer {
+	return &CommentServer{
+		commentService: commentService,
+		validate:       validate,
+	}
+}
+
+func (server *CommentServer) ListComments(ctx context.Context, request *todov1.ListCommentsRequest) (*todov1.ListCommentsResponse, error) {
+	filterRequest := web.CommentFilterRequest{
+		Page: int(request.GetPage()),
+		Size: int(request.GetSize()),
+	}
+	if err := server.validate.Struct(filterRequest); err != nil {
+		return nil, invalidArgument(filterRequest, err)
+	}
+
+	page := server.commentService.FindAll(ctx, int(request.GetTodoId()), filterRequest)
+	comments, _ := page.Items.([]web.CommentResponse)
+	return &todov1.ListCommentsResponse{
+		Items: toComments(comments),
+		Page:  int32(page.Page),
+		Size:  int32(page.Size),
+		Total: page.Total,
+	}, nil
+}
+
+func (server *CommentServer) CreateComment(ctx context.Context, request *todov1.CreateCommentRequest) (*todov1.Comment, error) {
+	createRequest := web.CommentCreateRequest{
+		TodoId: int(request.GetTodoId()),
+		Body:   request.GetBody(),
+	}
+	if request.ParentId != nil {
+		parentId := int(request.GetParentId())
+		createRequest.ParentId = &parentId
+	}
+	if err := server.validate.Struct(createRequest); err != nil {
+		return nil, invalidArgument(createRequest, err)
+	}
+
+	return toComment(server.commentService.Create(ctx, createRequest)), nil
+}
+
+func (server *CommentServer) UpdateComment(ctx context.Context, request *todov1.UpdateCommentRequest) (*todov1.Comment, error) {
+	updateRequest := web.CommentUpdateRequest{
+		Id:     int(request.GetId()),
+		TodoId: int(request.GetTodoId()),
+		Body:   request.GetBody(),
+	}
+	if err := server.validate.Struct(updateRequest); err != nil {
+		return nil, invalidArgument(updateRequest, err)
+	}
+
+	return toComment(server.commentService.Update(ctx, updateRequest)), nil
+}
+
+func (server *CommentServer) DeleteComment(ctx context.Context, request *todov1.DeleteCommentRequest) (*todov1.DeleteCommentResponse, error) {
+	server.commentService.Delete(ctx, int(request.GetTodoId()), int(request.GetId()))
+	return &todov1.DeleteCommentResponse{}, nil
+}
+
+func (server *CommentServer) GetCommentHistory(ctx context.Context, request *todov1.GetCommentHistoryRequest) (*todov1.GetCommentHistoryResponse, error) {
+	edits := server.commentService.History(ctx, int(request.GetTodoId()), int(request.GetId()))
+
+	response := &todov1.GetCommentHistoryResponse{}
+	for _, edit := range edits {
+		response.Edits = append(response.Edits, &todov1.CommentEdit{
+			Body:      edit.Body,
+			Actor:     edit.Actor,
+			CreatedAt: timestamppb.New(edit.CreatedAt),
+		})
+	}
+	return response, nil
+}
+
+func (server *CommentServer) ReactToComment(ctx context.Context, request *todov1.ReactToCommentRequest) (*todov1.Comment, error) {
+	reacted := request.GetReacted()
+	reactionRequest := web.CommentReactionRequest{
+		Id:      int(request.GetId()),
+		TodoId:  int(request.GetTodoId()),
+		Emoji:   request.GetEmoji(),
+		Reacted: &reacted,
+	}
+	if err := server.validate.Struct(reactionRequest); err != nil {
+		return nil, invalidArgument(reactionRequest, err)
+	}
+
+	return toComment(server.commentService.React(ctx, reactionRequest)), nil
+}
+
+func toComment(comment web.CommentResponse) *todov1.Comment {
+	message := &todov1.Comment{
+		Id:      int64(comment.Id),
+		TodoId:  int64(comment.TodoId),
+		Actor:   comment.Actor,
+		Body:    comment.Body,
+		Edited:  comment.Edited,
+		Deleted: comment.Deleted,
+		Replies: toComments(comment.Replies),
+	}
+	if comment.ParentId != nil {
+		parentId := int64(*comment.ParentId)
+		message.ParentId = &parentId
+	}
+	for _, reaction := range comment.Reactions {
+		message.Reactions = append(message.Reactions, &todov1.CommentReaction{
+			Emoji:  reaction.Emoji,
+			Count:  int32(reaction.Count),
+			Actors: reaction.Actors,
+		})
+	}
+	if !comment.CreatedAt.IsZero() {
+		message.CreatedAt = timestamppb.New(comment.CreatedAt)
+	}
+	if !comment.UpdatedAt.IsZero() {
+		message.UpdatedAt = timestamppb.New(comment.UpdatedAt)
+	}
+	return message
+}
+
+func toComments(comments []web.CommentResponse) []*todov1.Comment {
+	var messages []*todov1.Comment
+	for _, comment := range comments {
+		messages = append(messages, toComment(comment))
+	}
+	return messages
+}
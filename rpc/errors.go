@@ -0,0 +1,112 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"todo-app-api/exception"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// invalidArgument reports every field of request that failed validation in a
+// google.rpc.BadRequest detail, naming fields as the REST API does.
+func invalidArgument(request interface{}, err error) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	requestType := reflect.TypeOf(request)
+	badRequest := &errdetails.BadRequest{}
+	for _, fieldError := range validationErrors {
+		rule := fieldError.Tag()
+		if fieldError.Param() != "" {
+			rule += "=" + fieldError.Param()
+		}
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fieldName(requestType, fieldError),
+			Description: fmt.Sprintf("fails %q", rule),
+		})
+	}
+
+	withDetails, detailErr := status.New(codes.InvalidArgument, "invalid request").WithDetails(badRequest)
+	if detailErr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return withDetails.Err()
+}
+
+// fieldName is the json name of the field, which matches the protobuf field
+// name; fields hidden from JSON such as the id of an update are lowercased.
+func fieldName(requestType reflect.Type, fieldError validator.FieldError) string {
+	if field, ok := requestType.FieldByName(fieldError.StructField()); ok {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return strings.ToLower(fieldError.Field())
+}
+
+// toStatus maps a panic of the service layer to the status the REST error
+// handler would have answered with.
+func toStatus(recovered interface{}) error {
+	err, ok := recovered.(error)
+	if !ok {
+		return status.Error(codes.Internal, fmt.Sprint(recovered))
+	}
+
+	var notFound exception.NotFoundError
+	var conflict exception.ConflictError
+	var forbidden exception.ForbiddenError
+	var validationErrors validator.ValidationErrors
+	var fiberError *fiber.Error
+	switch {
+	case errors.As(err, &notFound):
+		return status.Error(codes.NotFound, notFound.Message)
+	case errors.As(err, &conflict):
+		return status.Error(codes.Aborted, conflict.Message)
+	case errors.As(err, &forbidden):
+		return status.Error(codes.PermissionDenied, forbidden.Message)
+	case errors.As(err, &validationErrors):
+		return status.Error(codes.InvalidArgument, validationErrors.Error())
+	case errors.As(err, &fiberError):
+		switch fiberError.Code {
+		case fiber.StatusBadRequest:
+			return status.Error(codes.InvalidArgument, fiberError.Message)
+		case fiber.StatusNotFound:
+			return status.Error(codes.NotFound, fiberError.Message)
+		}
+		return status.Error(codes.Internal, fiberError.Message)
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// httpStatus is the status code the REST API would have answered with, used
+// to keep audit entries of both APIs comparable.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.Aborted:
+		return http.StatusConflict
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
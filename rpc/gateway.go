@@ -0,0 +1,58 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/textproto"
+	todov1 "todo-app-api/gen/todo/v1"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// NewGateway serves the HTTP rules of todo.v1.TodoService and
+// todo.v1.CommentService by calling them over conn. Fields keep their
+// protobuf names and are sent even when empty; the X-Actor and X-Request-Id
+// headers travel as the metadata of the same name, and the request id comes
+// back as X-Request-Id.
+func NewGateway(ctx context.Context, conn *grpc.ClientConn) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   true,
+				EmitUnpopulated: true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		}),
+		runtime.WithIncomingHeaderMatcher(incomingHeader),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeader),
+	)
+
+	if err := todov1.RegisterTodoServiceHandler(ctx, mux, conn); err != nil {
+		return nil, err
+	}
+	if err := todov1.RegisterCommentServiceHandler(ctx, mux, conn); err != nil {
+		return nil, err
+	}
+	return mux, nil
+}
+
+func incomingHeader(key string) (string, bool) {
+	switch textproto.CanonicalMIMEHeaderKey(key) {
+	case "X-Actor":
+		return metadataActor, true
+	case "X-Request-Id":
+		return metadataRequestId, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+func outgoingHeader(key string) (string, bool) {
+	if key == metadataRequestId {
+		return "X-Request-Id", true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
@@ -0,0 +1,138 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"todo-app-api/helper"
+	"todo-app-api/models/domain"
+	"todo-app-api/service"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	metadataActor     = "x-actor"
+	metadataRequestId = "x-request-id"
+
+	maxRequestIdLength = 128
+)
+
+// mutatingMethods are audited like POST, PUT and DELETE requests are.
+var mutatingMethods = map[string]bool{
+	"CreateTodo": true,
+	"UpdateTodo": true,
+	"DeleteTodo": true,
+	"RevertTodo": true,
+	"SetTask":    true,
+
+	"CreateComment":  true,
+	"UpdateComment":  true,
+	"DeleteComment":  true,
+	"ReactToComment": true,
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func peerAddress(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host := p.Addr.String()
+		if index := strings.LastIndex(host, ":"); index > 0 {
+			host = host[:index]
+		}
+		return host
+	}
+	return ""
+}
+
+// Identity reads the x-actor and x-request-id metadata the way the Actor,
+// Client and RequestId middleware read their headers, and echoes the request
+// id back in the response header.
+func Identity() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		requestId := firstValue(md, metadataRequestId)
+		if requestId == "" || len(requestId) > maxRequestIdLength {
+			requestId = uuid.NewString()
+		}
+		grpc.SetHeader(ctx, metadata.Pairs(metadataRequestId, requestId))
+
+		actor := firstValue(md, metadataActor)
+		client := actor
+		if client == "" {
+			client = peerAddress(ctx)
+		}
+
+		ctx = helper.WithRequestId(ctx, requestId)
+		ctx = helper.WithActor(ctx, actor)
+		ctx = helper.WithClient(ctx, client)
+		return handler(ctx, request)
+	}
+}
+
+// Recover turns the panics the service layer reports errors with into
+// statuses.
+func Recover() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (response interface{}, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				response, err = nil, toStatus(recovered)
+			}
+		}()
+
+		return handler(ctx, request)
+	}
+}
+
+// Audit records every mutating call in the audit log, like the Audit
+// middleware does for the REST API. It runs inside one Recover and outside
+// another, so failed calls are recorded with their final status and a
+// failure to record them does not take the server down.
+func Audit(auditService service.AuditService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+		if !mutatingMethods[method] {
+			return handler(ctx, request)
+		}
+
+		target := &helper.AuditTarget{}
+		response, err := handler(helper.WithAuditTarget(ctx, target), request)
+
+		statusCode := httpStatus(status.Code(err))
+		outcome := service.AuditOutcomeSuccess
+		if err != nil {
+			outcome = service.AuditOutcomeFailure
+		}
+
+		action := target.Action
+		if action == "" {
+			action = fmt.Sprintf("GRPC %s", info.FullMethod)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		auditService.Record(ctx, domain.AuditLog{
+			Actor:      helper.ActorFromContext(ctx),
+			Ip:         peerAddress(ctx),
+			UserAgent:  firstValue(md, "user-agent"),
+			RequestId:  helper.RequestIdFromContext(ctx),
+			Method:     "GRPC",
+			Path:       info.FullMethod,
+			Action:     action,
+			Resource:   target.Resource,
+			Outcome:    outcome,
+			StatusCode: statusCode,
+		})
+
+		return response, err
+	}
+}
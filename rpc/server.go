@@ -0,0 +1,37 @@
+package rpc
+
+import (
+	todov1 "todo-app-api/gen/todo/v1"
+	"todo-app-api/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthv1 "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewServer serves todoServer and commentServer with server reflection and
+// the standard health service, which reports both services and the server as
+// a whole as serving until healthServer.Shutdown is called.
+func NewServer(todoServer *TodoServer, commentServer *CommentServer, auditService service.AuditService) (*grpc.Server, *health.Server) {
+	// the inner Recover hands Audit the final status of a failed call, the
+	// outer one catches a panic of Audit itself
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		Recover(),
+		Identity(),
+		Audit(auditService),
+		Recover(),
+	))
+
+	todov1.RegisterTodoServiceServer(server, todoServer)
+	todov1.RegisterCommentServiceServer(server, commentServer)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(todov1.TodoService_ServiceDesc.ServiceName, healthv1.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(todov1.CommentService_ServiceDesc.ServiceName, healthv1.HealthCheckResponse_SERVING)
+	healthv1.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+
+	return server, healthServer
+}
@@ -0,0 +1,180 @@
+package rpc
+
+import (
+	"context"
+	todov1 "todo-app-api/gen/todo/v1"
+	"todo-app-api/helper"
+	"todo-app-api/models/web"
+	"todo-app-api/service"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TodoServer serves todo.v1.TodoService on top of service.TodoService.
+// Requests are validated before they reach the service so invalid fields can
+// be reported one by one.
+type TodoServer struct {
+	todov1.UnimplementedTodoServiceServer
+
+	todoService service.TodoService
+	validate    *validator.Validate
+}
+
+func NewTodoServer(todoService service.TodoService, validate *validator.Validate) *TodoServer {
+	return &TodoServer{
+		todoService: todoService,
+		validate:    validate,
+	}
+}
+
+func (server *TodoServer) CreateTodo(ctx context.Context, request *todov1.CreateTodoRequest) (*todov1.Todo, error) {
+	createRequest := web.TodoCreateRequest{
+		Title:       request.GetTitle(),
+		Description: request.GetDescription(),
+		Status:      request.GetStatus(),
+	}
+	if err := server.validate.Struct(createRequest); err != nil {
+		return nil, invalidArgument(createRequest, err)
+	}
+
+	return toTodo(server.todoService.Create(ctx, createRequest)), nil
+}
+
+func (server *TodoServer) UpdateTodo(ctx context.Context, request *todov1.UpdateTodoRequest) (*todov1.Todo, error) {
+	updateRequest := web.TodoUpdateRequest{
+		Id:          int(request.GetId()),
+		Title:       request.GetTitle(),
+		Description: request.GetDescription(),
+		Status:      request.GetStatus(),
+	}
+	if err := server.validate.Struct(updateRequest); err != nil {
+		return nil, invalidArgument(updateRequest, err)
+	}
+
+	return toTodo(server.todoService.Update(ctx, updateRequest)), nil
+}
+
+func (server *TodoServer) DeleteTodo(ctx context.Context, request *todov1.DeleteTodoRequest) (*todov1.DeleteTodoResponse, error) {
+	server.todoService.Delete(ctx, int(request.GetId()))
+	return &todov1.DeleteTodoResponse{}, nil
+}
+
+func (server *TodoServer) GetTodo(ctx context.Context, request *todov1.GetTodoRequest) (*todov1.Todo, error) {
+	renderRequest := web.TodoRenderRequest{Render: request.GetRender()}
+	if err := server.validate.Struct(renderRequest); err != nil {
+		return nil, invalidArgument(renderRequest, err)
+	}
+
+	todo := server.todoService.FindById(ctx, int(request.GetId()))
+	if renderRequest.Render == "html" {
+		todo = helper.RenderTodoResponse(todo)
+	}
+	return toTodo(todo), nil
+}
+
+func (server *TodoServer) ListTodos(ctx context.Context, request *todov1.ListTodosRequest) (*todov1.ListTodosResponse, error) {
+	filterRequest := web.TodoFilterRequest{
+		Status:  request.GetStatus(),
+		Mention: request.GetMention(),
+		Link:    request.GetLink(),
+		Render:  request.GetRender(),
+	}
+	if err := server.validate.Struct(filterRequest); err != nil {
+		return nil, invalidArgument(filterRequest, err)
+	}
+
+	todos := server.todoService.FindAll(ctx, filterRequest)
+	if filterRequest.Render == "html" {
+		todos = helper.RenderTodoResponses(todos)
+	}
+	return &todov1.ListTodosResponse{Todos: toTodos(todos)}, nil
+}
+
+func (server *TodoServer) GetHistory(ctx context.Context, request *todov1.GetHistoryRequest) (*todov1.GetHistoryResponse, error) {
+	revisions := server.todoService.History(ctx, int(request.GetTodoId()))
+	return &todov1.GetHistoryResponse{Revisions: toRevisions(revisions)}, nil
+}
+
+func (server *TodoServer) RevertTodo(ctx context.Context, request *todov1.RevertTodoRequest) (*todov1.Todo, error) {
+	return toTodo(server.todoService.Revert(ctx, int(request.GetTodoId()), int(request.GetRevision()))), nil
+}
+
+func (server *TodoServer) SetTask(ctx context.Context, request *todov1.SetTaskRequest) (*todov1.Todo, error) {
+	checked := request.GetChecked()
+	taskRequest := web.TodoTaskRequest{
+		Id:      int(request.GetId()),
+		Task:    int(request.GetTask()),
+		Checked: &checked,
+	}
+	if err := server.validate.Struct(taskRequest); err != nil {
+		return nil, invalidArgument(taskRequest, err)
+	}
+
+	return toTodo(server.todoService.SetTask(ctx, taskRequest)), nil
+}
+
+func (server *TodoServer) BatchGetTodos(ctx context.Context, request *todov1.BatchGetTodosRequest) (*todov1.BatchGetTodosResponse, error) {
+	todos := server.todoService.FindByIds(ctx, toInts(request.GetIds()))
+	return &todov1.BatchGetTodosResponse{Todos: toTodos(todos)}, nil
+}
+
+func (server *TodoServer) BatchGetHistories(ctx context.Context, request *todov1.BatchGetHistoriesRequest) (*todov1.BatchGetHistoriesResponse, error) {
+	histories := map[int64]*todov1.History{}
+	for todoId, revisions := range server.todoService.HistoryByTodoIds(ctx, toInts(request.GetTodoIds())) {
+		histories[int64(todoId)] = &todov1.History{Revisions: toRevisions(revisions)}
+	}
+	return &todov1.BatchGetHistoriesResponse{Histories: histories}, nil
+}
+
+func toInts(ids []int64) []int {
+	result := make([]int, len(ids))
+	for i, id := range ids {
+		result[i] = int(id)
+	}
+	return result
+}
+
+func toTodo(todo web.TodoResponse) *todov1.Todo {
+	message := &todov1.Todo{
+		Id:              int64(todo.Id),
+		Title:           todo.Title,
+		Description:     todo.Description,
+		Status:          todo.Status,
+		DescriptionHtml: todo.DescriptionHtml,
+		CommentCount:    int32(todo.CommentCount),
+	}
+	if !todo.CreatedAt.IsZero() {
+		message.CreatedAt = timestamppb.New(todo.CreatedAt)
+	}
+	if !todo.UpdatedAt.IsZero() {
+		message.UpdatedAt = timestamppb.New(todo.UpdatedAt)
+	}
+	return message
+}
+
+func toTodos(todos []web.TodoResponse) []*todov1.Todo {
+	var messages []*todov1.Todo
+	for _, todo := range todos {
+		messages = append(messages, toTodo(todo))
+	}
+	return messages
+}
+
+func toRevisions(revisions []web.TodoRevisionResponse) []*todov1.TodoRevision {
+	var messages []*todov1.TodoRevision
+	for _, revision := range revisions {
+		message := &todov1.TodoRevision{
+			Revision:  int32(revision.Revision),
+			TodoId:    int64(revision.TodoId),
+			Action:    revision.Action,
+			Actor:     revision.Actor,
+			CreatedAt: timestamppb.New(revision.CreatedAt),
+		}
+		for _, change := range revision.Changes {
+			message.Changes = append(message.Changes, &todov1.FieldChange{Field: change.Field, Old: change.Old, New: change.New})
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+	"todo-app-api/models/domain"
+	"todo-app-api/models/web"
+)
+
+type AuditService interface {
+	Record(context context.Context, auditLog domain.AuditLog)
+	FindAll(context context.Context, request web.AuditLogFilterRequest) web.PageResponse
+	Verify(context context.Context) web.AuditVerifyResponse
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"todo-app-api/helper"
+	"todo-app-api/models/domain"
+	"todo-app-api/models/web"
+	"todo-app-api/repository"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+
+	auditDefaultPageSize = 20
+	auditVerifyBatchSize = 500
+	auditAppendAttempts  = 5
+	auditHashVersion     = 2
+)
+
+type AuditServiceImpl struct {
+	AuditLogRepository repository.AuditLogRepository
+	DB                 *gorm.DB
+	Validate           *validator.Validate
+	mutex              sync.Mutex
+}
+
+func NewAuditService(auditLogRepository repository.AuditLogRepository, DB *gorm.DB, validate *validator.Validate) AuditService {
+	return &AuditServiceImpl{
+		AuditLogRepository: auditLogRepository,
+		DB:                 DB,
+		Validate:           validate,
+	}
+}
+
+// auditHash hashes every stored field of the entry together with the hash of
+// the previous entry, so editing, removing or reordering rows breaks the chain.
+// Version 1 entries joined the fields with "|", which let a "|" inside one
+// field move into the next without changing the hash; since version 2 every
+// field is prefixed with its length.
+func auditHash(auditLog domain.AuditLog) string {
+	fields := []string{
+		auditLog.PrevHash,
+		auditLog.Actor,
+		auditLog.Ip,
+		auditLog.UserAgent,
+		auditLog.RequestId,
+		auditLog.Method,
+		auditLog.Path,
+		auditLog.Action,
+		auditLog.Resource,
+		auditLog.Outcome,
+		strconv.Itoa(auditLog.StatusCode),
+		auditLog.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+
+	var payload strings.Builder
+	if auditLog.HashVersion < 2 {
+		payload.WriteString(strings.Join(fields, "|"))
+	} else {
+		fmt.Fprintf(&payload, "v%d", auditLog.HashVersion)
+		for _, field := range fields {
+			fmt.Fprintf(&payload, "|%d:%s", len(field), field)
+		}
+	}
+	sum := sha256.Sum256([]byte(payload.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (service *AuditServiceImpl) Record(ctx context.Context, auditLog domain.AuditLog) {
+	// appends of this process are serialized; those of other instances are
+	// caught by the unique prev_hash and retried on top of the new head
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	var err error
+	for attempt := 0; attempt < auditAppendAttempts; attempt++ {
+		if err = service.append(ctx, auditLog); err == nil {
+			return
+		}
+	}
+	panic(err)
+}
+
+// append links auditLog to the current head of the chain in one transaction.
+func (service *AuditServiceImpl) append(ctx context.Context, auditLog domain.AuditLog) (err error) {
+	tx := service.DB.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit().Error
+	}()
+
+	last, err := service.AuditLogRepository.FindLast(ctx, tx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	auditLog.Id = 0
+	auditLog.PrevHash = last.Hash
+	auditLog.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	auditLog.HashVersion = auditHashVersion
+	auditLog.Hash = auditHash(auditLog)
+
+	_, err = service.AuditLogRepository.Save(ctx, tx, auditLog)
+	return err
+}
+
+func (service *AuditServiceImpl) FindAll(ctx context.Context, request web.AuditLogFilterRequest) web.PageResponse {
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+
+	if request.Page == 0 {
+		request.Page = 1
+	}
+	if request.Size == 0 {
+		request.Size = auditDefaultPageSize
+	}
+
+	filter := domain.AuditLogFilter{
+		Actor:    request.Actor,
+		Action:   request.Action,
+		Resource: request.Resource,
+		Outcome:  request.Outcome,
+		Offset:   (request.Page - 1) * request.Size,
+		Limit:    request.Size,
+	}
+	if request.From != "" {
+		filter.From, _ = time.Parse(time.RFC3339, request.From)
+	}
+	if request.To != "" {
+		filter.To, _ = time.Parse(time.RFC3339, request.To)
+	}
+
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	auditLogs, total := service.AuditLogRepository.FindAll(ctx, tx, filter)
+
+	return web.PageResponse{
+		Items: helper.ToAuditLogResponses(auditLogs),
+		Page:  request.Page,
+		Size:  request.Size,
+		Total: total,
+	}
+}
+
+func (service *AuditServiceImpl) Verify(ctx context.Context) web.AuditVerifyResponse {
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	response := web.AuditVerifyResponse{Valid: true}
+	prevHash := ""
+	service.AuditLogRepository.FindInBatches(ctx, tx, auditVerifyBatchSize, func(auditLogs []domain.AuditLog) bool {
+		for _, auditLog := range auditLogs {
+			if auditLog.PrevHash != prevHash || auditHash(auditLog) != auditLog.Hash {
+				response.Valid = false
+				response.BrokenAt = auditLog.Id
+				return false
+			}
+			prevHash = auditLog.Hash
+			response.Entries++
+		}
+		return true
+	})
+
+	return response
+}
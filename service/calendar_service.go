@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+	"todo-app-api/models/web"
+)
+
+// CalendarService serves todos as an iCalendar feed. Feed and Upload
+// authenticate with a token created by CreateToken.
+type CalendarService interface {
+	CreateToken(context context.Context) web.CalendarTokenResponse
+	DeleteToken(context context.Context, tokenId int)
+	Feed(context context.Context, token string) []byte
+	Upload(context context.Context, token string, content []byte) web.CalendarUploadResponse
+}
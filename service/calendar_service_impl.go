@@ -0,0 +1,343 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"todo-app-api/exception"
+	"todo-app-api/helper"
+	"todo-app-api/ical"
+	"todo-app-api/models/domain"
+	"todo-app-api/models/web"
+	"todo-app-api/repository"
+	"todo-app-api/tracing"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+const (
+	CalendarResultCreated   = "created"
+	CalendarResultUpdated   = "updated"
+	CalendarResultUnchanged = "unchanged"
+	CalendarResultStale     = "stale"
+	CalendarResultInvalid   = "invalid"
+
+	calendarProductId = "-//todo-app-api//Todo App API//EN"
+)
+
+// todoUid is the UID of a todo that was not uploaded by a calendar app.
+var todoUid = regexp.MustCompile(`^todo-(\d+)@todo-app-api$`)
+
+func defaultTodoUid(todoId int) string {
+	return fmt.Sprintf("todo-%d@todo-app-api", todoId)
+}
+
+// sequenceOf derives the SEQUENCE of a todo from its revisions: 0 when
+// created, one more for every change since.
+func sequenceOf(lastRevision int) int {
+	return max(lastRevision-1, 0)
+}
+
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type CalendarServiceImpl struct {
+	CalendarTokenRepository  repository.CalendarTokenRepository
+	CalendarObjectRepository repository.CalendarObjectRepository
+	TodoRepository           repository.TodoRepository
+	TodoRevisionRepository   repository.TodoRevisionRepository
+	TodoService              TodoService
+	DB                       *gorm.DB
+	Validate                 *validator.Validate
+}
+
+func NewCalendarService(calendarTokenRepository repository.CalendarTokenRepository, calendarObjectRepository repository.CalendarObjectRepository, todoRepository repository.TodoRepository, todoRevisionRepository repository.TodoRevisionRepository, todoService TodoService, DB *gorm.DB, validate *validator.Validate) CalendarService {
+	return &CalendarServiceImpl{
+		CalendarTokenRepository:  calendarTokenRepository,
+		CalendarObjectRepository: calendarObjectRepository,
+		TodoRepository:           todoRepository,
+		TodoRevisionRepository:   todoRevisionRepository,
+		TodoService:              todoService,
+		DB:                       DB,
+		Validate:                 validate,
+	}
+}
+
+func (service *CalendarServiceImpl) CreateToken(ctx context.Context) web.CalendarTokenResponse {
+	actor := helper.ActorFromContext(ctx)
+	if actor == helper.AnonymousActor {
+		helper.PanicIfError(errors.New("a calendar token belongs to a user, name one in X-Actor"))
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	calendarToken := service.CalendarTokenRepository.Save(ctx, tx, domain.CalendarToken{
+		Actor:     actor,
+		TokenHash: hashCalendarToken(token),
+	})
+	helper.SetAuditTarget(ctx, "calendar_token.create", calendarTokenResource(calendarToken.Id))
+
+	return web.CalendarTokenResponse{
+		Id:        calendarToken.Id,
+		Actor:     calendarToken.Actor,
+		Token:     token,
+		FeedPath:  "/calendar/todos.ics?token=" + token,
+		CreatedAt: calendarToken.CreatedAt,
+	}
+}
+
+// DeleteToken revokes a token of the calling actor. Tokens of others are
+// reported as not found.
+func (service *CalendarServiceImpl) DeleteToken(ctx context.Context, tokenId int) {
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	calendarToken, err := service.CalendarTokenRepository.FindById(ctx, tx, tokenId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		panic(err)
+	}
+	if err != nil || calendarToken.Actor != helper.ActorFromContext(ctx) {
+		panic(exception.NotFoundError{Message: "calendar token not found"})
+	}
+
+	service.CalendarTokenRepository.Delete(ctx, tx, calendarToken)
+	helper.SetAuditTarget(ctx, "calendar_token.delete", calendarTokenResource(tokenId))
+}
+
+// authenticate answers unknown tokens with not found, so a guessed URL looks
+// like any other missing page.
+func (service *CalendarServiceImpl) authenticate(ctx context.Context, tx *gorm.DB, token string) domain.CalendarToken {
+	calendarToken, err := service.CalendarTokenRepository.FindByHash(ctx, tx, hashCalendarToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			panic(exception.NotFoundError{Message: "calendar feed not found"})
+		}
+		panic(err)
+	}
+	return calendarToken
+}
+
+func (service *CalendarServiceImpl) Feed(ctx context.Context, token string) []byte {
+	ctx, span := tracing.Tracer().Start(ctx, "CalendarService.Feed")
+	defer tracing.End(span)
+
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	service.authenticate(ctx, tx, token)
+
+	todos := service.TodoRepository.FindAll(ctx, tx, domain.TodoFilter{})
+	todoIds := make([]int, len(todos))
+	for i, todo := range todos {
+		todoIds[i] = todo.Id
+	}
+	lastRevisions := service.TodoRevisionRepository.LastRevisions(ctx, tx, todoIds)
+	uids := map[int]string{}
+	for _, object := range service.CalendarObjectRepository.FindByTodoIds(ctx, tx, todoIds) {
+		uids[object.TodoId] = object.Uid
+	}
+
+	calendar := ical.NewComponent("VCALENDAR")
+	calendar.Add("VERSION", "2.0")
+	calendar.Add("PRODID", calendarProductId)
+	calendar.Add("CALSCALE", "GREGORIAN")
+	calendar.AddText("X-WR-CALNAME", "Todos")
+	for _, todo := range todos {
+		uid, ok := uids[todo.Id]
+		if !ok {
+			uid = defaultTodoUid(todo.Id)
+		}
+
+		// DTSTAMP follows the todo rather than the clock so an unchanged
+		// todo renders to the same bytes on every fetch
+		vtodo := ical.NewComponent("VTODO")
+		vtodo.AddText("UID", uid)
+		vtodo.AddTime("DTSTAMP", todo.UpdatedAt)
+		vtodo.AddTime("CREATED", todo.CreatedAt)
+		vtodo.AddTime("LAST-MODIFIED", todo.UpdatedAt)
+		vtodo.Add("SEQUENCE", strconv.Itoa(sequenceOf(lastRevisions[todo.Id])))
+		vtodo.AddText("SUMMARY", todo.Title)
+		if todo.Description != "" {
+			vtodo.AddText("DESCRIPTION", todo.Description)
+		}
+		if todo.Status == "done" {
+			vtodo.Add("STATUS", "COMPLETED")
+			vtodo.AddTime("COMPLETED", todo.UpdatedAt)
+		} else {
+			vtodo.Add("STATUS", "NEEDS-ACTION")
+		}
+		calendar.Components = append(calendar.Components, vtodo)
+	}
+
+	var feed bytes.Buffer
+	if err := ical.Encode(&feed, calendar); err != nil {
+		panic(err)
+	}
+	return feed.Bytes()
+}
+
+// Upload creates or updates a todo for every VTODO of content. A VTODO is
+// matched to its todo by UID; one that matches the todo as stored is left
+// alone, so uploading the same calendar twice changes nothing. Changes made
+// from an older SEQUENCE than the stored todo are rejected as stale.
+func (service *CalendarServiceImpl) Upload(ctx context.Context, token string, content []byte) web.CalendarUploadResponse {
+	ctx, span := tracing.Tracer().Start(ctx, "CalendarService.Upload")
+	defer tracing.End(span)
+
+	calendarToken := service.authenticate(ctx, service.DB, token)
+	ctx = helper.WithActor(ctx, calendarToken.Actor)
+
+	calendar, err := ical.Decode(bytes.NewReader(content))
+	helper.PanicIfError(err)
+	if calendar.Name != "VCALENDAR" {
+		helper.PanicIfError(fmt.Errorf("expected a VCALENDAR, got a %s", calendar.Name))
+	}
+
+	response := web.CalendarUploadResponse{Results: []web.CalendarUploadResult{}}
+	for _, vtodo := range calendar.Children("VTODO") {
+		response.Results = append(response.Results, service.upload(ctx, vtodo))
+	}
+	helper.SetAuditTarget(ctx, "calendar.upload", calendarTokenResource(calendarToken.Id))
+
+	return response
+}
+
+func (service *CalendarServiceImpl) upload(ctx context.Context, vtodo *ical.Component) web.CalendarUploadResult {
+	uid := strings.TrimSpace(vtodo.Text("UID"))
+	result := web.CalendarUploadResult{Uid: uid}
+	if uid == "" {
+		result.Result = CalendarResultInvalid
+		result.Errors = []string{"UID is required"}
+		return result
+	}
+
+	status := "pending"
+	if strings.EqualFold(vtodo.Text("STATUS"), "COMPLETED") {
+		status = "done"
+	}
+	request := web.TodoCreateRequest{
+		Title:       strings.TrimSpace(vtodo.Text("SUMMARY")),
+		Description: strings.TrimSpace(vtodo.Text("DESCRIPTION")),
+		Status:      status,
+	}
+	if err := service.Validate.Struct(request); err != nil {
+		result.Result = CalendarResultInvalid
+		result.Errors = fieldProblems(err)
+		return result
+	}
+	sequence, _ := strconv.Atoi(vtodo.Text("SEQUENCE"))
+
+	todo, found, stale := service.findByUid(ctx, uid)
+	if !found {
+		created, err := service.create(ctx, uid, stale, request)
+		if err == nil {
+			result.Result = CalendarResultCreated
+			result.TodoId = created.Id
+			return result
+		}
+		// a concurrent upload of the same UID created it first
+		todo, found, _ = service.findByUid(ctx, uid)
+		if !found {
+			panic(err)
+		}
+	}
+
+	result.TodoId = todo.Id
+	result.Sequence = sequenceOf(service.TodoRevisionRepository.LastRevision(ctx, service.DB, todo.Id))
+	switch {
+	case todo.Title == request.Title && todo.Description == request.Description && todo.Status == request.Status:
+		result.Result = CalendarResultUnchanged
+	case sequence < result.Sequence:
+		result.Result = CalendarResultStale
+		result.Errors = []string{fmt.Sprintf("SEQUENCE %d is older than the stored %d", sequence, result.Sequence)}
+	default:
+		service.TodoService.Update(ctx, web.TodoUpdateRequest{
+			Id:          todo.Id,
+			Title:       request.Title,
+			Description: request.Description,
+			Status:      request.Status,
+		})
+		result.Result = CalendarResultUpdated
+		result.Sequence++
+	}
+	return result
+}
+
+// create stores the todo and the UID it was uploaded under in one
+// transaction, so a todo never exists without its UID. The unique index on
+// the UID makes the loser of two concurrent uploads roll back with an error.
+// stale is a mapping of the UID to a deleted todo, which the new todo takes
+// over.
+func (service *CalendarServiceImpl) create(ctx context.Context, uid string, stale *domain.CalendarObject, request web.TodoCreateRequest) (created web.TodoResponse, err error) {
+	tx := service.DB.Begin()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			tx.Rollback()
+			panic(recovered)
+		}
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit().Error
+	}()
+
+	if stale != nil {
+		service.CalendarObjectRepository.Delete(ctx, tx, *stale)
+	}
+	created = service.TodoService.CreateWithin(ctx, tx, request)
+	if uid != defaultTodoUid(created.Id) {
+		_, err = service.CalendarObjectRepository.Save(ctx, tx, domain.CalendarObject{TodoId: created.Id, Uid: uid})
+	}
+	return created, err
+}
+
+// findByUid looks a todo up by the UID a calendar app gave it, then by the
+// UID the feed serves it under. A UID that still points at a deleted todo is
+// returned as stale.
+func (service *CalendarServiceImpl) findByUid(ctx context.Context, uid string) (todo domain.Todo, found bool, stale *domain.CalendarObject) {
+	todoId := 0
+	object, err := service.CalendarObjectRepository.FindByUid(ctx, service.DB, uid)
+	switch {
+	case err == nil:
+		todoId = object.TodoId
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		panic(err)
+	default:
+		match := todoUid.FindStringSubmatch(uid)
+		if match == nil {
+			return domain.Todo{}, false, nil
+		}
+		todoId, _ = strconv.Atoi(match[1])
+	}
+
+	todo, err = service.TodoRepository.FindById(ctx, service.DB, todoId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if object.Uid != "" {
+				return domain.Todo{}, false, &object
+			}
+			return domain.Todo{}, false, nil
+		}
+		panic(err)
+	}
+	return todo, true, nil
+}
@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+	"todo-app-api/models/web"
+)
+
+type CommentService interface {
+	Create(context context.Context, request web.CommentCreateRequest) web.CommentResponse
+	Update(context context.Context, request web.CommentUpdateRequest) web.CommentResponse
+	Delete(context context.Context, todoId int, commentId int)
+	FindAll(context context.Context, todoId int, request web.CommentFilterRequest) web.PageResponse
+	History(context context.Context, todoId int, commentId int) []web.CommentEditResponse
+	React(context context.Context, request web.CommentReactionRequest) web.CommentResponse
+}
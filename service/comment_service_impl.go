@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo-app-api/exception"
+	"todo-app-api/helper"
+	"todo-app-api/models/domain"
+	"todo-app-api/models/web"
+	"todo-app-api/repository"
+	"todo-app-api/tracing"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+const commentDefaultPageSize = 20
+
+type CommentServiceImpl struct {
+	CommentRepository         repository.CommentRepository
+	CommentEditRepository     repository.CommentEditRepository
+	CommentReactionRepository repository.CommentReactionRepository
+	TodoRepository            repository.TodoRepository
+	DB                        *gorm.DB
+	Validate                  *validator.Validate
+}
+
+func NewCommentService(commentRepository repository.CommentRepository, commentEditRepository repository.CommentEditRepository, commentReactionRepository repository.CommentReactionRepository, todoRepository repository.TodoRepository, DB *gorm.DB, validate *validator.Validate) CommentService {
+	return &CommentServiceImpl{
+		CommentRepository:         commentRepository,
+		CommentEditRepository:     commentEditRepository,
+		CommentReactionRepository: commentReactionRepository,
+		TodoRepository:            todoRepository,
+		DB:                        DB,
+		Validate:                  validate,
+	}
+}
+
+// author is the actor a change to a comment is made by. Comments are signed,
+// so anonymous requests are refused.
+func author(ctx context.Context) string {
+	actor := helper.ActorFromContext(ctx)
+	if actor == helper.AnonymousActor {
+		helper.PanicIfError(errors.New("a comment needs an author, name one in X-Actor"))
+	}
+	return actor
+}
+
+func (service *CommentServiceImpl) findTodo(ctx context.Context, tx *gorm.DB, todoId int) {
+	_, err := service.TodoRepository.FindById(ctx, tx, todoId)
+	panicIfTodoMissing(err)
+}
+
+// lockTodo finds the todo of a change to its comments and keeps it locked
+// until tx ends. A concurrent TodoService.Delete then either runs first, and
+// the change finds no todo, or waits and removes the new rows with the rest.
+func (service *CommentServiceImpl) lockTodo(ctx context.Context, tx *gorm.DB, todoId int) {
+	_, err := service.TodoRepository.FindByIdForUpdate(ctx, tx, todoId)
+	panicIfTodoMissing(err)
+}
+
+func panicIfTodoMissing(err error) {
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			panic(exception.NotFoundError{Message: "todo not found"})
+		}
+		panic(err)
+	}
+}
+
+// findComment looks a comment up under the todo of the path; a comment of
+// another todo is reported as not found.
+func (service *CommentServiceImpl) findComment(ctx context.Context, tx *gorm.DB, todoId int, commentId int, message string) domain.Comment {
+	comment, err := service.CommentRepository.FindById(ctx, tx, commentId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		panic(err)
+	}
+	if err != nil || comment.TodoId != todoId {
+		panic(exception.NotFoundError{Message: message})
+	}
+	return comment
+}
+
+func (service *CommentServiceImpl) toResponse(ctx context.Context, tx *gorm.DB, comment domain.Comment) web.CommentResponse {
+	return helper.ToCommentResponse(comment, service.CommentReactionRepository.FindByCommentIds(ctx, tx, []int{comment.Id}))
+}
+
+func (service *CommentServiceImpl) Create(ctx context.Context, request web.CommentCreateRequest) web.CommentResponse {
+	ctx, span := tracing.Tracer().Start(ctx, "CommentService.Create")
+	defer tracing.End(span)
+
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+	actor := author(ctx)
+
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	service.lockTodo(ctx, tx, request.TodoId)
+	if request.ParentId != nil {
+		parent := service.findComment(ctx, tx, request.TodoId, *request.ParentId, "parent comment not found")
+		if parent.DeletedAt != nil {
+			helper.PanicIfError(errors.New("a deleted comment cannot be replied to"))
+		}
+	}
+
+	comment := service.CommentRepository.Save(ctx, tx, domain.Comment{
+		TodoId:   request.TodoId,
+		ParentId: request.ParentId,
+		Actor:    actor,
+		Body:     request.Body,
+	})
+	helper.SetAuditTarget(ctx, "comment.create", commentResource(comment.Id))
+
+	return helper.ToCommentResponse(comment, nil)
+}
+
+// Update replaces the body of a comment of the calling actor and keeps the
+// previous one in its edit history.
+func (service *CommentServiceImpl) Update(ctx context.Context, request web.CommentUpdateRequest) web.CommentResponse {
+	ctx, span := tracing.Tracer().Start(ctx, "CommentService.Update")
+	defer tracing.End(span)
+
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+	actor := author(ctx)
+
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	service.lockTodo(ctx, tx, request.TodoId)
+	comment := service.findComment(ctx, tx, request.TodoId, request.Id, "comment not found")
+	if comment.DeletedAt != nil {
+		helper.PanicIfError(errors.New("a deleted comment cannot be edited"))
+	}
+	if comment.Actor != actor {
+		panic(exception.ForbiddenError{Message: "only the author can edit a comment"})
+	}
+
+	if request.Body != comment.Body {
+		service.CommentEditRepository.Save(ctx, tx, domain.CommentEdit{
+			CommentId: comment.Id,
+			Body:      comment.Body,
+			Actor:     actor,
+		})
+		editedAt := time.Now()
+		comment.Body = request.Body
+		comment.EditedAt = &editedAt
+		comment = service.CommentRepository.Update(ctx, tx, comment)
+	}
+	helper.SetAuditTarget(ctx, "comment.update", commentResource(comment.Id))
+
+	return service.toResponse(ctx, tx, comment)
+}
+
+// Delete blanks a comment of the calling actor and drops its edit history and
+// reactions. The comment stays in its thread so the replies below it do too.
+func (service *CommentServiceImpl) Delete(ctx context.Context, todoId int, commentId int) {
+	ctx, span := tracing.Tracer().Start(ctx, "CommentService.Delete")
+	defer tracing.End(span)
+
+	actor := author(ctx)
+
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	comment := service.findComment(ctx, tx, todoId, commentId, "comment not found")
+	if comment.Actor != actor {
+		panic(exception.ForbiddenError{Message: "only the author can delete a comment"})
+	}
+
+	if comment.DeletedAt == nil {
+		deletedAt := time.Now()
+		comment.Body = ""
+		comment.DeletedAt = &deletedAt
+		service.CommentRepository.Update(ctx, tx, comment)
+		service.CommentEditRepository.DeleteByCommentId(ctx, tx, comment.Id)
+		service.CommentReactionRepository.DeleteByCommentId(ctx, tx, comment.Id)
+	}
+	helper.SetAuditTarget(ctx, "comment.delete", commentResource(comment.Id))
+}
+
+// FindAll pages through the threads of a todo, oldest first, each with all
+// of its replies nested below it.
+func (service *CommentServiceImpl) FindAll(ctx context.Context, todoId int, request web.CommentFilterRequest) web.PageResponse {
+	ctx, span := tracing.Tracer().Start(ctx, "CommentService.FindAll")
+	defer tracing.End(span)
+
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+
+	if request.Page == 0 {
+		request.Page = 1
+	}
+	if request.Size == 0 {
+		request.Size = commentDefaultPageSize
+	}
+
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	service.findTodo(ctx, tx, todoId)
+	threads, total := service.CommentRepository.FindThreads(ctx, tx, domain.CommentFilter{
+		TodoId: todoId,
+		Offset: (request.Page - 1) * request.Size,
+		Limit:  request.Size,
+	})
+
+	replies := map[int][]domain.Comment{}
+	commentIds := []int{}
+	for _, thread := range threads {
+		commentIds = append(commentIds, thread.Id)
+	}
+	for _, reply := range service.CommentRepository.FindReplies(ctx, tx, todoId) {
+		replies[*reply.ParentId] = append(replies[*reply.ParentId], reply)
+		commentIds = append(commentIds, reply.Id)
+	}
+
+	reactions := map[int][]domain.CommentReaction{}
+	for _, reaction := range service.CommentReactionRepository.FindByCommentIds(ctx, tx, commentIds) {
+		reactions[reaction.CommentId] = append(reactions[reaction.CommentId], reaction)
+	}
+
+	var thread func(comment domain.Comment) web.CommentResponse
+	thread = func(comment domain.Comment) web.CommentResponse {
+		commentResponse := helper.ToCommentResponse(comment, reactions[comment.Id])
+		for _, reply := range replies[comment.Id] {
+			commentResponse.Replies = append(commentResponse.Replies, thread(reply))
+		}
+		return commentResponse
+	}
+
+	commentResponses := []web.CommentResponse{}
+	for _, comment := range threads {
+		commentResponses = append(commentResponses, thread(comment))
+	}
+
+	return web.PageResponse{
+		Items: commentResponses,
+		Page:  request.Page,
+		Size:  request.Size,
+		Total: total,
+	}
+}
+
+// History lists the bodies a comment had before its edits, oldest first.
+func (service *CommentServiceImpl) History(ctx context.Context, todoId int, commentId int) []web.CommentEditResponse {
+	ctx, span := tracing.Tracer().Start(ctx, "CommentService.History")
+	defer tracing.End(span)
+
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	comment := service.findComment(ctx, tx, todoId, commentId, "comment not found")
+	return helper.ToCommentEditResponses(service.CommentEditRepository.FindByCommentId(ctx, tx, comment.Id))
+}
+
+// React sets whether the calling actor reacted to a comment with an emoji.
+// Setting it twice has the same effect as once.
+func (service *CommentServiceImpl) React(ctx context.Context, request web.CommentReactionRequest) web.CommentResponse {
+	ctx, span := tracing.Tracer().Start(ctx, "CommentService.React")
+	defer tracing.End(span)
+
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+	actor := author(ctx)
+
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	service.lockTodo(ctx, tx, request.TodoId)
+	comment := service.findComment(ctx, tx, request.TodoId, request.Id, "comment not found")
+	if comment.DeletedAt != nil {
+		helper.PanicIfError(errors.New("a deleted comment cannot be reacted to"))
+	}
+
+	reaction := domain.CommentReaction{CommentId: comment.Id, Actor: actor, Emoji: request.Emoji}
+	if *request.Reacted {
+		service.CommentReactionRepository.Save(ctx, tx, reaction)
+	} else {
+		service.CommentReactionRepository.Delete(ctx, tx, reaction)
+	}
+	helper.SetAuditTarget(ctx, "comment.react", commentResource(comment.Id))
+
+	return service.toResponse(ctx, tx, comment)
+}
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+	"todo-app-api/models/web"
+)
+
+type HealthService interface {
+	Liveness(context context.Context) web.HealthResponse
+	Readiness(context context.Context) web.HealthResponse
+	Version(context context.Context) web.VersionResponse
+	DatabaseStats(context context.Context) web.DatabaseStatsResponse
+}
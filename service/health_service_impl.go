@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"todo-app-api/config"
+	"todo-app-api/helper"
+	"todo-app-api/lifecycle"
+	"todo-app-api/migration"
+	"todo-app-api/models/web"
+
+	"gorm.io/gorm"
+)
+
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+// HealthCheck is a single readiness dependency. Check must honour ctx, which
+// carries the per-check timeout.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type HealthServiceImpl struct {
+	DB        *gorm.DB
+	Lifecycle *lifecycle.Lifecycle
+	Checks    []HealthCheck
+	// Timeout bounds each check; CacheTTL is how long a readiness result is
+	// reused so frequent probes do not hit the database every time.
+	Timeout   time.Duration
+	CacheTTL  time.Duration
+	StartedAt time.Time
+	mutex     sync.Mutex
+	cached    web.HealthResponse
+	expires   time.Time
+}
+
+func NewHealthService(DB *gorm.DB, migrator *migration.Migrator, lifecycle *lifecycle.Lifecycle, healthConfig config.HealthConfig) HealthService {
+	return &HealthServiceImpl{
+		DB:        DB,
+		Lifecycle: lifecycle,
+		Checks: []HealthCheck{
+			{Name: "database", Check: databaseCheck(DB)},
+			{Name: "migrations", Check: migrationCheck(migrator)},
+			{Name: "workers", Check: workerCheck(lifecycle)},
+		},
+		Timeout:   healthConfig.Timeout,
+		CacheTTL:  healthConfig.CacheTTL,
+		StartedAt: time.Now().UTC(),
+	}
+}
+
+func databaseCheck(DB *gorm.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		sqlDB, err := DB.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+func migrationCheck(migrator *migration.Migrator) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d pending migration(s), first is %d_%s", len(pending), pending[0].Version, pending[0].Name)
+		}
+		return nil
+	}
+}
+
+func workerCheck(lifecycle *lifecycle.Lifecycle) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var stopped []string
+		for name, running := range lifecycle.Workers() {
+			if !running {
+				stopped = append(stopped, name)
+			}
+		}
+		if len(stopped) > 0 {
+			sort.Strings(stopped)
+			return fmt.Errorf("stopped: %s", strings.Join(stopped, ", "))
+		}
+		return nil
+	}
+}
+
+func (service *HealthServiceImpl) Liveness(ctx context.Context) web.HealthResponse {
+	return web.HealthResponse{
+		Status:    HealthStatusUp,
+		CheckedAt: time.Now().UTC(),
+	}
+}
+
+func (service *HealthServiceImpl) Readiness(ctx context.Context) web.HealthResponse {
+	// shutdown must be visible at once, not after the cache expires
+	if service.Lifecycle.ShuttingDown() {
+		return web.HealthResponse{
+			Status: HealthStatusDown,
+			Checks: map[string]web.HealthCheckResponse{
+				"lifecycle": {Status: HealthStatusDown, Error: "shutting down"},
+			},
+			CheckedAt: time.Now().UTC(),
+		}
+	}
+
+	// holding the lock while checking collapses concurrent probes into one run
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	if time.Now().Before(service.expires) {
+		return service.cached
+	}
+
+	service.cached = service.check(ctx)
+	service.expires = time.Now().Add(service.CacheTTL)
+	return service.cached
+}
+
+func (service *HealthServiceImpl) check(ctx context.Context) web.HealthResponse {
+	response := web.HealthResponse{
+		Status:    HealthStatusUp,
+		Checks:    make(map[string]web.HealthCheckResponse, len(service.Checks)),
+		CheckedAt: time.Now().UTC(),
+	}
+
+	results := make([]web.HealthCheckResponse, len(service.Checks))
+	var wait sync.WaitGroup
+	for i, healthCheck := range service.Checks {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			results[i] = service.run(ctx, healthCheck)
+		}()
+	}
+	wait.Wait()
+
+	for i, healthCheck := range service.Checks {
+		response.Checks[healthCheck.Name] = results[i]
+		if results[i].Status != HealthStatusUp {
+			response.Status = HealthStatusDown
+		}
+	}
+	return response
+}
+
+func (service *HealthServiceImpl) run(ctx context.Context, healthCheck HealthCheck) web.HealthCheckResponse {
+	// the result is shared through the cache, so one probe hanging up must
+	// not fail the check for everyone else
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), service.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- healthCheck.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := web.HealthCheckResponse{
+		Status:     HealthStatusUp,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", service.Timeout)
+		}
+		result.Status = HealthStatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (service *HealthServiceImpl) Version(ctx context.Context) web.VersionResponse {
+	response := web.VersionResponse{
+		Version:       config.Version,
+		GoVersion:     runtime.Version(),
+		StartedAt:     service.StartedAt,
+		UptimeSeconds: int64(time.Since(service.StartedAt).Seconds()),
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				response.Revision = setting.Value
+			}
+		}
+	}
+	return response
+}
+
+func (service *HealthServiceImpl) DatabaseStats(ctx context.Context) web.DatabaseStatsResponse {
+	stats, err := config.PoolStats(service.DB)
+	if err != nil {
+		panic(err)
+	}
+	return helper.ToDatabaseStatsResponse(stats)
+}
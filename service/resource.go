@@ -0,0 +1,21 @@
+package service
+
+import "fmt"
+
+// resource names identify the target of a change in the audit log.
+
+func todoResource(todoId int) string {
+	return fmt.Sprintf("todo:%d", todoId)
+}
+
+func webhookResource(webhookId int) string {
+	return fmt.Sprintf("webhook:%d", webhookId)
+}
+
+func commentResource(commentId int) string {
+	return fmt.Sprintf("comment:%d", commentId)
+}
+
+func calendarTokenResource(tokenId int) string {
+	return fmt.Sprintf("calendar_token:%d", tokenId)
+}
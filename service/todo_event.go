@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"todo-app-api/event"
+	"todo-app-api/helper"
+	"todo-app-api/models/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const todoAggregate = "todo"
+
+// enqueueEvent writes the event to the outbox inside tx, so it is committed
+// or rolled back together with the change it describes.
+func (service *TodoServiceImpl) enqueueEvent(ctx context.Context, tx *gorm.DB, eventType string, todo domain.Todo) {
+	evt := event.Event{
+		Id:         uuid.NewString(),
+		Type:       eventType,
+		TodoId:     todo.Id,
+		Data:       helper.ToTodoResponse(todo),
+		OccurredAt: time.Now().UTC(),
+	}
+
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		panic(err)
+	}
+
+	service.OutboxRepository.Save(ctx, tx, domain.OutboxMessage{
+		AggregateType: todoAggregate,
+		AggregateId:   todo.Id,
+		EventId:       evt.Id,
+		EventType:     evt.Type,
+		Payload:       string(payload),
+	})
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"todo-app-api/helper"
+	"todo-app-api/importer"
+	"todo-app-api/models/web"
+	"todo-app-api/tracing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	ImportResultCreated = "created"
+	ImportResultValid   = "valid"
+	ImportResultSkipped = "skipped"
+	ImportResultInvalid = "invalid"
+)
+
+// fieldProblems lists what is wrong with a request, one entry per field, in
+// the words of the TodoCreateRequest rules.
+func fieldProblems(err error) []string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+	for _, fieldError := range validationErrors {
+		rule := fieldError.Tag()
+		if fieldError.Param() != "" {
+			rule += "=" + fieldError.Param()
+		}
+		problems = append(problems, fmt.Sprintf("%s fails %q", strings.ToLower(fieldError.Field()), rule))
+	}
+	return problems
+}
+
+func (service *TodoServiceImpl) Import(ctx context.Context, request web.TodoImportRequest) web.TodoImportResponse {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoService.Import")
+	defer tracing.End(span)
+
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+
+	rows, err := importer.Parse(request.Format, []byte(request.Content), request.Mapping)
+	helper.PanicIfError(err)
+
+	committed := false
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx, func() {
+		if committed {
+			service.Replicas.Wrote(ctx)
+		}
+	})
+
+	// titles already taken, by a stored todo or an earlier row
+	taken := map[string]bool{}
+	checkDuplicates := request.Duplicates != "allow"
+	if checkDuplicates && len(rows) > 0 {
+		titles := make([]string, len(rows))
+		for i, row := range rows {
+			titles[i] = row.Todo.Title
+		}
+		for _, todo := range service.TodoRepository.FindByTitles(ctx, tx, titles) {
+			taken[strings.ToLower(todo.Title)] = true
+		}
+	}
+
+	response := web.TodoImportResponse{
+		DryRun: request.DryRun,
+		Total:  len(rows),
+		Rows:   make([]web.TodoImportRowResponse, len(rows)),
+	}
+	for i, row := range rows {
+		result := web.TodoImportRowResponse{Line: row.Line, Title: row.Todo.Title, Result: ImportResultValid}
+		key := strings.ToLower(row.Todo.Title)
+
+		switch {
+		case row.Error != "":
+			result.Result = ImportResultInvalid
+			result.Errors = []string{row.Error}
+		case row.Skip != "":
+			result.Result = ImportResultSkipped
+			result.Reason = row.Skip
+		default:
+			if err := service.Validate.Struct(row.Todo); err != nil {
+				result.Result = ImportResultInvalid
+				result.Errors = fieldProblems(err)
+			} else if checkDuplicates && taken[key] {
+				result.Result = ImportResultSkipped
+				result.Reason = "duplicate"
+			}
+		}
+
+		switch result.Result {
+		case ImportResultValid:
+			taken[key] = true
+		case ImportResultSkipped:
+			response.Skipped++
+		case ImportResultInvalid:
+			response.Failed++
+		}
+		response.Rows[i] = result
+	}
+
+	response.Committed = !request.DryRun && (request.Partial || response.Failed == 0)
+	if response.Committed {
+		committed = true
+		for i, row := range rows {
+			if response.Rows[i].Result != ImportResultValid {
+				continue
+			}
+			todo := service.create(ctx, tx, row.Todo)
+			response.Rows[i].Result = ImportResultCreated
+			response.Rows[i].TodoId = todo.Id
+			response.Imported++
+		}
+	}
+	helper.SetAuditTarget(ctx, "todo.import", "")
+
+	return response
+}
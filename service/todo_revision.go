@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"todo-app-api/exception"
+	"todo-app-api/helper"
+	"todo-app-api/models/domain"
+
+	"gorm.io/gorm"
+)
+
+const (
+	RevisionActionCreate = "create"
+	RevisionActionUpdate = "update"
+	RevisionActionDelete = "delete"
+	RevisionActionRevert = "revert"
+)
+
+func snapshotOf(todo domain.Todo) domain.TodoSnapshot {
+	return domain.TodoSnapshot{
+		Title:       todo.Title,
+		Description: todo.Description,
+		Status:      todo.Status,
+	}
+}
+
+func diffSnapshots(before domain.TodoSnapshot, after domain.TodoSnapshot) []domain.FieldChange {
+	changes := []domain.FieldChange{}
+	if before.Title != after.Title {
+		changes = append(changes, domain.FieldChange{Field: "title", Old: before.Title, New: after.Title})
+	}
+	if before.Description != after.Description {
+		changes = append(changes, domain.FieldChange{Field: "description", Old: before.Description, New: after.Description})
+	}
+	if before.Status != after.Status {
+		changes = append(changes, domain.FieldChange{Field: "status", Old: before.Status, New: after.Status})
+	}
+	return changes
+}
+
+// recordRevision appends a revision for todoId inside tx. before and after are
+// the todo state around the change; for deletions after is the zero snapshot
+// and the stored snapshot keeps the last known state so it can be restored.
+func (service *TodoServiceImpl) recordRevision(ctx context.Context, tx *gorm.DB, todoId int, action string, before domain.TodoSnapshot, after domain.TodoSnapshot) {
+	snapshot := after
+	if action == RevisionActionDelete {
+		snapshot = before
+	}
+
+	_, err := service.TodoRevisionRepository.Save(ctx, tx, domain.TodoRevision{
+		TodoId:   todoId,
+		Revision: service.TodoRevisionRepository.LastRevision(ctx, tx, todoId) + 1,
+		Action:   action,
+		Actor:    helper.ActorFromContext(ctx),
+		Changes:  diffSnapshots(before, after),
+		Snapshot: snapshot,
+	})
+	// writers of an existing todo hold its row lock, so only a write that had
+	// nothing to lock, like recreating a deleted todo, can lose this race
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		panic(exception.ConflictError{Message: "todo was changed concurrently, retry"})
+	}
+	if err != nil {
+		panic(err)
+	}
+}
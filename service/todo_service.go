@@ -3,12 +3,32 @@ package service
 import (
 	"context"
 	"todo-app-api/models/web"
+
+	"gorm.io/gorm"
 )
 
 type TodoService interface {
 	Create(context context.Context, request web.TodoCreateRequest) web.TodoResponse
+	// CreateWithin is Create inside the caller's transaction, so the todo
+	// commits or rolls back together with the caller's own rows.
+	CreateWithin(context context.Context, tx *gorm.DB, request web.TodoCreateRequest) web.TodoResponse
 	Update(context context.Context, request web.TodoUpdateRequest) web.TodoResponse
 	Delete(context context.Context, todoId int)
+	SetTask(context context.Context, request web.TodoTaskRequest) web.TodoResponse
 	FindById(context context.Context, todoId int) web.TodoResponse
-	FindAll(context context.Context) []web.TodoResponse
+	FindAll(context context.Context, request web.TodoFilterRequest) []web.TodoResponse
+	// Export validates request and returns a walk over the matching todos
+	// that reads them one row at a time. Unlike the other methods it reports
+	// database errors instead of panicking, as it usually runs after the
+	// response has started.
+	Export(context context.Context, request web.TodoFilterRequest) func(fn func(todo web.TodoResponse) bool) error
+	History(context context.Context, todoId int) []web.TodoRevisionResponse
+	Revert(context context.Context, todoId int, revision int) web.TodoResponse
+	// FindByIds and HistoryByTodoIds load many todos at once for batching
+	// callers; ids that do not exist are left out.
+	FindByIds(context context.Context, todoIds []int) []web.TodoResponse
+	// Import reads a file of todos and, unless it is a dry run, stores the
+	// valid ones in a single transaction.
+	Import(context context.Context, request web.TodoImportRequest) web.TodoImportResponse
+	HistoryByTodoIds(context context.Context, todoIds []int) map[int][]web.TodoRevisionResponse
 }
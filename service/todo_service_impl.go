@@ -3,37 +3,77 @@ package service
 import (
 	"context"
 	"errors"
+	"todo-app-api/event"
 	"todo-app-api/exception"
 	"todo-app-api/helper"
+	"todo-app-api/markdown"
 	"todo-app-api/models/domain"
 	"todo-app-api/models/web"
+	"todo-app-api/replica"
 	"todo-app-api/repository"
+	"todo-app-api/tracing"
 
 	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
 )
 
 type TodoServiceImpl struct {
-	TodoRepository repository.TodoRepository
-	DB             *gorm.DB
-	Validate       *validator.Validate
+	TodoRepository          repository.TodoRepository
+	TodoRevisionRepository  repository.TodoRevisionRepository
+	TodoReferenceRepository repository.TodoReferenceRepository
+	CommentRepository       repository.CommentRepository
+	OutboxRepository        repository.OutboxRepository
+	DB                      *gorm.DB
+	Replicas                *replica.Router
+	Validate                *validator.Validate
 }
 
-func NewTodoService(todoRepository repository.TodoRepository, DB *gorm.DB, validate *validator.Validate) TodoService {
+func NewTodoService(todoRepository repository.TodoRepository, todoRevisionRepository repository.TodoRevisionRepository, todoReferenceRepository repository.TodoReferenceRepository, commentRepository repository.CommentRepository, outboxRepository repository.OutboxRepository, DB *gorm.DB, replicas *replica.Router, validate *validator.Validate) TodoService {
 	return &TodoServiceImpl{
-		TodoRepository: todoRepository,
-		DB:             DB,
-		Validate:       validate,
+		TodoRepository:          todoRepository,
+		TodoRevisionRepository:  todoRevisionRepository,
+		TodoReferenceRepository: todoReferenceRepository,
+		CommentRepository:       commentRepository,
+		OutboxRepository:        outboxRepository,
+		DB:                      DB,
+		Replicas:                replicas,
+		Validate:                validate,
 	}
 }
 
 func (service *TodoServiceImpl) Create(ctx context.Context, request web.TodoCreateRequest) web.TodoResponse {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoService.Create")
+	defer tracing.End(span)
+
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx, service.pin(ctx))
+
+	return service.CreateWithin(ctx, tx, request)
+}
+
+// CreateWithin leaves pinning the client to the primary to the caller, as
+// only the caller knows when tx has committed.
+func (service *TodoServiceImpl) CreateWithin(ctx context.Context, tx *gorm.DB, request web.TodoCreateRequest) web.TodoResponse {
 	err := service.Validate.Struct(request)
 	helper.PanicIfError(err)
 
-	tx := service.DB.Begin()
-	defer helper.CommitOrRollback(tx)
+	todo := service.create(ctx, tx, request)
+	helper.SetAuditTarget(ctx, "todo.create", todoResource(todo.Id))
 
+	return helper.ToTodoResponse(todo)
+}
+
+// pin returns the afterCommit callback that keeps the client reading from the
+// primary until the replicas have caught up with its write. A rolled back
+// write pins nothing.
+func (service *TodoServiceImpl) pin(ctx context.Context) func() {
+	return func() {
+		service.Replicas.Wrote(ctx)
+	}
+}
+
+// create stores a validated request with its first revision and event.
+func (service *TodoServiceImpl) create(ctx context.Context, tx *gorm.DB, request web.TodoCreateRequest) domain.Todo {
 	todo := domain.Todo{
 		Title:       request.Title,
 		Description: request.Description,
@@ -44,19 +84,24 @@ func (service *TodoServiceImpl) Create(ctx context.Context, request web.TodoCrea
 		todo.Status = "pending"
 	}
 
-	service.TodoRepository.Save(ctx, tx, todo)
-
-	return helper.ToTodoResponse(todo)
+	todo = service.TodoRepository.Save(ctx, tx, todo)
+	service.recordRevision(ctx, tx, todo.Id, RevisionActionCreate, domain.TodoSnapshot{}, snapshotOf(todo))
+	service.indexReferences(ctx, tx, todo)
+	service.enqueueEvent(ctx, tx, event.TodoCreated, todo)
+	return todo
 }
 
 func (service *TodoServiceImpl) Update(ctx context.Context, request web.TodoUpdateRequest) web.TodoResponse {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoService.Update")
+	defer tracing.End(span)
+
 	err := service.Validate.Struct(request)
 	helper.PanicIfError(err)
 
 	tx := service.DB.Begin()
-	defer helper.CommitOrRollback(tx)
+	defer helper.CommitOrRollback(tx, service.pin(ctx))
 
-	todo, err := service.TodoRepository.FindById(ctx, tx, request.Id)
+	todo, err := service.TodoRepository.FindByIdForUpdate(ctx, tx, request.Id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			panic(exception.NotFoundError{Message: "todo not found"})
@@ -64,24 +109,79 @@ func (service *TodoServiceImpl) Update(ctx context.Context, request web.TodoUpda
 		panic(err)
 	}
 
+	before := snapshotOf(todo)
+
 	todo.Title = request.Title
 	todo.Description = request.Description
 	todo.Status = request.Status
 
-	todo = service.TodoRepository.Update(ctx, tx, todo)
+	todo = service.update(ctx, tx, todo, before)
+	helper.SetAuditTarget(ctx, "todo.update", todoResource(todo.Id))
 
+	return service.withCommentCount(ctx, tx, helper.ToTodoResponse(todo))
+}
+
+// update stores the changed todo with its revision and events.
+func (service *TodoServiceImpl) update(ctx context.Context, tx *gorm.DB, todo domain.Todo, before domain.TodoSnapshot) domain.Todo {
+	// v1 accepts an update without a status
 	if todo.Status == "" {
-		todo.Status = "pending" // default
+		todo.Status = "pending"
 	}
 
-	return helper.ToTodoResponse(todo)
+	todo = service.TodoRepository.Update(ctx, tx, todo)
+	service.recordRevision(ctx, tx, todo.Id, RevisionActionUpdate, before, snapshotOf(todo))
+	service.indexReferences(ctx, tx, todo)
+
+	service.enqueueEvent(ctx, tx, event.TodoUpdated, todo)
+	if before.Status != "done" && todo.Status == "done" {
+		service.enqueueEvent(ctx, tx, event.TodoCompleted, todo)
+	}
+	return todo
+}
+
+// SetTask checks or unchecks an item of the task list in the description by
+// rewriting its Markdown source, and stores it like any other update.
+func (service *TodoServiceImpl) SetTask(ctx context.Context, request web.TodoTaskRequest) web.TodoResponse {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoService.SetTask")
+	defer tracing.End(span)
+
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx, service.pin(ctx))
+
+	todo, err := service.TodoRepository.FindByIdForUpdate(ctx, tx, request.Id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			panic(exception.NotFoundError{Message: "todo not found"})
+		}
+		panic(err)
+	}
+
+	description, err := markdown.SetTask(todo.Description, request.Task, *request.Checked)
+	if err != nil {
+		panic(exception.NotFoundError{Message: "task not found"})
+	}
+
+	if description != todo.Description {
+		before := snapshotOf(todo)
+		todo.Description = description
+		todo = service.update(ctx, tx, todo, before)
+	}
+	helper.SetAuditTarget(ctx, "todo.task", todoResource(todo.Id))
+
+	return service.withCommentCount(ctx, tx, helper.ToTodoResponse(todo))
 }
 
 func (service *TodoServiceImpl) Delete(ctx context.Context, todoId int) {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoService.Delete")
+	defer tracing.End(span)
+
 	tx := service.DB.Begin()
-	defer helper.CommitOrRollback(tx)
+	defer helper.CommitOrRollback(tx, service.pin(ctx))
 
-	todo, err := service.TodoRepository.FindById(ctx, tx, todoId)
+	todo, err := service.TodoRepository.FindByIdForUpdate(ctx, tx, todoId)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			panic(exception.NotFoundError{Message: "todo not found"})
@@ -90,13 +190,22 @@ func (service *TodoServiceImpl) Delete(ctx context.Context, todoId int) {
 	}
 
 	service.TodoRepository.Delete(ctx, tx, todo)
+	service.recordRevision(ctx, tx, todo.Id, RevisionActionDelete, snapshotOf(todo), domain.TodoSnapshot{})
+	service.TodoReferenceRepository.Replace(ctx, tx, todo.Id, nil)
+	// comments go with the todo; reverting the deletion does not bring them back
+	service.CommentRepository.DeleteByTodoId(ctx, tx, todo.Id)
+	helper.SetAuditTarget(ctx, "todo.delete", todoResource(todo.Id))
+	service.enqueueEvent(ctx, tx, event.TodoDeleted, todo)
 }
 
+// FindById and FindAll run outside a transaction so they can be served by a
+// read replica.
 func (service *TodoServiceImpl) FindById(ctx context.Context, todoId int) web.TodoResponse {
-	tx := service.DB.Begin()
-	defer helper.CommitOrRollback(tx)
+	ctx, span := tracing.Tracer().Start(ctx, "TodoService.FindById")
+	defer tracing.End(span)
 
-	todo, err := service.TodoRepository.FindById(ctx, tx, todoId)
+	reader := service.Replicas.Reader(ctx)
+	todo, err := service.TodoRepository.FindById(ctx, reader, todoId)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			panic(exception.NotFoundError{Message: "todo not found"})
@@ -104,14 +213,168 @@ func (service *TodoServiceImpl) FindById(ctx context.Context, todoId int) web.To
 		panic(err)
 	}
 
-	return helper.ToTodoResponse(todo)
+	return service.withCommentCount(ctx, reader, helper.ToTodoResponse(todo))
+}
+
+func (service *TodoServiceImpl) FindAll(ctx context.Context, request web.TodoFilterRequest) []web.TodoResponse {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoService.FindAll")
+	defer tracing.End(span)
+
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+
+	reader := service.Replicas.Reader(ctx)
+	todos := service.TodoRepository.FindAll(ctx, reader, filterOf(request))
+
+	return service.withCommentCounts(ctx, reader, helper.ToTodoResponses(todos))
+}
+
+func (service *TodoServiceImpl) Export(ctx context.Context, request web.TodoFilterRequest) func(fn func(todo web.TodoResponse) bool) error {
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+
+	filter := filterOf(request)
+	return func(fn func(todo web.TodoResponse) bool) error {
+		ctx, span := tracing.Tracer().Start(ctx, "TodoService.Export")
+		defer tracing.End(span)
+
+		return service.TodoRepository.FindEach(ctx, service.Replicas.Reader(ctx), filter, func(todo domain.Todo) bool {
+			return fn(helper.ToTodoResponse(todo))
+		})
+	}
+}
+
+func filterOf(request web.TodoFilterRequest) domain.TodoFilter {
+	return domain.TodoFilter{
+		Status:  request.Status,
+		Mention: request.Mention,
+		Link:    request.Link,
+	}
+}
+
+func (service *TodoServiceImpl) FindByIds(ctx context.Context, todoIds []int) []web.TodoResponse {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoService.FindByIds")
+	defer tracing.End(span)
+
+	reader := service.Replicas.Reader(ctx)
+	todos := service.TodoRepository.FindByIds(ctx, reader, todoIds)
+
+	return service.withCommentCounts(ctx, reader, helper.ToTodoResponses(todos))
 }
 
-func (service *TodoServiceImpl) FindAll(ctx context.Context) []web.TodoResponse {
+func (service *TodoServiceImpl) HistoryByTodoIds(ctx context.Context, todoIds []int) map[int][]web.TodoRevisionResponse {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoService.HistoryByTodoIds")
+	defer tracing.End(span)
+
+	revisions := service.TodoRevisionRepository.FindByTodoIds(ctx, service.Replicas.Reader(ctx), todoIds)
+
+	histories := map[int][]web.TodoRevisionResponse{}
+	for _, revision := range revisions {
+		histories[revision.TodoId] = append(histories[revision.TodoId], helper.ToTodoRevisionResponse(revision))
+	}
+	return histories
+}
+
+func (service *TodoServiceImpl) History(ctx context.Context, todoId int) []web.TodoRevisionResponse {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoService.History")
+	defer tracing.End(span)
+
 	tx := service.DB.Begin()
 	defer helper.CommitOrRollback(tx)
 
-	todos := service.TodoRepository.FindAll(ctx, tx)
+	revisions := service.TodoRevisionRepository.FindByTodoId(ctx, tx, todoId)
+	if len(revisions) == 0 {
+		panic(exception.NotFoundError{Message: "todo history not found"})
+	}
+
+	return helper.ToTodoRevisionResponses(revisions)
+}
+
+func (service *TodoServiceImpl) Revert(ctx context.Context, todoId int, revision int) web.TodoResponse {
+	ctx, span := tracing.Tracer().Start(ctx, "TodoService.Revert")
+	defer tracing.End(span)
+
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx, service.pin(ctx))
+
+	// a deleted todo is recreated under its original id
+	todo, err := service.TodoRepository.FindByIdForUpdate(ctx, tx, todoId)
+	exists := true
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			panic(err)
+		}
+		exists = false
+		todo = domain.Todo{Id: todoId}
+	}
+
+	target, err := service.TodoRevisionRepository.FindByRevision(ctx, tx, todoId, revision)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			panic(exception.NotFoundError{Message: "todo revision not found"})
+		}
+		panic(err)
+	}
+
+	before := domain.TodoSnapshot{}
+	if exists {
+		before = snapshotOf(todo)
+	}
+
+	todo.Title = target.Snapshot.Title
+	todo.Description = target.Snapshot.Description
+	todo.Status = target.Snapshot.Status
+	// revisions written before the status was defaulted on update have none
+	if todo.Status == "" {
+		todo.Status = "pending"
+	}
+
+	if exists {
+		todo = service.TodoRepository.Update(ctx, tx, todo)
+	} else {
+		todo = service.TodoRepository.Save(ctx, tx, todo)
+	}
+	service.recordRevision(ctx, tx, todoId, RevisionActionRevert, before, snapshotOf(todo))
+	service.indexReferences(ctx, tx, todo)
+	helper.SetAuditTarget(ctx, "todo.revert", todoResource(todoId))
+	if exists {
+		service.enqueueEvent(ctx, tx, event.TodoUpdated, todo)
+	} else {
+		service.enqueueEvent(ctx, tx, event.TodoCreated, todo)
+	}
+
+	return service.withCommentCount(ctx, tx, helper.ToTodoResponse(todo))
+}
+
+// indexReferences keeps the links and mentions of the description
+// searchable.
+func (service *TodoServiceImpl) indexReferences(ctx context.Context, tx *gorm.DB, todo domain.Todo) {
+	var references []domain.TodoReference
+	for _, link := range markdown.Links(todo.Description) {
+		references = append(references, domain.TodoReference{Kind: domain.TodoReferenceLink, Value: link})
+	}
+	for _, mention := range markdown.Mentions(todo.Description) {
+		references = append(references, domain.TodoReference{Kind: domain.TodoReferenceMention, Value: mention})
+	}
+	service.TodoReferenceRepository.Replace(ctx, tx, todo.Id, references)
+}
+
+// withCommentCounts fills in how many comments each todo has.
+func (service *TodoServiceImpl) withCommentCounts(ctx context.Context, tx *gorm.DB, todos []web.TodoResponse) []web.TodoResponse {
+	if len(todos) == 0 {
+		return todos
+	}
+	todoIds := make([]int, len(todos))
+	for i, todo := range todos {
+		todoIds[i] = todo.Id
+	}
+	counts := service.CommentRepository.CountByTodoIds(ctx, tx, todoIds)
+	for i := range todos {
+		todos[i].CommentCount = counts[todos[i].Id]
+	}
+	return todos
+}
 
-	return helper.ToTodoResponses(todos)
+func (service *TodoServiceImpl) withCommentCount(ctx context.Context, tx *gorm.DB, todo web.TodoResponse) web.TodoResponse {
+	return service.withCommentCounts(ctx, tx, []web.TodoResponse{todo})[0]
 }
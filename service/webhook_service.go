@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+	"todo-app-api/models/web"
+)
+
+type WebhookService interface {
+	Create(context context.Context, request web.WebhookCreateRequest) web.WebhookResponse
+	Update(context context.Context, request web.WebhookUpdateRequest) web.WebhookResponse
+	Delete(context context.Context, webhookId int)
+	FindById(context context.Context, webhookId int) web.WebhookResponse
+	FindAll(context context.Context) []web.WebhookResponse
+	Deliveries(context context.Context, webhookId int) []web.WebhookDeliveryResponse
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"todo-app-api/exception"
+	"todo-app-api/helper"
+	"todo-app-api/models/domain"
+	"todo-app-api/models/web"
+	"todo-app-api/repository"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type WebhookServiceImpl struct {
+	SubscriptionRepository repository.WebhookSubscriptionRepository
+	DeliveryRepository     repository.WebhookDeliveryRepository
+	DB                     *gorm.DB
+	Validate               *validator.Validate
+}
+
+func NewWebhookService(subscriptionRepository repository.WebhookSubscriptionRepository, deliveryRepository repository.WebhookDeliveryRepository, DB *gorm.DB, validate *validator.Validate) WebhookService {
+	return &WebhookServiceImpl{
+		SubscriptionRepository: subscriptionRepository,
+		DeliveryRepository:     deliveryRepository,
+		DB:                     DB,
+		Validate:               validate,
+	}
+}
+
+func (service *WebhookServiceImpl) findSubscription(ctx context.Context, tx *gorm.DB, webhookId int) domain.WebhookSubscription {
+	subscription, err := service.SubscriptionRepository.FindById(ctx, tx, webhookId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			panic(exception.NotFoundError{Message: "webhook not found"})
+		}
+		panic(err)
+	}
+	return subscription
+}
+
+func (service *WebhookServiceImpl) Create(ctx context.Context, request web.WebhookCreateRequest) web.WebhookResponse {
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	subscription := service.SubscriptionRepository.Save(ctx, tx, domain.WebhookSubscription{
+		Url:    request.Url,
+		Secret: request.Secret,
+		Events: request.Events,
+		Active: true,
+	})
+	helper.SetAuditTarget(ctx, "webhook.create", webhookResource(subscription.Id))
+
+	return helper.ToWebhookResponse(subscription)
+}
+
+func (service *WebhookServiceImpl) Update(ctx context.Context, request web.WebhookUpdateRequest) web.WebhookResponse {
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	subscription := service.findSubscription(ctx, tx, request.Id)
+	subscription.Url = request.Url
+	subscription.Events = request.Events
+	subscription.Active = request.Active
+	if request.Secret != "" {
+		subscription.Secret = request.Secret
+	}
+
+	subscription = service.SubscriptionRepository.Update(ctx, tx, subscription)
+	helper.SetAuditTarget(ctx, "webhook.update", webhookResource(subscription.Id))
+
+	return helper.ToWebhookResponse(subscription)
+}
+
+func (service *WebhookServiceImpl) Delete(ctx context.Context, webhookId int) {
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	subscription := service.findSubscription(ctx, tx, webhookId)
+	service.SubscriptionRepository.Delete(ctx, tx, subscription)
+	helper.SetAuditTarget(ctx, "webhook.delete", webhookResource(subscription.Id))
+}
+
+func (service *WebhookServiceImpl) FindById(ctx context.Context, webhookId int) web.WebhookResponse {
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	return helper.ToWebhookResponse(service.findSubscription(ctx, tx, webhookId))
+}
+
+func (service *WebhookServiceImpl) FindAll(ctx context.Context) []web.WebhookResponse {
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	subscriptions := service.SubscriptionRepository.FindAll(ctx, tx)
+
+	return helper.ToWebhookResponses(subscriptions)
+}
+
+func (service *WebhookServiceImpl) Deliveries(ctx context.Context, webhookId int) []web.WebhookDeliveryResponse {
+	tx := service.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	subscription := service.findSubscription(ctx, tx, webhookId)
+	deliveries := service.DeliveryRepository.FindBySubscriptionId(ctx, tx, subscription.Id)
+
+	return helper.ToWebhookDeliveryResponses(deliveries)
+}
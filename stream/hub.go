@@ -0,0 +1,151 @@
+package stream
+
+import (
+	"context"
+	"sync"
+	"todo-app-api/event"
+)
+
+// Message is an event tagged with its outbox sequence, which clients send
+// back as Last-Event-ID to resume a stream.
+type Message struct {
+	Id    uint64
+	Event event.Event
+}
+
+type Subscription struct {
+	Messages <-chan Message
+	// Lagged is closed when the subscriber fell too far behind and was
+	// dropped; the client should reconnect with its last seen id.
+	Lagged <-chan struct{}
+
+	messages chan Message
+	lagged   chan struct{}
+	filter   func(evt event.Event) bool
+	once     sync.Once
+}
+
+func (subscription *Subscription) drop() {
+	subscription.once.Do(func() { close(subscription.lagged) })
+}
+
+// Hub keeps the most recent events in a ring buffer and fans new ones out to
+// live subscribers without ever blocking the publisher.
+type Hub struct {
+	mutex       sync.Mutex
+	history     []Message
+	historySize int
+	bufferSize  int
+	subscribers map[*Subscription]struct{}
+}
+
+func NewHub(historySize int, bufferSize int) *Hub {
+	return &Hub{
+		historySize: historySize,
+		bufferSize:  bufferSize,
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+func (hub *Hub) Publish(ctx context.Context, evt event.Event) error {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	// the relay delivers at least once; a redelivered event is not sent twice
+	if evt.Sequence > 0 && hub.indexOf(evt.Sequence) >= 0 {
+		return nil
+	}
+	message := Message{Id: evt.Sequence, Event: evt}
+
+	hub.history = append(hub.history, message)
+	if len(hub.history) > hub.historySize {
+		hub.history = hub.history[len(hub.history)-hub.historySize:]
+	}
+
+	for subscription := range hub.subscribers {
+		if subscription.filter != nil && !subscription.filter(evt) {
+			continue
+		}
+		select {
+		case subscription.messages <- message:
+		default:
+			// slow consumer: drop it instead of stalling everyone else
+			delete(hub.subscribers, subscription)
+			subscription.drop()
+		}
+	}
+	return nil
+}
+
+// Subscribe registers a subscriber that receives every event published after
+// lastEventId. Sequences are not published in order across todos, so the
+// backlog is taken by position in the history; complete is false when
+// lastEventId is not in the history any more, in which case the client has to
+// refetch its state.
+func (hub *Hub) Subscribe(lastEventId uint64, filter func(evt event.Event) bool) (subscription *Subscription, backlog []Message, complete bool) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	messages := make(chan Message, hub.bufferSize)
+	lagged := make(chan struct{})
+	subscription = &Subscription{
+		Messages: messages,
+		Lagged:   lagged,
+		messages: messages,
+		lagged:   lagged,
+		filter:   filter,
+	}
+	hub.subscribers[subscription] = struct{}{}
+
+	complete = true
+	if lastEventId > 0 {
+		history := hub.history
+		if index := hub.indexOf(lastEventId); index >= 0 {
+			history = history[index+1:]
+		} else {
+			complete = false
+		}
+		for _, message := range history {
+			if (complete || message.Id > lastEventId) && (filter == nil || filter(message.Event)) {
+				backlog = append(backlog, message)
+			}
+		}
+	}
+
+	return subscription, backlog, complete
+}
+
+func (hub *Hub) indexOf(id uint64) int {
+	for i := len(hub.history) - 1; i >= 0; i-- {
+		if hub.history[i].Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (hub *Hub) Unsubscribe(subscription *Subscription) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	delete(hub.subscribers, subscription)
+}
+
+func (hub *Hub) Subscribers() int {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	return len(hub.subscribers)
+}
+
+// Close ends every live subscription; clients are told to reset and will
+// reconnect, typically to another instance.
+func (hub *Hub) Close() {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	for subscription := range hub.subscribers {
+		delete(hub.subscribers, subscription)
+		subscription.drop()
+	}
+}
@@ -0,0 +1,146 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+	"todo-app-api/event"
+	"todo-app-api/models/domain"
+	"todo-app-api/repository"
+
+	"gorm.io/gorm"
+)
+
+// maxGap bounds the ids tracked for one jump of the cursor; a jump larger
+// than that comes from the id sequence, not from transactions in flight.
+const maxGap = 1000
+
+// Tailer feeds a Hub from the outbox table. Every instance runs its own, so
+// the clients of every instance see every event, with the same ids, no
+// matter which instance's relay published it.
+//
+// Outbox ids are handed out when a transaction writes its message, not when
+// it commits, so a lower id can become visible after a higher one. The ids
+// skipped that way are looked up again on every poll until they show up or
+// GapTimeout has passed, after which the transaction is taken to have rolled
+// back.
+type Tailer struct {
+	OutboxRepository repository.OutboxRepository
+	DB               *gorm.DB
+	Hub              *Hub
+	PollInterval     time.Duration
+	BatchSize        int
+	// Backfill is how many past events are loaded into the hub on start, so
+	// clients can resume across a restart or a move to another instance.
+	Backfill   int
+	GapTimeout time.Duration
+	Now        func() time.Time
+
+	started bool
+	cursor  int
+	gaps    map[int]time.Time
+	wake    chan struct{}
+}
+
+func NewTailer(outboxRepository repository.OutboxRepository, db *gorm.DB, hub *Hub) *Tailer {
+	return &Tailer{
+		OutboxRepository: outboxRepository,
+		DB:               db,
+		Hub:              hub,
+		PollInterval:     500 * time.Millisecond,
+		BatchSize:        100,
+		Backfill:         hub.historySize,
+		GapTimeout:       time.Minute,
+		Now:              time.Now,
+		gaps:             map[int]time.Time{},
+		wake:             make(chan struct{}, 1),
+	}
+}
+
+// Publish makes the next poll happen right away. Subscribed to the relay's
+// bus, it keeps events written through this instance as quick to arrive as
+// if the hub took them from the relay.
+func (tailer *Tailer) Publish(ctx context.Context, evt event.Event) error {
+	select {
+	case tailer.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (tailer *Tailer) Run(ctx context.Context) {
+	ticker := time.NewTicker(tailer.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for tailer.Poll(ctx) >= tailer.BatchSize {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-tailer.wake:
+		}
+	}
+}
+
+// Poll hands the messages committed since the last poll to the hub and
+// returns how many were read past the cursor.
+func (tailer *Tailer) Poll(ctx context.Context) int {
+	if !tailer.started {
+		tailer.started = true
+		messages := tailer.OutboxRepository.FindLatest(ctx, tailer.DB, tailer.Backfill)
+		if len(messages) > 0 {
+			tailer.cursor = messages[0].Id - 1
+		}
+		tailer.publish(ctx, messages)
+		return len(messages)
+	}
+
+	now := tailer.Now()
+	if len(tailer.gaps) > 0 {
+		gapIds := make([]int, 0, len(tailer.gaps))
+		for id, seenAt := range tailer.gaps {
+			if now.Sub(seenAt) > tailer.GapTimeout {
+				delete(tailer.gaps, id)
+				continue
+			}
+			gapIds = append(gapIds, id)
+		}
+		if len(gapIds) > 0 {
+			filled := tailer.OutboxRepository.FindByIds(ctx, tailer.DB, gapIds)
+			for _, message := range filled {
+				delete(tailer.gaps, message.Id)
+			}
+			tailer.publish(ctx, filled)
+		}
+	}
+
+	messages := tailer.OutboxRepository.FindAfter(ctx, tailer.DB, tailer.cursor, tailer.BatchSize)
+	tailer.publish(ctx, messages)
+	return len(messages)
+}
+
+// publish moves the cursor past messages, which are ordered by id, noting the
+// ids skipped on the way.
+func (tailer *Tailer) publish(ctx context.Context, messages []domain.OutboxMessage) {
+	now := tailer.Now()
+	for _, message := range messages {
+		if message.Id > tailer.cursor {
+			for id := max(tailer.cursor+1, message.Id-maxGap); id < message.Id; id++ {
+				tailer.gaps[id] = now
+			}
+			tailer.cursor = message.Id
+		}
+
+		var evt event.Event
+		if err := json.Unmarshal([]byte(message.Payload), &evt); err != nil {
+			slog.Error("stream: undecodable outbox message", "outbox_id", message.Id, "error", err)
+			continue
+		}
+		evt.Sequence = uint64(message.Id)
+		tailer.Hub.Publish(ctx, evt)
+	}
+}
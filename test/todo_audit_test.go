@@ -0,0 +1,196 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-app-api/command"
+	"todo-app-api/config"
+	"todo-app-api/controller"
+	"todo-app-api/exception"
+	"todo-app-api/graph"
+	"todo-app-api/lifecycle"
+	"todo-app-api/middleware"
+	"todo-app-api/models/domain"
+	"todo-app-api/models/web"
+	"todo-app-api/openapi"
+	"todo-app-api/replica"
+	"todo-app-api/repository"
+	"todo-app-api/routes"
+	"todo-app-api/service"
+	"todo-app-api/stream"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupAuditApp(t *testing.T) (*fiber.App, *gorm.DB, service.AuditService) {
+	db := setupTestDB(t)
+	validate := validator.New()
+
+	auditService := service.NewAuditService(repository.NewAuditLogRepository(db), db, validate)
+	todoService := service.NewTodoService(repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db), repository.NewTodoReferenceRepository(db), repository.NewCommentRepository(db), repository.NewOutboxRepository(db), db, replica.NewRouter(db, nil), validate)
+	webhookService := service.NewWebhookService(repository.NewWebhookSubscriptionRepository(db), repository.NewWebhookDeliveryRepository(db), db, validate)
+	calendarService := service.NewCalendarService(repository.NewCalendarTokenRepository(db), repository.NewCalendarObjectRepository(db), repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db), todoService, db, validate)
+	commentService := service.NewCommentService(repository.NewCommentRepository(db), repository.NewCommentEditRepository(db), repository.NewCommentReactionRepository(db), repository.NewTodoRepository(db), db, validate)
+
+	document := routes.OpenAPI("test")
+	apiValidator, err := openapi.NewValidator(document)
+	assert.NoError(t, err)
+
+	graphqlExecutor, err := graph.NewExecutor(todoService, stream.NewHub(10, 10), graph.Limits{MaxDepth: 8, MaxComplexity: 1000, MaxIntrospectionDepth: 15, MaxIntrospectionComplexity: 50000})
+	assert.NoError(t, err)
+
+	app := fiber.New(fiber.Config{ErrorHandler: exception.NewErrorHandler})
+	app.Use(recover.New())
+	app.Use(middleware.APIVersion(routes.Resources, config.Date("2026-11-01"), config.Date("2027-05-01")))
+	app.Use(middleware.Actor())
+	app.Use(middleware.Audit(auditService))
+	app.Use(middleware.Validate(apiValidator, true))
+	routes.NewRouter(app, controller.NewTodoController(todoService), controller.NewAuditController(auditService), controller.NewWebhookController(webhookService), controller.NewStreamController(stream.NewHub(10, 10), time.Second), controller.NewHealthController(setupHealthService(t, db, lifecycle.New())), controller.NewDocsController(document), controller.NewGraphQLController(graphqlExecutor, false), controller.NewCalendarController(calendarService), controller.NewCommentController(commentService))
+
+	return app, db, auditService
+}
+
+func TestAuditRecordsMutations(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+
+	body, _ := json.Marshal(web.TodoCreateRequest{Title: "Audit me", Description: "d"})
+	request := httptest.NewRequest(http.MethodPost, "/todos", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Actor", "bob")
+	request.Header.Set("X-Request-ID", "req-1")
+	response, _ := app.Test(request, -1)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// validation failure panics inside the service and must still be audited
+	body, _ = json.Marshal(web.TodoCreateRequest{Title: ""})
+	request = httptest.NewRequest(http.MethodPost, "/todos", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response, _ = app.Test(request, -1)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	// reads are not audited
+	response, _ = app.Test(httptest.NewRequest(http.MethodGet, "/todos", nil), -1)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var auditLogs []domain.AuditLog
+	db.Order("id ASC").Find(&auditLogs)
+	assert.Len(t, auditLogs, 2)
+
+	assert.Equal(t, "bob", auditLogs[0].Actor)
+	assert.Equal(t, "req-1", auditLogs[0].RequestId)
+	assert.Equal(t, "todo.create", auditLogs[0].Action)
+	assert.Equal(t, "todo:1", auditLogs[0].Resource)
+	assert.Equal(t, "success", auditLogs[0].Outcome)
+
+	assert.Equal(t, "failure", auditLogs[1].Outcome)
+	assert.Equal(t, "POST /v1/todos", auditLogs[1].Action)
+	assert.Equal(t, http.StatusBadRequest, auditLogs[1].StatusCode)
+	assert.Equal(t, auditLogs[0].Hash, auditLogs[1].PrevHash)
+}
+
+func TestAuditFindAllFiltersAndPaginates(t *testing.T) {
+	app, _, auditService := setupAuditApp(t)
+	ctx := context.Background()
+
+	auditService.Record(ctx, domain.AuditLog{Actor: "alice", Action: "todo.create", Outcome: "success", StatusCode: 200})
+	auditService.Record(ctx, domain.AuditLog{Actor: "bob", Action: "todo.create", Outcome: "success", StatusCode: 200})
+	auditService.Record(ctx, domain.AuditLog{Actor: "alice", Action: "todo.delete", Outcome: "failure", StatusCode: 404})
+
+	response, _ := app.Test(httptest.NewRequest(http.MethodGet, "/audit?actor=alice&size=1", nil), -1)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var page struct {
+		Data struct {
+			Items []web.AuditLogResponse `json:"items"`
+			Total int64                  `json:"total"`
+		}
+	}
+	json.NewDecoder(response.Body).Decode(&page)
+	assert.EqualValues(t, 2, page.Data.Total)
+	assert.Len(t, page.Data.Items, 1)
+	assert.Equal(t, "todo.delete", page.Data.Items[0].Action)
+
+	response, _ = app.Test(httptest.NewRequest(http.MethodGet, "/audit?outcome=maybe", nil), -1)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestAuditVerifyDetectsTampering(t *testing.T) {
+	_, db, auditService := setupAuditApp(t)
+	ctx := context.Background()
+
+	auditService.Record(ctx, domain.AuditLog{Actor: "alice", Action: "todo.create", Outcome: "success", StatusCode: 200})
+	auditService.Record(ctx, domain.AuditLog{Actor: "alice", Action: "todo.update", Outcome: "success", StatusCode: 200})
+
+	var out bytes.Buffer
+	assert.NoError(t, command.AuditVerify(ctx, auditService, &out))
+	assert.Contains(t, out.String(), "2 entries verified")
+
+	db.Model(&domain.AuditLog{}).Where("id = ?", 1).Update("actor", "mallory")
+
+	result := auditService.Verify(ctx)
+	assert.False(t, result.Valid)
+	assert.Equal(t, 1, result.BrokenAt)
+	assert.Error(t, command.AuditVerify(ctx, auditService, &out))
+}
+
+// staleAuditLogRepository sees the chain as another instance did before the
+// last append.
+type staleAuditLogRepository struct {
+	repository.AuditLogRepository
+	stale bool
+}
+
+func (repository *staleAuditLogRepository) FindLast(ctx context.Context, tx *gorm.DB) (domain.AuditLog, error) {
+	last, err := repository.AuditLogRepository.FindLast(ctx, tx)
+	if repository.stale {
+		repository.stale = false
+		return domain.AuditLog{Hash: last.PrevHash}, err
+	}
+	return last, err
+}
+
+func TestAuditRecordDoesNotForkTheChain(t *testing.T) {
+	_, db, auditService := setupAuditApp(t)
+	ctx := context.Background()
+	auditService.Record(ctx, domain.AuditLog{Actor: "alice", Action: "todo.create", Outcome: "success", StatusCode: 200})
+
+	stale := &staleAuditLogRepository{AuditLogRepository: repository.NewAuditLogRepository(db), stale: true}
+	otherInstance := service.NewAuditService(stale, db, validator.New())
+	otherInstance.Record(ctx, domain.AuditLog{Actor: "bob", Action: "todo.update", Outcome: "success", StatusCode: 200})
+
+	assert.False(t, stale.stale)
+	result := auditService.Verify(ctx)
+	assert.True(t, result.Valid)
+	assert.Equal(t, 2, result.Entries)
+}
+
+func TestAuditHashSeparatesFields(t *testing.T) {
+	_, db, auditService := setupAuditApp(t)
+	ctx := context.Background()
+
+	// written before the hash encoding was versioned
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	payload := "|alice||||||todo.create||success|200|" + createdAt.Format(time.RFC3339Nano)
+	sum := sha256.Sum256([]byte(payload))
+	db.Create(&domain.AuditLog{Actor: "alice", Action: "todo.create", Outcome: "success", StatusCode: 200, Hash: hex.EncodeToString(sum[:]), HashVersion: 1, CreatedAt: createdAt})
+
+	auditService.Record(ctx, domain.AuditLog{Actor: "bob", Ip: "10.0.0.1|x", Action: "todo.update", Outcome: "success", StatusCode: 200})
+	assert.True(t, auditService.Verify(ctx).Valid)
+
+	// a "|" moved into the neighbouring field changes the hash
+	db.Model(&domain.AuditLog{}).Where("id = ?", 2).Updates(map[string]interface{}{"ip": "10.0.0.1", "user_agent": "x|"})
+	result := auditService.Verify(ctx)
+	assert.False(t, result.Valid)
+	assert.Equal(t, 2, result.BrokenAt)
+}
@@ -0,0 +1,265 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app-api/ical"
+	"todo-app-api/models/domain"
+	"todo-app-api/models/web"
+	"todo-app-api/replica"
+	"todo-app-api/repository"
+	"todo-app-api/service"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func createCalendarToken(t *testing.T, app *fiber.App, actor string) (int, web.CalendarTokenResponse) {
+	request := httptest.NewRequest(http.MethodPost, "/calendar/tokens", nil)
+	if actor != "" {
+		request.Header.Set("X-Actor", actor)
+	}
+	response, err := app.Test(request, -1)
+	assert.NoError(t, err)
+
+	var envelope struct {
+		Data web.CalendarTokenResponse `json:"data"`
+	}
+	json.NewDecoder(response.Body).Decode(&envelope)
+	return response.StatusCode, envelope.Data
+}
+
+func getFeed(t *testing.T, app *fiber.App, token string) (*http.Response, string) {
+	response, err := app.Test(httptest.NewRequest(http.MethodGet, "/calendar/todos.ics?token="+token, nil), -1)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(response.Body)
+	return response, string(body)
+}
+
+func uploadCalendar(t *testing.T, app *fiber.App, token string, content string) (int, web.CalendarUploadResponse) {
+	request := httptest.NewRequest(http.MethodPost, "/calendar/todos.ics?token="+token, strings.NewReader(content))
+	request.Header.Set("Content-Type", "text/calendar")
+	response, err := app.Test(request, -1)
+	assert.NoError(t, err)
+
+	var envelope struct {
+		Data web.CalendarUploadResponse `json:"data"`
+	}
+	json.NewDecoder(response.Body).Decode(&envelope)
+	return response.StatusCode, envelope.Data
+}
+
+func TestCalendarFeedRendersTodos(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+	todoRepository := repository.NewTodoRepository(db)
+	todoRepository.Save(context.Background(), db, domain.Todo{Title: "Buy milk", Description: "Two litres; semi-skimmed, please", Status: "pending"})
+	todoRepository.Save(context.Background(), db, domain.Todo{Title: "Ship it", Description: "", Status: "done"})
+
+	status, _ := createCalendarToken(t, app, "")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, token := createCalendarToken(t, app, "alice")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "alice", token.Actor)
+	assert.Equal(t, "/calendar/todos.ics?token="+token.Token, token.FeedPath)
+
+	var stored domain.CalendarToken
+	db.First(&stored)
+	assert.NotEqual(t, token.Token, stored.TokenHash)
+
+	response, feed := getFeed(t, app, token.Token)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/calendar; charset=utf-8", response.Header.Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(feed, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.Contains(t, feed, "UID:todo-1@todo-app-api\r\n")
+	assert.Contains(t, feed, "SUMMARY:Buy milk\r\n")
+	assert.Contains(t, feed, `DESCRIPTION:Two litres\; semi-skimmed\, please`)
+	assert.Contains(t, feed, "STATUS:NEEDS-ACTION\r\n")
+	assert.Contains(t, feed, "STATUS:COMPLETED\r\n")
+
+	calendar, err := ical.Decode(strings.NewReader(feed))
+	assert.NoError(t, err)
+	vtodos := calendar.Children("VTODO")
+	if assert.Len(t, vtodos, 2) {
+		assert.Equal(t, "Two litres; semi-skimmed, please", vtodos[0].Text("DESCRIPTION"))
+		assert.Equal(t, "0", vtodos[0].Text("SEQUENCE"))
+		_, hasCompleted := vtodos[1].Get("COMPLETED")
+		assert.True(t, hasCompleted)
+	}
+
+	// the feed only changes with the todos
+	_, again := getFeed(t, app, token.Token)
+	assert.Equal(t, feed, again)
+
+	response, _ = getFeed(t, app, "guessed")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	response, _ = getFeed(t, app, "")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	// only the owner revokes a token
+	request := httptest.NewRequest(http.MethodDelete, "/calendar/tokens/1", nil)
+	request.Header.Set("X-Actor", "mallory")
+	deleteResponse, _ := app.Test(request, -1)
+	assert.Equal(t, http.StatusNotFound, deleteResponse.StatusCode)
+
+	request.Header.Set("X-Actor", "alice")
+	deleteResponse, _ = app.Test(request, -1)
+	assert.Equal(t, http.StatusOK, deleteResponse.StatusCode)
+	response, _ = getFeed(t, app, token.Token)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	// the token stays out of the audit log
+	var auditLogs []domain.AuditLog
+	db.Find(&auditLogs)
+	for _, auditLog := range auditLogs {
+		assert.NotContains(t, auditLog.Path, token.Token)
+	}
+}
+
+func TestCalendarUploadRoundTrips(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+	_, token := createCalendarToken(t, app, "alice")
+
+	upload := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Example//Tasks//EN\r\n" +
+		"BEGIN:VTODO\r\nUID:3f2a@example.com\r\nSEQUENCE:0\r\nSUMMARY:Water the plants\r\n" +
+		"DESCRIPTION:The ones on the\r\n  balcony too\r\nSTATUS:NEEDS-ACTION\r\nDUE:20261101T090000Z\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:no-summary@example.com\r\nDESCRIPTION:d\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nSUMMARY:No UID\r\nDESCRIPTION:d\r\nEND:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	status, report := uploadCalendar(t, app, token.Token, upload)
+	assert.Equal(t, http.StatusOK, status)
+	if assert.Len(t, report.Results, 3) {
+		assert.Equal(t, web.CalendarUploadResult{Uid: "3f2a@example.com", Result: "created", TodoId: 1}, report.Results[0])
+		assert.Equal(t, "invalid", report.Results[1].Result)
+		assert.Equal(t, []string{`title fails "required"`}, report.Results[1].Errors)
+		assert.Equal(t, []string{"UID is required"}, report.Results[2].Errors)
+	}
+
+	todo, _ := repository.NewTodoRepository(db).FindById(context.Background(), db, 1)
+	assert.Equal(t, "The ones on the balcony too", todo.Description)
+	var revision domain.TodoRevision
+	db.First(&revision)
+	assert.Equal(t, "alice", revision.Actor)
+
+	// the same upload again changes nothing
+	_, report = uploadCalendar(t, app, token.Token, upload)
+	assert.Equal(t, "unchanged", report.Results[0].Result)
+	assert.Equal(t, 1, report.Results[0].TodoId)
+
+	// the feed keeps the UID the calendar app chose
+	_, feed := getFeed(t, app, token.Token)
+	assert.Contains(t, feed, "UID:3f2a@example.com\r\n")
+	assert.NotContains(t, feed, "DUE")
+
+	completed := strings.Replace(upload, "STATUS:NEEDS-ACTION", "STATUS:COMPLETED", 1)
+	_, report = uploadCalendar(t, app, token.Token, completed)
+	assert.Equal(t, web.CalendarUploadResult{Uid: "3f2a@example.com", Result: "updated", TodoId: 1, Sequence: 1}, report.Results[0])
+	todo, _ = repository.NewTodoRepository(db).FindById(context.Background(), db, 1)
+	assert.Equal(t, "done", todo.Status)
+
+	// an edit based on the copy before the completion is stale
+	renamed := strings.Replace(upload, "SUMMARY:Water the plants", "SUMMARY:Water the cactus", 1)
+	_, report = uploadCalendar(t, app, token.Token, renamed)
+	assert.Equal(t, "stale", report.Results[0].Result)
+	assert.Equal(t, 1, report.Results[0].Sequence)
+
+	// todos created through the API are matched by the UID of the feed
+	body, _ := json.Marshal(web.TodoCreateRequest{Title: "From the API", Description: "d"})
+	request := httptest.NewRequest(http.MethodPost, "/todos", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	app.Test(request, -1)
+	_, feed = getFeed(t, app, token.Token)
+	calendar, _ := ical.Decode(strings.NewReader(feed))
+	var buffer bytes.Buffer
+	ical.Encode(&buffer, calendar)
+	_, report = uploadCalendar(t, app, token.Token, buffer.String())
+	if assert.Len(t, report.Results, 2) {
+		assert.Equal(t, "unchanged", report.Results[0].Result)
+		assert.Equal(t, web.CalendarUploadResult{Uid: "todo-2@todo-app-api", Result: "unchanged", TodoId: 2}, report.Results[1])
+	}
+
+	var todos int64
+	db.Model(&domain.Todo{}).Count(&todos)
+	assert.Equal(t, int64(2), todos)
+
+	status, _ = uploadCalendar(t, app, token.Token, "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\n")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = uploadCalendar(t, app, "guessed", upload)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+// racingCalendarObjectRepository misses the UID mapping on the first lookup,
+// as an upload does that races another one of the same VTODO.
+type racingCalendarObjectRepository struct {
+	repository.CalendarObjectRepository
+	missed bool
+}
+
+func (r *racingCalendarObjectRepository) FindByUid(ctx context.Context, tx *gorm.DB, uid string) (domain.CalendarObject, error) {
+	if !r.missed {
+		r.missed = true
+		return domain.CalendarObject{}, gorm.ErrRecordNotFound
+	}
+	return r.CalendarObjectRepository.FindByUid(ctx, tx, uid)
+}
+
+func TestCalendarUploadCreatesATodoOncePerUid(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+	_, token := createCalendarToken(t, app, "alice")
+	upload := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VTODO\r\nUID:race@example.com\r\nSUMMARY:Once\r\nDESCRIPTION:d\r\nEND:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	_, report := uploadCalendar(t, app, token.Token, upload)
+	assert.Equal(t, "created", report.Results[0].Result)
+
+	// the loser of the race rolls its todo back and finds the winner's
+	validate := validator.New()
+	todoService := service.NewTodoService(repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db), repository.NewTodoReferenceRepository(db), repository.NewCommentRepository(db), repository.NewOutboxRepository(db), db, replica.NewRouter(db, nil), validate)
+	racing := &racingCalendarObjectRepository{CalendarObjectRepository: repository.NewCalendarObjectRepository(db)}
+	calendarService := service.NewCalendarService(repository.NewCalendarTokenRepository(db), racing, repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db), todoService, db, validate)
+	response := calendarService.Upload(context.Background(), token.Token, []byte(upload))
+	assert.Equal(t, web.CalendarUploadResult{Uid: "race@example.com", Result: "unchanged", TodoId: 1}, response.Results[0])
+
+	var todos int64
+	db.Model(&domain.Todo{}).Count(&todos)
+	assert.Equal(t, int64(1), todos)
+
+	// a UID whose todo was deleted is taken over by the new todo
+	request := httptest.NewRequest(http.MethodDelete, "/todos/1", nil)
+	deleted, _ := app.Test(request, -1)
+	assert.Equal(t, http.StatusOK, deleted.StatusCode)
+
+	_, report = uploadCalendar(t, app, token.Token, upload)
+	assert.Equal(t, web.CalendarUploadResult{Uid: "race@example.com", Result: "created", TodoId: 2}, report.Results[0])
+	_, report = uploadCalendar(t, app, token.Token, upload)
+	assert.Equal(t, web.CalendarUploadResult{Uid: "race@example.com", Result: "unchanged", TodoId: 2}, report.Results[0])
+	_, feed := getFeed(t, app, token.Token)
+	assert.Contains(t, feed, "UID:race@example.com\r\n")
+}
+
+func TestICalFoldsLongLines(t *testing.T) {
+	calendar := ical.NewComponent("VCALENDAR")
+	vtodo := ical.NewComponent("VTODO")
+	vtodo.AddText("SUMMARY", strings.Repeat("é", 60))
+	calendar.Components = append(calendar.Components, vtodo)
+
+	var buffer bytes.Buffer
+	assert.NoError(t, ical.Encode(&buffer, calendar))
+	for _, line := range strings.Split(strings.TrimSuffix(buffer.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+
+	decoded, err := ical.Decode(&buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("é", 60), decoded.Children("VTODO")[0].Text("SUMMARY"))
+}
@@ -0,0 +1,252 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app-api/exception"
+	"todo-app-api/helper"
+	"todo-app-api/models/domain"
+	"todo-app-api/models/web"
+	"todo-app-api/repository"
+	"todo-app-api/service"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func sendComment(t *testing.T, app *fiber.App, method string, path string, actor string, body string) (int, json.RawMessage) {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if actor != "" {
+		request.Header.Set("X-Actor", actor)
+	}
+	response, err := app.Test(request, -1)
+	assert.NoError(t, err)
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	json.NewDecoder(response.Body).Decode(&envelope)
+	return response.StatusCode, envelope.Data
+}
+
+func postComment(t *testing.T, app *fiber.App, actor string, body string) web.CommentResponse {
+	status, data := sendComment(t, app, http.MethodPost, "/v2/todos/1/comments", actor, body)
+	assert.Equal(t, http.StatusOK, status)
+	var comment web.CommentResponse
+	json.Unmarshal(data, &comment)
+	return comment
+}
+
+func findComments(t *testing.T, app *fiber.App, query string) (web.CommentResponse, []web.CommentResponse, int64) {
+	_, data := sendComment(t, app, http.MethodGet, "/v2/todos/1/comments"+query, "", "")
+	var page struct {
+		Items []web.CommentResponse `json:"items"`
+		Total int64                 `json:"total"`
+	}
+	json.Unmarshal(data, &page)
+	if len(page.Items) == 0 {
+		return web.CommentResponse{}, nil, page.Total
+	}
+	return page.Items[0], page.Items, page.Total
+}
+
+func TestCommentThreadsArePaged(t *testing.T) {
+	app, _, _ := setupAuditApp(t)
+	sendTodo(t, app, http.MethodPost, "/v2/todos", `{"title": "Release", "description": "d"}`)
+
+	status, _ := sendComment(t, app, http.MethodPost, "/v2/todos/1/comments", "", `{"body": "anonymous"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = sendComment(t, app, http.MethodPost, "/v2/todos/9/comments", "alice", `{"body": "lost"}`)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	first := postComment(t, app, "alice", `{"body": "Who tags the build?"}`)
+	assert.Equal(t, "alice", first.Actor)
+	reply := postComment(t, app, "bob", `{"body": "I do", "parent_id": 1}`)
+	assert.Equal(t, &first.Id, reply.ParentId)
+	postComment(t, app, "alice", `{"body": "Thanks", "parent_id": 2}`)
+	postComment(t, app, "carol", `{"body": "Second thread"}`)
+
+	status, _ = sendComment(t, app, http.MethodPost, "/v2/todos/1/comments", "bob", `{"body": "x", "parent_id": 99}`)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	thread, threads, total := findComments(t, app, "?size=1")
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, threads, 1)
+	assert.Equal(t, "Who tags the build?", thread.Body)
+	if assert.Len(t, thread.Replies, 1) && assert.Len(t, thread.Replies[0].Replies, 1) {
+		assert.Equal(t, "Thanks", thread.Replies[0].Replies[0].Body)
+	}
+
+	thread, _, _ = findComments(t, app, "?size=1&page=2")
+	assert.Equal(t, "Second thread", thread.Body)
+	assert.Empty(t, thread.Replies)
+
+	status, _ = sendComment(t, app, http.MethodGet, "/v2/todos/1/comments?size=101", "", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = sendComment(t, app, http.MethodGet, "/v2/todos/9/comments", "", "")
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestCommentEditDeleteAndReactions(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+	sendTodo(t, app, http.MethodPost, "/v2/todos", `{"title": "Release", "description": "d"}`)
+	postComment(t, app, "alice", `{"body": "Tag on Friday"}`)
+	postComment(t, app, "bob", `{"body": "Friday works", "parent_id": 1}`)
+
+	status, _ := sendComment(t, app, http.MethodPut, "/v2/todos/1/comments/1", "bob", `{"body": "Tag on Monday"}`)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	status, data := sendComment(t, app, http.MethodPut, "/v2/todos/1/comments/1", "alice", `{"body": "Tag on Monday"}`)
+	assert.Equal(t, http.StatusOK, status)
+	var comment web.CommentResponse
+	json.Unmarshal(data, &comment)
+	assert.Equal(t, "Tag on Monday", comment.Body)
+	assert.True(t, comment.Edited)
+
+	_, data = sendComment(t, app, http.MethodGet, "/v2/todos/1/comments/1/history", "", "")
+	var edits []web.CommentEditResponse
+	json.Unmarshal(data, &edits)
+	if assert.Len(t, edits, 1) {
+		assert.Equal(t, "Tag on Friday", edits[0].Body)
+	}
+
+	sendComment(t, app, http.MethodPut, "/v2/todos/1/comments/1/reactions", "bob", `{"emoji": "+1", "reacted": true}`)
+	sendComment(t, app, http.MethodPut, "/v2/todos/1/comments/1/reactions", "bob", `{"emoji": "+1", "reacted": true}`)
+	sendComment(t, app, http.MethodPut, "/v2/todos/1/comments/1/reactions", "carol", `{"emoji": "rocket", "reacted": true}`)
+	_, data = sendComment(t, app, http.MethodPut, "/v2/todos/1/comments/1/reactions", "carol", `{"emoji": "+1", "reacted": true}`)
+	json.Unmarshal(data, &comment)
+	assert.Equal(t, []web.CommentReactionResponse{
+		{Emoji: "+1", Count: 2, Actors: []string{"bob", "carol"}},
+		{Emoji: "rocket", Count: 1, Actors: []string{"carol"}},
+	}, comment.Reactions)
+
+	_, data = sendComment(t, app, http.MethodPut, "/v2/todos/1/comments/1/reactions", "carol", `{"emoji": "rocket", "reacted": false}`)
+	json.Unmarshal(data, &comment)
+	assert.Len(t, comment.Reactions, 1)
+	status, _ = sendComment(t, app, http.MethodPut, "/v2/todos/1/comments/1/reactions", "carol", `{"emoji": "tada", "reacted": true}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	var auditLog domain.AuditLog
+	db.Where("action = ?", "comment.react").Last(&auditLog)
+	assert.Equal(t, "comment:1", auditLog.Resource)
+
+	status, _ = sendComment(t, app, http.MethodDelete, "/v2/todos/1/comments/1", "bob", "")
+	assert.Equal(t, http.StatusForbidden, status)
+	status, _ = sendComment(t, app, http.MethodDelete, "/v2/todos/1/comments/1", "alice", "")
+	assert.Equal(t, http.StatusOK, status)
+
+	// the reply keeps its place below the deleted comment
+	thread, _, total := findComments(t, app, "")
+	assert.Equal(t, int64(1), total)
+	assert.True(t, thread.Deleted)
+	assert.Empty(t, thread.Body)
+	assert.Empty(t, thread.Reactions)
+	if assert.Len(t, thread.Replies, 1) {
+		assert.Equal(t, "Friday works", thread.Replies[0].Body)
+	}
+	_, data = sendComment(t, app, http.MethodGet, "/v2/todos/1/comments/1/history", "", "")
+	assert.JSONEq(t, `[]`, string(data))
+
+	status, _ = sendComment(t, app, http.MethodPut, "/v2/todos/1/comments/1", "alice", `{"body": "Back"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = sendComment(t, app, http.MethodPost, "/v2/todos/1/comments", "bob", `{"body": "x", "parent_id": 1}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// comments are addressed through their own todo
+	sendTodo(t, app, http.MethodPost, "/v2/todos", `{"title": "Other", "description": "d"}`)
+	status, _ = sendComment(t, app, http.MethodPut, "/v2/todos/2/comments/2", "bob", `{"body": "moved"}`)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestCommentCountAndTodoDelete(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+	sendTodo(t, app, http.MethodPost, "/v2/todos", `{"title": "Release", "description": "d"}`)
+	postComment(t, app, "alice", `{"body": "one"}`)
+	postComment(t, app, "bob", `{"body": "two", "parent_id": 1}`)
+	postComment(t, app, "bob", `{"body": "three"}`)
+	sendComment(t, app, http.MethodPut, "/v2/todos/1/comments/1", "alice", `{"body": "one, edited"}`)
+	sendComment(t, app, http.MethodPut, "/v2/todos/1/comments/1/reactions", "bob", `{"emoji": "heart", "reacted": true}`)
+	sendComment(t, app, http.MethodDelete, "/v2/todos/1/comments/3", "bob", "")
+
+	_, todo := sendTodo(t, app, http.MethodGet, "/v2/todos/1", "")
+	assert.Equal(t, 2, todo.CommentCount)
+
+	response, _ := app.Test(httptest.NewRequest(http.MethodGet, "/v1/todos/1", nil), -1)
+	var v1 map[string]any
+	json.NewDecoder(response.Body).Decode(&v1)
+	assert.NotContains(t, v1["Data"], "comment_count")
+
+	// v1 shows it only when asked for
+	response, _ = app.Test(httptest.NewRequest(http.MethodGet, "/v1/todos/1?include=comment_count", nil), -1)
+	json.NewDecoder(response.Body).Decode(&v1)
+	assert.Equal(t, float64(2), v1["Data"].(map[string]any)["comment_count"])
+
+	response, _ = app.Test(httptest.NewRequest(http.MethodGet, "/v1/todos?include=comment_count", nil), -1)
+	json.NewDecoder(response.Body).Decode(&v1)
+	if todos, ok := v1["Data"].([]any); assert.True(t, ok) && assert.Len(t, todos, 1) {
+		assert.Equal(t, float64(2), todos[0].(map[string]any)["comment_count"])
+	}
+
+	status, _ := sendTodo(t, app, http.MethodDelete, "/v2/todos/1", "")
+	assert.Equal(t, http.StatusOK, status)
+
+	for _, model := range []any{&domain.Comment{}, &domain.CommentEdit{}, &domain.CommentReaction{}} {
+		var count int64
+		db.Model(model).Count(&count)
+		assert.Zero(t, count)
+	}
+
+	// a reverted delete brings the todo back without its comments
+	sendTodo(t, app, http.MethodPost, "/v2/todos/1/history/1/revert", "")
+	_, todo = sendTodo(t, app, http.MethodGet, "/v2/todos/1", "")
+	assert.Equal(t, "Release", todo.Title)
+	assert.Zero(t, todo.CommentCount)
+}
+
+// lockingTodoRepository records the todos locked through it.
+type lockingTodoRepository struct {
+	repository.TodoRepository
+	locked []int
+}
+
+func (r *lockingTodoRepository) FindByIdForUpdate(ctx context.Context, tx *gorm.DB, todoId int) (domain.Todo, error) {
+	r.locked = append(r.locked, todoId)
+	return r.TodoRepository.FindByIdForUpdate(ctx, tx, todoId)
+}
+
+func TestCommentChangesLockTheirTodo(t *testing.T) {
+	db := setupTestDB(t)
+	validate := validator.New()
+	todoRepository := &lockingTodoRepository{TodoRepository: repository.NewTodoRepository(db)}
+	commentService := service.NewCommentService(repository.NewCommentRepository(db), repository.NewCommentEditRepository(db), repository.NewCommentReactionRepository(db), todoRepository, db, validate)
+	db.Create(&domain.Todo{Title: "Release", Description: "d", Status: "pending"})
+	ctx := helper.WithActor(context.Background(), "alice")
+	reacted := true
+
+	comment := commentService.Create(ctx, web.CommentCreateRequest{TodoId: 1, Body: "one"})
+	commentService.Update(ctx, web.CommentUpdateRequest{TodoId: 1, Id: comment.Id, Body: "one, edited"})
+	commentService.React(ctx, web.CommentReactionRequest{TodoId: 1, Id: comment.Id, Emoji: "heart", Reacted: &reacted})
+	assert.Equal(t, []int{1, 1, 1}, todoRepository.locked)
+
+	assert.PanicsWithValue(t, exception.NotFoundError{Message: "todo not found"}, func() {
+		commentService.Create(ctx, web.CommentCreateRequest{TodoId: 2, Body: "nowhere"})
+	})
+
+	// the lock is a FOR UPDATE where the database has row locks
+	postgresDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	assert.NoError(t, err)
+	var query string
+	postgresDB.Callback().Query().After("gorm:query").Register("test:capture", func(db *gorm.DB) {
+		query = db.Statement.SQL.String()
+	})
+	repository.NewTodoRepository(postgresDB).FindByIdForUpdate(context.Background(), postgresDB, 1)
+	assert.True(t, strings.HasSuffix(query, "FOR UPDATE"), query)
+}
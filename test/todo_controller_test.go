@@ -14,6 +14,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 type MockTodoService struct {
@@ -25,6 +26,11 @@ func (m *MockTodoService) Create(context context.Context, request web.TodoCreate
 	return args.Get(0).(web.TodoResponse)
 }
 
+func (m *MockTodoService) CreateWithin(context context.Context, tx *gorm.DB, request web.TodoCreateRequest) web.TodoResponse {
+	args := m.Called(context, tx, request)
+	return args.Get(0).(web.TodoResponse)
+}
+
 func (m *MockTodoService) Update(context context.Context, request web.TodoUpdateRequest) web.TodoResponse {
 	args := m.Called(context, request)
 	return args.Get(0).(web.TodoResponse)
@@ -34,16 +40,51 @@ func (m *MockTodoService) Delete(context context.Context, todoId int) {
 	m.Called(context, todoId)
 }
 
+func (m *MockTodoService) SetTask(context context.Context, request web.TodoTaskRequest) web.TodoResponse {
+	args := m.Called(context, request)
+	return args.Get(0).(web.TodoResponse)
+}
+
 func (m *MockTodoService) FindById(context context.Context, todoId int) web.TodoResponse {
 	args := m.Called(context, todoId)
 	return args.Get(0).(web.TodoResponse)
 }
 
-func (m *MockTodoService) FindAll(context context.Context) []web.TodoResponse {
-	args := m.Called(context)
+func (m *MockTodoService) FindAll(context context.Context, request web.TodoFilterRequest) []web.TodoResponse {
+	args := m.Called(context, request)
+	return args.Get(0).([]web.TodoResponse)
+}
+
+func (m *MockTodoService) Import(context context.Context, request web.TodoImportRequest) web.TodoImportResponse {
+	args := m.Called(context, request)
+	return args.Get(0).(web.TodoImportResponse)
+}
+
+func (m *MockTodoService) Export(context context.Context, request web.TodoFilterRequest) func(fn func(todo web.TodoResponse) bool) error {
+	args := m.Called(context, request)
+	return args.Get(0).(func(fn func(todo web.TodoResponse) bool) error)
+}
+
+func (m *MockTodoService) History(context context.Context, todoId int) []web.TodoRevisionResponse {
+	args := m.Called(context, todoId)
+	return args.Get(0).([]web.TodoRevisionResponse)
+}
+
+func (m *MockTodoService) Revert(context context.Context, todoId int, revision int) web.TodoResponse {
+	args := m.Called(context, todoId, revision)
+	return args.Get(0).(web.TodoResponse)
+}
+
+func (m *MockTodoService) FindByIds(context context.Context, todoIds []int) []web.TodoResponse {
+	args := m.Called(context, todoIds)
 	return args.Get(0).([]web.TodoResponse)
 }
 
+func (m *MockTodoService) HistoryByTodoIds(context context.Context, todoIds []int) map[int][]web.TodoRevisionResponse {
+	args := m.Called(context, todoIds)
+	return args.Get(0).(map[int][]web.TodoRevisionResponse)
+}
+
 func setupFiberApp(todoController controller.TodoController) *fiber.App {
 	app := fiber.New(fiber.Config{
 		ErrorHandler: exception.NewErrorHandler,
@@ -53,6 +94,8 @@ func setupFiberApp(todoController controller.TodoController) *fiber.App {
 	app.Delete("/todos/:todoId", todoController.Delete)
 	app.Get("/todos", todoController.FindAll)
 	app.Get("/todos/:todoId", todoController.FindById)
+	app.Get("/todos/:todoId/history", todoController.History)
+	app.Post("/todos/:todoId/history/:revision/revert", todoController.Revert)
 
 	return app
 }
@@ -187,7 +230,7 @@ func TestControllerFindAllSuccess(t *testing.T) {
 			Status:      "done",
 		},
 	}
-	mockService.On("FindAll", mock.Anything).Return(expected)
+	mockService.On("FindAll", mock.Anything, web.TodoFilterRequest{}).Return(expected)
 
 	request := httptest.NewRequest(http.MethodGet, "/todos", nil)
 	response, _ := app.Test(request, -1)
@@ -225,3 +268,31 @@ func TestControllerDeletedFailed(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, response.StatusCode)
 }
+
+func TestControllerHistorySuccess(t *testing.T) {
+	mockService := new(MockTodoService)
+	todoController := controller.NewTodoController(mockService)
+	app := setupFiberApp(todoController)
+
+	expected := []web.TodoRevisionResponse{
+		{Revision: 1, TodoId: 1, Action: "create", Actor: "anonymous"},
+	}
+	mockService.On("History", mock.Anything, 1).Return(expected)
+
+	request := httptest.NewRequest(http.MethodGet, "/todos/1/history", nil)
+	response, _ := app.Test(request, -1)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestControllerRevertFailed(t *testing.T) {
+	mockService := new(MockTodoService)
+	todoController := controller.NewTodoController(mockService)
+	app := setupFiberApp(todoController)
+
+	request := httptest.NewRequest(http.MethodPost, "/todos/1/history/abc/revert", nil)
+	response, _ := app.Test(request, -1)
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
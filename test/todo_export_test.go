@@ -0,0 +1,137 @@
+package test
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app-api/models/domain"
+	"todo-app-api/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func exportTodos(t *testing.T, app *fiber.App, target string) (*http.Response, string) {
+	response, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil), -1)
+	assert.NoError(t, err)
+	body, err := io.ReadAll(response.Body)
+	assert.NoError(t, err)
+	return response, string(body)
+}
+
+func TestExportTodosAsCSV(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+	todoRepository := repository.NewTodoRepository(db)
+	ctx := context.Background()
+	todoRepository.Save(ctx, db, domain.Todo{Title: "Plain", Description: "d", Status: "pending"})
+	todoRepository.Save(ctx, db, domain.Todo{Title: `Say "hi", then leave`, Description: "line one\nline two", Status: "done"})
+
+	response, body := exportTodos(t, app, "/todos/export")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", response.Header.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="todos.csv"`, response.Header.Get("Content-Disposition"))
+	assert.True(t, strings.HasPrefix(body, "id,title,description,status,created_at,updated_at\r\n"), body)
+	assert.Contains(t, body, "2,\"Say \"\"hi\"\", then leave\",\"line one\r\nline two\",done,")
+
+	records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, records, 3) {
+		assert.Equal(t, []string{"2", `Say "hi", then leave`, "line one\nline two", "done"}, records[2][:4])
+	}
+
+	// same filters as GET /todos, with the columns picked and ordered
+	response, body = exportTodos(t, app, "/v2/todos/export?status=pending&columns=status,title")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "status,title\r\npending,Plain\r\n", body)
+
+	response, _ = exportTodos(t, app, "/todos/export?status=later")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response, body = exportTodos(t, app, "/todos/export?columns=title,owner")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Contains(t, body, `unknown column \"owner\"`)
+}
+
+func TestExportTodosGuardsCSVFormulas(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+	todoRepository := repository.NewTodoRepository(db)
+	ctx := context.Background()
+	todoRepository.Save(ctx, db, domain.Todo{Title: `=HYPERLINK("http://evil.example","x")`, Description: "+1 from ops", Status: "pending"})
+	todoRepository.Save(ctx, db, domain.Todo{Title: "-2+3", Description: "@SUM(A1)", Status: "pending"})
+	todoRepository.Save(ctx, db, domain.Todo{Title: "\t=1", Description: "a = b", Status: "pending"})
+
+	_, body := exportTodos(t, app, "/todos/export?columns=title,description")
+	records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"title", "description"},
+		{`'=HYPERLINK("http://evil.example","x")`, "'+1 from ops"},
+		{"'-2+3", "'@SUM(A1)"},
+		{"'\t=1", "a = b"},
+	}, records)
+
+	// JSON keeps the values as they are
+	_, body = exportTodos(t, app, "/todos/export?format=ndjson&columns=title")
+	assert.Equal(t, `{"title":"=HYPERLINK(\"http://evil.example\",\"x\")"}`+"\n"+`{"title":"-2+3"}`+"\n"+`{"title":"\t=1"}`+"\n", body)
+}
+
+func TestExportTodosAsJSONAndNDJSON(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+	todoRepository := repository.NewTodoRepository(db)
+	ctx := context.Background()
+	todoRepository.Save(ctx, db, domain.Todo{Title: "One", Description: "d", Status: "pending"})
+	todoRepository.Save(ctx, db, domain.Todo{Title: "Two", Description: "d", Status: "done"})
+
+	response, body := exportTodos(t, app, "/todos/export?format=json&columns=id,title")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="todos.json"`, response.Header.Get("Content-Disposition"))
+	assert.Equal(t, "[\n{\"id\":1,\"title\":\"One\"},\n{\"id\":2,\"title\":\"Two\"}\n]\n", body)
+
+	response, body = exportTodos(t, app, "/todos/export?format=ndjson&status=done")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "application/x-ndjson", response.Header.Get("Content-Type"))
+	lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	if assert.Len(t, lines, 1) {
+		var todo map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &todo))
+		assert.Equal(t, "Two", todo["title"])
+		assert.NotEmpty(t, todo["created_at"])
+	}
+
+	_, body = exportTodos(t, app, "/todos/export?format=json&status=pending&columns=title")
+	assert.Equal(t, "[\n{\"title\":\"One\"}\n]\n", body)
+
+	// an empty export is still a valid document
+	db.Exec("DELETE FROM todos")
+	_, body = exportTodos(t, app, "/todos/export?format=json")
+	assert.Equal(t, "[]\n", body)
+	_, body = exportTodos(t, app, "/todos/export?columns=id")
+	assert.Equal(t, "id\r\n", body)
+
+	response, _ = exportTodos(t, app, "/todos/export?format=xlsx")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestFindAllFiltersByStatus(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+	todoRepository := repository.NewTodoRepository(db)
+	ctx := context.Background()
+	todoRepository.Save(ctx, db, domain.Todo{Title: "One", Description: "d", Status: "pending"})
+	todoRepository.Save(ctx, db, domain.Todo{Title: "Two", Description: "d", Status: "done"})
+
+	response, body := getVersioned(t, app, "/v2/todos?status=done", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	todos := body["data"].([]interface{})
+	if assert.Len(t, todos, 1) {
+		assert.Equal(t, "Two", todos[0].(map[string]interface{})["title"])
+	}
+
+	status, _ := sendValidated(t, app, http.MethodGet, "/todos?status=later", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
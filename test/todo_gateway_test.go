@@ -0,0 +1,190 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	todov1 "todo-app-api/gen/todo/v1"
+	"todo-app-api/rpc"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// setupGateway serves the gRPC-Gateway in front of setupGRPC.
+func setupGateway(t *testing.T) *httptest.Server {
+	conn, _ := setupGRPC(t)
+	gateway, err := rpc.NewGateway(context.Background(), conn)
+	if err != nil {
+		t.Fatalf("gateway: %v", err)
+	}
+	server := httptest.NewServer(gateway)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func callGateway(t *testing.T, server *httptest.Server, method string, path string, body string, actor string) (*http.Response, map[string]interface{}) {
+	request, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if actor != "" {
+		request.Header.Set("X-Actor", actor)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer response.Body.Close()
+
+	raw, _ := io.ReadAll(response.Body)
+	decoded := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("%s %s: %v in %s", method, path, err, raw)
+	}
+	return response, decoded
+}
+
+func TestGatewayServesTheRESTPaths(t *testing.T) {
+	server := setupGateway(t)
+
+	request, _ := http.NewRequest(http.MethodPost, server.URL+"/todos", strings.NewReader(`{"title":"Gateway","description":"**bold** @ada"}`))
+	request.Header.Set("X-Actor", "ada")
+	request.Header.Set("X-Request-Id", "req-gateway")
+	response, err := http.DefaultClient.Do(request)
+	assert.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "req-gateway", response.Header.Get("X-Request-Id"))
+
+	response, todo := callGateway(t, server, http.MethodGet, "/todos/1", "", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "Gateway", todo["title"])
+	assert.Equal(t, "", todo["description_html"])
+	assert.Equal(t, float64(0), todo["comment_count"])
+	assert.Contains(t, todo, "created_at")
+
+	response, comment := callGateway(t, server, http.MethodPost, "/todos/1/comments", `{"body":"first"}`, "ada")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "ada", comment["actor"])
+
+	_, todo = callGateway(t, server, http.MethodGet, "/todos/1?render=html", "", "")
+	assert.Equal(t, "<p><strong>bold</strong> @ada</p>\n", todo["description_html"])
+	assert.Equal(t, float64(1), todo["comment_count"])
+
+	response, list := callGateway(t, server, http.MethodGet, "/todos?mention=ada&render=html", "", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	if todos, ok := list["todos"].([]interface{}); assert.True(t, ok) && assert.Len(t, todos, 1) {
+		assert.NotEmpty(t, todos[0].(map[string]interface{})["description_html"])
+	}
+
+	response, todo = callGateway(t, server, http.MethodPut, "/todos/1", `{"title":"Gateway","description":"- [ ] one","status":"pending"}`, "ada")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "- [ ] one", todo["description"])
+
+	response, todo = callGateway(t, server, http.MethodPut, "/todos/1/tasks/1", `{"checked":true}`, "ada")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "- [x] one", todo["description"])
+
+	_, history := callGateway(t, server, http.MethodGet, "/todos/1/history", "", "")
+	assert.Len(t, history["revisions"], 3)
+
+	response, todo = callGateway(t, server, http.MethodPost, "/todos/1/history/1/revert", "", "ada")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "**bold** @ada", todo["description"])
+
+	_, batch := callGateway(t, server, http.MethodGet, "/todos:batchGet?ids=1&ids=99", "", "")
+	assert.Len(t, batch["todos"], 1)
+
+	_, histories := callGateway(t, server, http.MethodGet, "/todos/history:batchGet?todo_ids=1", "", "")
+	assert.Contains(t, histories["histories"], "1")
+
+	response, _ = callGateway(t, server, http.MethodDelete, "/todos/1", "", "ada")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, failure := callGateway(t, server, http.MethodGet, "/todos/1", "", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	assert.Equal(t, "todo not found", failure["message"])
+
+	response, _ = callGateway(t, server, http.MethodGet, "/todos?render=pdf", "", "")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestGatewayServesComments(t *testing.T) {
+	server := setupGateway(t)
+	callGateway(t, server, http.MethodPost, "/todos", `{"title":"Discussed","description":"d"}`, "ada")
+
+	response, _ := callGateway(t, server, http.MethodPost, "/todos/1/comments", `{"body":"anonymous"}`, "")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	_, first := callGateway(t, server, http.MethodPost, "/todos/1/comments", `{"body":"first"}`, "ada")
+	response, reply := callGateway(t, server, http.MethodPost, "/todos/1/comments", `{"body":"reply","parent_id":"1"}`, "bob")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "1", reply["parent_id"])
+	assert.NotContains(t, first, "parent_id")
+
+	response, _ = callGateway(t, server, http.MethodPut, "/todos/1/comments/1", `{"body":"hijacked"}`, "bob")
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+	response, edited := callGateway(t, server, http.MethodPut, "/todos/1/comments/1", `{"body":"first, edited"}`, "ada")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, true, edited["edited"])
+
+	_, history := callGateway(t, server, http.MethodGet, "/todos/1/comments/1/history", "", "")
+	if edits, ok := history["edits"].([]interface{}); assert.True(t, ok) && assert.Len(t, edits, 1) {
+		assert.Equal(t, "first", edits[0].(map[string]interface{})["body"])
+	}
+
+	response, reacted := callGateway(t, server, http.MethodPut, "/todos/1/comments/1/reactions", `{"emoji":"heart","reacted":true}`, "bob")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	if reactions, ok := reacted["reactions"].([]interface{}); assert.True(t, ok) && assert.Len(t, reactions, 1) {
+		assert.Equal(t, float64(1), reactions[0].(map[string]interface{})["count"])
+	}
+
+	response, _ = callGateway(t, server, http.MethodDelete, "/todos/1/comments/2", "", "bob")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	_, page := callGateway(t, server, http.MethodGet, "/todos/1/comments?size=10", "", "")
+	assert.Equal(t, "1", page["total"])
+	assert.Equal(t, float64(10), page["size"])
+	if items, ok := page["items"].([]interface{}); assert.True(t, ok) && assert.Len(t, items, 1) {
+		replies := items[0].(map[string]interface{})["replies"].([]interface{})
+		assert.Equal(t, true, replies[0].(map[string]interface{})["deleted"])
+	}
+}
+
+func TestGRPCCommentsNeedAnAuthor(t *testing.T) {
+	conn, _ := setupGRPC(t)
+	todos := todov1.NewTodoServiceClient(conn)
+	comments := todov1.NewCommentServiceClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-actor", "ada")
+
+	_, err := todos.CreateTodo(ctx, &todov1.CreateTodoRequest{Title: "Discussed", Description: "d"})
+	assert.NoError(t, err)
+
+	_, err = comments.CreateComment(context.Background(), &todov1.CreateCommentRequest{TodoId: 1, Body: "anonymous"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = comments.ReactToComment(ctx, &todov1.ReactToCommentRequest{TodoId: 1, Id: 1, Emoji: "tada", Reacted: true})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = comments.DeleteComment(ctx, &todov1.DeleteCommentRequest{TodoId: 1, Id: 7})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	comment, err := comments.CreateComment(ctx, &todov1.CreateCommentRequest{TodoId: 1, Body: "signed"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ada", comment.GetActor())
+	assert.Nil(t, comment.ParentId)
+
+	other := metadata.AppendToOutgoingContext(context.Background(), "x-actor", "bob")
+	_, err = comments.DeleteComment(other, &todov1.DeleteCommentRequest{TodoId: 1, Id: comment.GetId()})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	todo, err := todos.GetTodo(ctx, &todov1.GetTodoRequest{Id: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), todo.GetCommentCount())
+}
@@ -0,0 +1,311 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-app-api/controller"
+	"todo-app-api/graph"
+	"todo-app-api/models/web"
+	"todo-app-api/outbox"
+	"todo-app-api/replica"
+	"todo-app-api/repository"
+	"todo-app-api/service"
+	"todo-app-api/stream"
+
+	"github.com/fasthttp/websocket"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/graphql-go/graphql/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type graphQLResult struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func postGraphQL(t *testing.T, app *fiber.App, query string, variables map[string]interface{}) graphQLResult {
+	body, _ := json.Marshal(web.GraphQLRequest{Query: query, Variables: variables})
+	request := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Actor", "grace")
+	response, err := app.Test(request, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var result graphQLResult
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&result))
+	return result
+}
+
+// countQueries counts the SELECTs db runs on todos and their revisions from
+// now on, leaving out those of the audit log.
+func countQueries(t *testing.T, db *gorm.DB) *int {
+	count := 0
+	err := db.Callback().Query().After("gorm:query").Register("test:count_queries", func(query *gorm.DB) {
+		if query.Statement.Table != "audit_logs" {
+			count++
+		}
+	})
+	assert.NoError(t, err)
+	return &count
+}
+
+func TestGraphQLMutationsAndNestedQuery(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+
+	for _, title := range []string{"First", "Second", "Third"} {
+		result := postGraphQL(t, app, `mutation($input: TodoInput!) { createTodo(input: $input) { id title status } }`, map[string]interface{}{
+			"input": map[string]interface{}{"title": title, "description": "d"},
+		})
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, "PENDING", result.Data["createTodo"].(map[string]interface{})["status"])
+	}
+
+	result := postGraphQL(t, app, `mutation { updateTodo(id: 2, input: {title: "Second", description: "d2", status: DONE}) { status updatedAt } }`, nil)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, "DONE", result.Data["updateTodo"].(map[string]interface{})["status"])
+	assert.NotEmpty(t, result.Data["updateTodo"].(map[string]interface{})["updatedAt"])
+
+	result = postGraphQL(t, app, `mutation { deleteTodo(id: 3) }`, nil)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, true, result.Data["deleteTodo"])
+
+	queries := countQueries(t, db)
+	result = postGraphQL(t, app, `{ todos { id title history { revision action actor todo { id title } } } }`, nil)
+	assert.Empty(t, result.Errors)
+	// todos, every history, every todo the revisions point back to
+	assert.Equal(t, 3, *queries, "nested fields are batched")
+
+	todos := result.Data["todos"].([]interface{})
+	assert.Len(t, todos, 2)
+	second := todos[1].(map[string]interface{})
+	history := second["history"].([]interface{})
+	assert.Len(t, history, 2)
+	revision := history[1].(map[string]interface{})
+	assert.Equal(t, "update", revision["action"])
+	assert.Equal(t, "grace", revision["actor"])
+	assert.Equal(t, "Second", revision["todo"].(map[string]interface{})["title"])
+
+	result = postGraphQL(t, app, `mutation { revertTodo(id: 3, revision: 1) { id title } }`, nil)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, "Third", result.Data["revertTodo"].(map[string]interface{})["title"])
+}
+
+func TestGraphQLListsTodosUpdatedWithoutStatus(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+	postGraphQL(t, app, `mutation { createTodo(input: {title: "Done", description: "d", status: DONE}) { id } }`, nil)
+
+	// v1 accepts an update without a status
+	request := httptest.NewRequest(http.MethodPut, "/v1/todos/1", bytes.NewReader([]byte(`{"title": "Done", "description": "d"}`)))
+	request.Header.Set("Content-Type", "application/json")
+	response, err := app.Test(request, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var status string
+	db.Table("todos").Select("status").Where("id = ?", 1).Scan(&status)
+	assert.Equal(t, "pending", status)
+
+	result := postGraphQL(t, app, `{ todos { id status } }`, nil)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": float64(1), "status": "PENDING"}}, result.Data["todos"])
+}
+
+func TestGraphQLReportsServiceErrors(t *testing.T) {
+	app, _, _ := setupAuditApp(t)
+
+	result := postGraphQL(t, app, `{ todo(id: 99) { title } }`, nil)
+	assert.Nil(t, result.Data["todo"])
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "todo not found", result.Errors[0].Message)
+		assert.Equal(t, "NOT_FOUND", result.Errors[0].Extensions["code"])
+	}
+
+	result = postGraphQL(t, app, `mutation { createTodo(input: {title: "x", description: "d"}) { id } }`, nil)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "BAD_USER_INPUT", result.Errors[0].Extensions["code"])
+	}
+
+	result = postGraphQL(t, app, `{ todos { nope } }`, nil)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Contains(t, result.Errors[0].Message, `Cannot query field "nope"`)
+	}
+
+	result = postGraphQL(t, app, `subscription { todoEvents { id } }`, nil)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "subscriptions are only served over the WebSocket at /graphql/ws", result.Errors[0].Message)
+	}
+
+	status, response := sendValidated(t, app, http.MethodPost, "/graphql", `{"variables": {}}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "body.query: is required", response.Data)
+}
+
+func TestGraphQLLimitsDepthAndComplexity(t *testing.T) {
+	app, _, _ := setupAuditApp(t)
+
+	result := postGraphQL(t, app, `{ todos { history { todo { history { todo { history { todo { history { todo { id } } } } } } } } } }`, nil)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "query depth 10 exceeds the limit of 8", result.Errors[0].Message)
+		assert.Equal(t, "QUERY_TOO_COMPLEX", result.Errors[0].Extensions["code"])
+	}
+	assert.Nil(t, result.Data)
+
+	// shallow, but every list multiplies what is below it
+	result = postGraphQL(t, app, `fragment all on Todo { id title description status createdAt updatedAt }
+		{ todos { ...all history { revision changes { field old new } todo { ...all } } } }`, nil)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "query complexity 3971 exceeds the limit of 1000", result.Errors[0].Message)
+	}
+
+	// fragments spreading each other twice over are measured once each
+	fragments := "fragment f0 on Todo { id title }\n"
+	for i := 1; i <= 60; i++ {
+		fragments += fmt.Sprintf("fragment f%d on Todo { ...f%d ...f%d }\n", i, i-1, i-1)
+	}
+	started := time.Now()
+	result = postGraphQL(t, app, fragments+"{ todos { ...f60 } }", nil)
+	assert.Less(t, time.Since(started), 5*time.Second)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "query complexity 1099511627776 exceeds the limit of 1000", result.Errors[0].Message)
+	}
+
+	// introspection has limits of its own, loose enough for GraphiQL
+	result = postGraphQL(t, app, testutil.IntrospectionQuery, nil)
+	assert.Empty(t, result.Errors)
+	assert.NotNil(t, result.Data)
+
+	result = postGraphQL(t, app, `{ __type(name: "Todo") { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { name } } } } } } } } } } } } } } } } }`, nil)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "introspection depth 17 exceeds the limit of 15", result.Errors[0].Message)
+	}
+
+	result = postGraphQL(t, app, `{ __schema { types { fields { type { fields { type { fields { type { fields { name } } } } } } } } } }`, nil)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "introspection complexity 122212 exceeds the limit of 50000", result.Errors[0].Message)
+	}
+
+	// and __typename counts like any other field
+	result = postGraphQL(t, app, `{ todos { history { todo { history { todo { history { todo { history { __typename } } } } } } } } }`, nil)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "query depth 9 exceeds the limit of 8", result.Errors[0].Message)
+	}
+}
+
+func TestGraphQLSubscriptionOverWebSocket(t *testing.T) {
+	db := setupTestDB(t)
+	outboxRepository := repository.NewOutboxRepository(db)
+	hub := stream.NewHub(100, 16)
+	todoService := service.NewTodoService(repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db), repository.NewTodoReferenceRepository(db), repository.NewCommentRepository(db), outboxRepository, db, replica.NewRouter(db, nil), validator.New())
+	// events reach the hub only once the relay committed, resolvers would
+	// otherwise wait on its lock of the shared in-memory database
+	relayed := &recordingPublisher{}
+	relay := outbox.NewRelay(outboxRepository, db, relayed)
+
+	executor, err := graph.NewExecutor(todoService, hub, graph.Limits{MaxDepth: 8, MaxComplexity: 1000, MaxIntrospectionDepth: 15, MaxIntrospectionComplexity: 50000})
+	assert.NoError(t, err)
+	graphqlController := controller.NewGraphQLController(executor, false)
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/graphql/ws", graphqlController.WebSocket)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go app.Listener(listener)
+	t.Cleanup(func() { app.ShutdownWithTimeout(time.Second) })
+
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-transport-ws"}}
+	conn, response, err := dialer.Dial("ws://"+listener.Addr().String()+"/graphql/ws", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	assert.Equal(t, "graphql-transport-ws", response.Header.Get("Sec-WebSocket-Protocol"))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var message map[string]interface{}
+	assert.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "connection_init"}))
+	assert.NoError(t, conn.ReadJSON(&message))
+	assert.Equal(t, "connection_ack", message["type"])
+
+	assert.NoError(t, conn.WriteJSON(map[string]interface{}{"id": "bad", "type": "subscribe", "payload": map[string]interface{}{"query": `subscription { todoEvents { nope } }`}}))
+	message = nil
+	assert.NoError(t, conn.ReadJSON(&message))
+	assert.Equal(t, "error", message["type"])
+	assert.Equal(t, "bad", message["id"])
+
+	assert.NoError(t, conn.WriteJSON(map[string]interface{}{"id": "1", "type": "subscribe", "payload": map[string]interface{}{
+		"query": `subscription { todoEvents { type todoId todo { title status history { action } } } }`,
+	}}))
+	assert.Eventually(t, func() bool { return hub.Subscribers() == 1 }, time.Second, 10*time.Millisecond)
+
+	ctx := context.Background()
+	created := todoService.Create(ctx, web.TodoCreateRequest{Title: "Live", Description: "d"})
+	todoService.Update(ctx, web.TodoUpdateRequest{Id: created.Id, Title: "Live", Description: "d", Status: "done"})
+	for relay.RelayBatch(ctx) > 0 {
+	}
+	for _, evt := range relayed.events {
+		hub.Publish(ctx, evt)
+	}
+
+	for _, expected := range []struct {
+		eventType string
+		status    string
+	}{{"todo.created", "PENDING"}, {"todo.updated", "DONE"}, {"todo.completed", "DONE"}} {
+		message = nil
+		assert.NoError(t, conn.ReadJSON(&message))
+		assert.Equal(t, "next", message["type"])
+		assert.Equal(t, "1", message["id"])
+		evt := message["payload"].(map[string]interface{})["data"].(map[string]interface{})["todoEvents"].(map[string]interface{})
+		assert.Equal(t, expected.eventType, evt["type"])
+		assert.Equal(t, float64(created.Id), evt["todoId"])
+		todo := evt["todo"].(map[string]interface{})
+		assert.Equal(t, expected.status, todo["status"])
+		// history is loaded fresh for every event
+		assert.Len(t, todo["history"], 2)
+	}
+
+	assert.NoError(t, conn.WriteJSON(map[string]interface{}{"id": "1", "type": "complete"}))
+	assert.Eventually(t, func() bool { return hub.Subscribers() == 0 }, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "ping"}))
+	message = nil
+	assert.NoError(t, conn.ReadJSON(&message))
+	assert.Equal(t, "pong", message["type"])
+}
+
+func TestGraphQLPlaygroundOnlyInDevelopment(t *testing.T) {
+	app, _, _ := setupAuditApp(t)
+
+	response, err := app.Test(httptest.NewRequest(http.MethodGet, "/graphql/playground", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	executor, err := graph.NewExecutor(nil, stream.NewHub(10, 10), graph.Limits{})
+	assert.NoError(t, err)
+	development := fiber.New()
+	development.Get("/graphql/playground", controller.NewGraphQLController(executor, true).Playground)
+
+	response, err = development.Test(httptest.NewRequest(http.MethodGet, "/graphql/playground", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Contains(t, response.Header.Get("Content-Type"), "text/html")
+
+	body, _ := io.ReadAll(response.Body)
+	assert.Contains(t, string(body), `<script src="/assets/scripts/graphiql.min.js">`)
+	assert.NotContains(t, string(body), "https://")
+}
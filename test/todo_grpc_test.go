@@ -0,0 +1,279 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+	todov1 "todo-app-api/gen/todo/v1"
+	"todo-app-api/models/domain"
+	"todo-app-api/replica"
+	"todo-app-api/repository"
+	"todo-app-api/rpc"
+	"todo-app-api/service"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthv1 "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+)
+
+// setupGRPC serves the gRPC API over an in-memory listener.
+func setupGRPC(t *testing.T) (*grpc.ClientConn, *gorm.DB) {
+	db := setupTestDB(t)
+	validate := validator.New()
+	todoService := service.NewTodoService(repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db), repository.NewTodoReferenceRepository(db), repository.NewCommentRepository(db), repository.NewOutboxRepository(db), db, replica.NewRouter(db, nil), validate)
+	commentService := service.NewCommentService(repository.NewCommentRepository(db), repository.NewCommentEditRepository(db), repository.NewCommentReactionRepository(db), repository.NewTodoRepository(db), db, validate)
+	auditService := service.NewAuditService(repository.NewAuditLogRepository(db), db, validate)
+
+	server, _ := rpc.NewServer(rpc.NewTodoServer(todoService, validate), rpc.NewCommentServer(commentService, validate), auditService)
+	return dialGRPC(t, server), db
+}
+
+func dialGRPC(t *testing.T, server *grpc.Server) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGRPCTodoLifecycle(t *testing.T) {
+	conn, db := setupGRPC(t)
+	client := todov1.NewTodoServiceClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-actor", "ada", "x-request-id", "req-grpc")
+
+	var header metadata.MD
+	created, err := client.CreateTodo(ctx, &todov1.CreateTodoRequest{Title: "Typed", Description: "d"}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", created.GetStatus())
+	assert.NotNil(t, created.GetCreatedAt())
+	assert.Equal(t, []string{"req-grpc"}, header.Get("x-request-id"))
+
+	updated, err := client.UpdateTodo(ctx, &todov1.UpdateTodoRequest{Id: created.GetId(), Title: "Typed", Description: "d2", Status: "done"})
+	assert.NoError(t, err)
+	assert.Equal(t, "done", updated.GetStatus())
+
+	fetched, err := client.GetTodo(ctx, &todov1.GetTodoRequest{Id: created.GetId()})
+	assert.NoError(t, err)
+	assert.Equal(t, "d2", fetched.GetDescription())
+
+	list, err := client.ListTodos(ctx, &todov1.ListTodosRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, list.GetTodos(), 1)
+
+	history, err := client.GetHistory(ctx, &todov1.GetHistoryRequest{TodoId: created.GetId()})
+	assert.NoError(t, err)
+	if assert.Len(t, history.GetRevisions(), 2) {
+		assert.Equal(t, "ada", history.GetRevisions()[1].GetActor())
+		assert.NotEmpty(t, history.GetRevisions()[1].GetChanges())
+	}
+
+	reverted, err := client.RevertTodo(ctx, &todov1.RevertTodoRequest{TodoId: created.GetId(), Revision: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "d", reverted.GetDescription())
+
+	batch, err := client.BatchGetTodos(ctx, &todov1.BatchGetTodosRequest{Ids: []int64{created.GetId(), 99}})
+	assert.NoError(t, err)
+	assert.Len(t, batch.GetTodos(), 1)
+
+	histories, err := client.BatchGetHistories(ctx, &todov1.BatchGetHistoriesRequest{TodoIds: []int64{created.GetId(), 99}})
+	assert.NoError(t, err)
+	assert.Len(t, histories.GetHistories(), 1)
+	assert.Len(t, histories.GetHistories()[created.GetId()].GetRevisions(), 3)
+
+	_, err = client.DeleteTodo(ctx, &todov1.DeleteTodoRequest{Id: created.GetId()})
+	assert.NoError(t, err)
+
+	// mutations are audited like their REST counterparts
+	var auditLogs []domain.AuditLog
+	db.Order("id ASC").Find(&auditLogs)
+	if assert.Len(t, auditLogs, 4) {
+		assert.Equal(t, "ada", auditLogs[0].Actor)
+		assert.Equal(t, "req-grpc", auditLogs[0].RequestId)
+		assert.Equal(t, "todo.create", auditLogs[0].Action)
+		assert.Equal(t, "/todo.v1.TodoService/CreateTodo", auditLogs[0].Path)
+		assert.Equal(t, "todo.delete", auditLogs[3].Action)
+	}
+}
+
+func TestGRPCMapsErrors(t *testing.T) {
+	conn, db := setupGRPC(t)
+	client := todov1.NewTodoServiceClient(conn)
+	ctx := context.Background()
+
+	_, err := client.GetTodo(ctx, &todov1.GetTodoRequest{Id: 99})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "todo not found", status.Convert(err).Message())
+
+	_, err = client.DeleteTodo(ctx, &todov1.DeleteTodoRequest{Id: 99})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.CreateTodo(ctx, &todov1.CreateTodoRequest{Title: "x", Status: "later"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	var violations map[string]string
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = map[string]string{}
+			for _, violation := range badRequest.GetFieldViolations() {
+				violations[violation.GetField()] = violation.GetDescription()
+			}
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"title":       `fails "min=2"`,
+		"description": `fails "required"`,
+		"status":      `fails "oneof=pending done"`,
+	}, violations)
+
+	_, err = client.UpdateTodo(ctx, &todov1.UpdateTodoRequest{Title: "Valid", Description: "d"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	badRequest := status.Convert(err).Details()[0].(*errdetails.BadRequest)
+	assert.Equal(t, "id", badRequest.GetFieldViolations()[0].GetField())
+
+	// failed mutations are audited as failures; reads are not audited
+	var auditLogs []domain.AuditLog
+	db.Order("id ASC").Find(&auditLogs)
+	if assert.Len(t, auditLogs, 3) {
+		assert.Equal(t, "anonymous", auditLogs[0].Actor)
+		assert.Equal(t, "failure", auditLogs[0].Outcome)
+		assert.Equal(t, 404, auditLogs[0].StatusCode)
+		assert.Equal(t, 400, auditLogs[1].StatusCode)
+	}
+}
+
+func TestGRPCFiltersListsAndSetsTasks(t *testing.T) {
+	conn, _ := setupGRPC(t)
+	client := todov1.NewTodoServiceClient(conn)
+	ctx := context.Background()
+
+	release, err := client.CreateTodo(ctx, &todov1.CreateTodoRequest{Title: "Release", Description: "- [ ] tag\n- [ ] ship, ask @alice, see https://example.com/board"})
+	assert.NoError(t, err)
+	_, err = client.CreateTodo(ctx, &todov1.CreateTodoRequest{Title: "Other", Description: "Ask @carol", Status: "done"})
+	assert.NoError(t, err)
+
+	titles := func(request *todov1.ListTodosRequest) []string {
+		list, err := client.ListTodos(ctx, request)
+		assert.NoError(t, err)
+		var titles []string
+		for _, todo := range list.GetTodos() {
+			titles = append(titles, todo.GetTitle())
+		}
+		return titles
+	}
+	assert.Equal(t, []string{"Release", "Other"}, titles(&todov1.ListTodosRequest{}))
+	assert.Equal(t, []string{"Other"}, titles(&todov1.ListTodosRequest{Status: "done"}))
+	assert.Equal(t, []string{"Release"}, titles(&todov1.ListTodosRequest{Mention: "alice"}))
+	assert.Equal(t, []string{"Release"}, titles(&todov1.ListTodosRequest{Link: "https://example.com/board", Status: "pending"}))
+
+	_, err = client.ListTodos(ctx, &todov1.ListTodosRequest{Status: "later"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	checked, err := client.SetTask(ctx, &todov1.SetTaskRequest{Id: release.GetId(), Task: 2, Checked: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "- [ ] tag\n- [x] ship, ask @alice, see https://example.com/board", checked.GetDescription())
+
+	_, err = client.SetTask(ctx, &todov1.SetTaskRequest{Id: release.GetId()})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	badRequest := status.Convert(err).Details()[0].(*errdetails.BadRequest)
+	assert.Equal(t, "task", badRequest.GetFieldViolations()[0].GetField())
+}
+
+// panickingAuditService fails to record, as it does when the database does.
+type panickingAuditService struct {
+	service.AuditService
+}
+
+func (panickingAuditService) Record(ctx context.Context, auditLog domain.AuditLog) {
+	panic(errors.New("audit log unavailable"))
+}
+
+func TestGRPCSurvivesAFailingAuditLog(t *testing.T) {
+	db := setupTestDB(t)
+	validate := validator.New()
+	todoService := service.NewTodoService(repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db), repository.NewTodoReferenceRepository(db), repository.NewCommentRepository(db), repository.NewOutboxRepository(db), db, replica.NewRouter(db, nil), validate)
+
+	server, _ := rpc.NewServer(rpc.NewTodoServer(todoService, validate), rpc.NewCommentServer(nil, validate), panickingAuditService{})
+	client := todov1.NewTodoServiceClient(dialGRPC(t, server))
+
+	_, err := client.CreateTodo(context.Background(), &todov1.CreateTodoRequest{Title: "Audited", Description: "d"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "audit log unavailable", status.Convert(err).Message())
+
+	// the server is still up
+	list, err := client.ListTodos(context.Background(), &todov1.ListTodosRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, list.GetTodos(), 1)
+}
+
+func TestGRPCHealthAndReflection(t *testing.T) {
+	conn, _ := setupGRPC(t)
+	ctx := context.Background()
+
+	health := healthv1.NewHealthClient(conn)
+	for _, name := range []string{"", "todo.v1.TodoService", "todo.v1.CommentService"} {
+		response, err := health.Check(ctx, &healthv1.HealthCheckRequest{Service: name})
+		assert.NoError(t, err)
+		assert.Equal(t, healthv1.HealthCheckResponse_SERVING, response.GetStatus(), name)
+	}
+	_, err := health.Check(ctx, &healthv1.HealthCheckRequest{Service: "todo.v2.TodoService"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	stream, err := reflectionv1.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Send(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_ListServices{},
+	}))
+	response, err := stream.Recv()
+	assert.NoError(t, err)
+
+	var services []string
+	for _, service := range response.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	assert.Contains(t, services, "todo.v1.TodoService")
+	assert.Contains(t, services, "todo.v1.CommentService")
+	assert.Contains(t, services, "grpc.health.v1.Health")
+}
+
+func TestGRPCMatchesREST(t *testing.T) {
+	conn, _ := setupGRPC(t)
+	// same test name, so the same in-memory database
+	app, _, _ := setupAuditApp(t)
+	client := todov1.NewTodoServiceClient(conn)
+
+	created, err := client.CreateTodo(context.Background(), &todov1.CreateTodoRequest{Title: "Both", Description: "d"})
+	assert.NoError(t, err)
+
+	response, body := getVersioned(t, app, "/v2/todos/1", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	todo := body["data"].(map[string]interface{})
+	assert.Equal(t, float64(created.GetId()), todo["id"])
+	assert.Equal(t, created.GetTitle(), todo["title"])
+	assert.Equal(t, created.GetStatus(), todo["status"])
+	assert.Equal(t, created.GetCreatedAt().AsTime().Format(time.RFC3339Nano), todo["created_at"])
+
+	response, body = getVersioned(t, app, "/v2/todos/99", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	_, err = client.GetTodo(context.Background(), &todov1.GetTodoRequest{Id: 99})
+	assert.Equal(t, body["data"], status.Convert(err).Message())
+}
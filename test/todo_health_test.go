@@ -0,0 +1,163 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"todo-app-api/config"
+	"todo-app-api/controller"
+	"todo-app-api/lifecycle"
+	"todo-app-api/migration"
+	"todo-app-api/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupHealthService(t *testing.T, db *gorm.DB, appLifecycle *lifecycle.Lifecycle) service.HealthService {
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("failed to create migrator: %v", err)
+	}
+	return service.NewHealthService(db, migrator, appLifecycle, config.Default().Health)
+}
+
+func setupHealthApp(healthService service.HealthService) *fiber.App {
+	app := fiber.New()
+	healthController := controller.NewHealthController(healthService)
+	app.Get("/healthz", healthController.Liveness)
+	app.Get("/readyz", healthController.Readiness)
+	app.Get("/version", healthController.Version)
+	app.Get("/stats/database", healthController.DatabaseStats)
+	return app
+}
+
+func getHealth(t *testing.T, app *fiber.App, path string) (int, map[string]interface{}) {
+	response, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), -1)
+	assert.NoError(t, err)
+
+	var body map[string]interface{}
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&body))
+	return response.StatusCode, body["Data"].(map[string]interface{})
+}
+
+func TestHealthReadinessReportsEveryCheck(t *testing.T) {
+	db := setupTestDB(t)
+	appLifecycle := lifecycle.New()
+	appLifecycle.Go("worker", func(ctx context.Context) { <-ctx.Done() })
+	app := setupHealthApp(setupHealthService(t, db, appLifecycle))
+
+	code, data := getHealth(t, app, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "up", data["status"])
+
+	checks := data["checks"].(map[string]interface{})
+	for _, name := range []string{"database", "migrations", "workers"} {
+		assert.Equal(t, "up", checks[name].(map[string]interface{})["status"], name)
+	}
+
+	code, data = getHealth(t, app, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "up", data["status"])
+}
+
+func TestHealthReadinessFailsWhenWorkerStops(t *testing.T) {
+	db := setupTestDB(t)
+	appLifecycle := lifecycle.New()
+	appLifecycle.Go("outbox_relay", func(ctx context.Context) {})
+	assert.Eventually(t, func() bool { return !appLifecycle.Workers()["outbox_relay"] }, time.Second, 10*time.Millisecond)
+
+	code, data := getHealth(t, setupHealthApp(setupHealthService(t, db, appLifecycle)), "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "down", data["status"])
+
+	workers := data["checks"].(map[string]interface{})["workers"].(map[string]interface{})
+	assert.Equal(t, "stopped: outbox_relay", workers["error"])
+}
+
+func TestHealthReadinessReportsPendingMigrations(t *testing.T) {
+	db := setupTestDB(t)
+	migrator, err := migration.NewMigrator(db)
+	assert.NoError(t, err)
+	_, err = migrator.Down(context.Background())
+	assert.NoError(t, err)
+
+	healthService := service.NewHealthService(db, migrator, lifecycle.New(), config.Default().Health)
+	response := healthService.Readiness(context.Background())
+
+	assert.Equal(t, service.HealthStatusDown, response.Status)
+	assert.Equal(t, service.HealthStatusUp, response.Checks["database"].Status)
+	assert.Contains(t, response.Checks["migrations"].Error, "1 pending migration(s)")
+}
+
+func TestHealthReadinessIsCached(t *testing.T) {
+	var calls atomic.Int32
+	healthService := &service.HealthServiceImpl{
+		Lifecycle: lifecycle.New(),
+		Checks: []service.HealthCheck{{Name: "database", Check: func(ctx context.Context) error {
+			calls.Add(1)
+			return nil
+		}}},
+		Timeout:  time.Second,
+		CacheTTL: 50 * time.Millisecond,
+	}
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, service.HealthStatusUp, healthService.Readiness(context.Background()).Status)
+	}
+	assert.Equal(t, int32(1), calls.Load())
+
+	// once the entry expires the next probe checks again
+	healthService.CacheTTL = 0
+	time.Sleep(60 * time.Millisecond)
+	healthService.Readiness(context.Background())
+	healthService.Readiness(context.Background())
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestHealthCheckTimesOut(t *testing.T) {
+	healthService := &service.HealthServiceImpl{
+		Lifecycle: lifecycle.New(),
+		Checks: []service.HealthCheck{
+			{Name: "slow", Check: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}},
+			{Name: "broken", Check: func(ctx context.Context) error { return errors.New("connection refused") }},
+		},
+		Timeout: 20 * time.Millisecond,
+	}
+
+	response := healthService.Readiness(context.Background())
+
+	assert.Equal(t, service.HealthStatusDown, response.Status)
+	assert.Equal(t, "timed out after 20ms", response.Checks["slow"].Error)
+	assert.Equal(t, "connection refused", response.Checks["broken"].Error)
+}
+
+func TestHealthVersion(t *testing.T) {
+	healthService := &service.HealthServiceImpl{StartedAt: time.Now().Add(-time.Minute)}
+
+	code, data := getHealth(t, setupHealthApp(healthService), "/version")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, config.Version, data["version"])
+	assert.GreaterOrEqual(t, data["uptime_seconds"], float64(60))
+	assert.NotEmpty(t, data["go_version"])
+}
+
+func TestHealthDatabaseStats(t *testing.T) {
+	db := setupTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(4)
+
+	code, data := getHealth(t, setupHealthApp(setupHealthService(t, db, lifecycle.New())), "/stats/database")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(4), data["max_open_connections"])
+}
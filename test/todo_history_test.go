@@ -0,0 +1,131 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"todo-app-api/exception"
+	"todo-app-api/helper"
+	"todo-app-api/models/web"
+	"todo-app-api/replica"
+	"todo-app-api/repository"
+	"todo-app-api/service"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupHistoryService(t *testing.T) service.TodoService {
+	db := setupTestDB(t)
+	return newHistoryService(db, repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db))
+}
+
+func newHistoryService(db *gorm.DB, todoRepository repository.TodoRepository, revisionRepository repository.TodoRevisionRepository) service.TodoService {
+	return service.NewTodoService(
+		todoRepository,
+		revisionRepository,
+		repository.NewTodoReferenceRepository(db),
+		repository.NewCommentRepository(db),
+		repository.NewOutboxRepository(db),
+		db,
+		replica.NewRouter(db, nil),
+		validator.New(),
+	)
+}
+
+func TestHistoryRecordsFieldChanges(t *testing.T) {
+	todoService := setupHistoryService(t)
+	ctx := helper.WithActor(context.Background(), "alice")
+
+	created := todoService.Create(ctx, web.TodoCreateRequest{Title: "Write docs", Description: "first draft"})
+	todoService.Update(ctx, web.TodoUpdateRequest{Id: created.Id, Title: "Write docs", Description: "second draft", Status: "done"})
+
+	history := todoService.History(ctx, created.Id)
+	assert.Len(t, history, 2)
+	assert.Equal(t, "create", history[0].Action)
+	assert.Equal(t, "alice", history[0].Actor)
+
+	assert.Equal(t, 2, history[1].Revision)
+	assert.Equal(t, []web.FieldChangeResponse{
+		{Field: "description", Old: "first draft", New: "second draft"},
+		{Field: "status", Old: "pending", New: "done"},
+	}, history[1].Changes)
+}
+
+func TestHistorySurvivesDeleteAndRevertRestores(t *testing.T) {
+	todoService := setupHistoryService(t)
+	ctx := context.Background()
+
+	created := todoService.Create(ctx, web.TodoCreateRequest{Title: "Ship it", Description: "v1"})
+	todoService.Update(ctx, web.TodoUpdateRequest{Id: created.Id, Title: "Ship it", Description: "v2", Status: "pending"})
+	todoService.Delete(ctx, created.Id)
+
+	history := todoService.History(ctx, created.Id)
+	assert.Len(t, history, 3)
+	assert.Equal(t, "delete", history[2].Action)
+	assert.Equal(t, helper.AnonymousActor, history[2].Actor)
+
+	restored := todoService.Revert(ctx, created.Id, 1)
+	assert.Equal(t, created.Id, restored.Id)
+	assert.Equal(t, "v1", restored.Description)
+	assert.Equal(t, "v1", todoService.FindById(ctx, created.Id).Description)
+
+	history = todoService.History(ctx, created.Id)
+	assert.Len(t, history, 4)
+	assert.Equal(t, "revert", history[3].Action)
+}
+
+func TestHistoryRevertUnknownRevision(t *testing.T) {
+	todoService := setupHistoryService(t)
+	ctx := context.Background()
+
+	created := todoService.Create(ctx, web.TodoCreateRequest{Title: "Ship it", Description: "v1"})
+
+	assert.PanicsWithValue(t, exception.NotFoundError{Message: "todo revision not found"}, func() {
+		todoService.Revert(ctx, created.Id, 42)
+	})
+}
+
+func TestHistoryWritersLockTheTodo(t *testing.T) {
+	db := setupTestDB(t)
+	todoRepository := &lockingTodoRepository{TodoRepository: repository.NewTodoRepository(db)}
+	todoService := newHistoryService(db, todoRepository, repository.NewTodoRevisionRepository(db))
+	ctx := context.Background()
+	checked := true
+
+	created := todoService.Create(ctx, web.TodoCreateRequest{Title: "Ship it", Description: "- [ ] test"})
+	todoService.Update(ctx, web.TodoUpdateRequest{Id: created.Id, Title: "Ship it", Description: "- [ ] tests", Status: "pending"})
+	todoService.SetTask(ctx, web.TodoTaskRequest{Id: created.Id, Task: 1, Checked: &checked})
+	todoService.Delete(ctx, created.Id)
+	todoService.Revert(ctx, created.Id, 1)
+
+	// every revision after the first is numbered under the todo's row lock
+	assert.Equal(t, []int{created.Id, created.Id, created.Id, created.Id}, todoRepository.locked)
+	assert.Len(t, todoService.History(ctx, created.Id), 5)
+}
+
+// staleRevisionRepository answers LastRevision as a writer that raced with
+// another one would have read it.
+type staleRevisionRepository struct {
+	repository.TodoRevisionRepository
+}
+
+func (r *staleRevisionRepository) LastRevision(ctx context.Context, tx *gorm.DB, todoId int) int {
+	return r.TodoRevisionRepository.LastRevision(ctx, tx, todoId) - 1
+}
+
+func TestHistoryReportsARevisionRaceAsConflict(t *testing.T) {
+	db := setupTestDB(t)
+	todoService := newHistoryService(db, repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db))
+	ctx := context.Background()
+	created := todoService.Create(ctx, web.TodoCreateRequest{Title: "Ship it", Description: "v1"})
+
+	racing := newHistoryService(db, repository.NewTodoRepository(db), &staleRevisionRepository{repository.NewTodoRevisionRepository(db)})
+	assert.PanicsWithValue(t, exception.ConflictError{Message: "todo was changed concurrently, retry"}, func() {
+		racing.Update(ctx, web.TodoUpdateRequest{Id: created.Id, Title: "Ship it", Description: "v2", Status: "pending"})
+	})
+
+	// the losing write was rolled back
+	assert.Equal(t, "v1", todoService.FindById(ctx, created.Id).Description)
+	assert.Len(t, todoService.History(ctx, created.Id), 1)
+}
@@ -0,0 +1,193 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-app-api/models/domain"
+	"todo-app-api/models/web"
+	"todo-app-api/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func postImport(t *testing.T, app *fiber.App, request web.TodoImportRequest) (int, web.TodoImportResponse, string) {
+	body, _ := json.Marshal(request)
+	httpRequest := httptest.NewRequest(http.MethodPost, "/v2/todos/import", bytes.NewReader(body))
+	httpRequest.Header.Set("Content-Type", "application/json")
+	response, err := app.Test(httpRequest, -1)
+	assert.NoError(t, err)
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&envelope))
+
+	var importResponse web.TodoImportResponse
+	var message string
+	if json.Unmarshal(envelope.Data, &importResponse) != nil {
+		json.Unmarshal(envelope.Data, &message)
+	}
+	return response.StatusCode, importResponse, message
+}
+
+func storedTitles(db *gorm.DB) []string {
+	var titles []string
+	db.Model(&domain.Todo{}).Order("id ASC").Pluck("title", &titles)
+	return titles
+}
+
+func TestImportCSVWithMappingAndDryRun(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+	content := "Task,Notes,State\r\n" +
+		"Write report,Quarterly numbers,Done\r\n" +
+		"x,too short,pending\r\n" +
+		"\"Plan, then ship\",\"multi\nline\",later\r\n"
+	request := web.TodoImportRequest{
+		Format:  "csv",
+		Content: content,
+		Mapping: map[string]string{"title": "Task", "description": "Notes", "status": "State"},
+		DryRun:  true,
+	}
+
+	status, report, _ := postImport(t, app, request)
+	assert.Equal(t, http.StatusOK, status)
+	assert.True(t, report.DryRun)
+	assert.False(t, report.Committed)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 0, report.Imported)
+	assert.Equal(t, 2, report.Failed)
+	if assert.Len(t, report.Rows, 3) {
+		assert.Equal(t, web.TodoImportRowResponse{Line: 2, Title: "Write report", Result: "valid"}, report.Rows[0])
+		assert.Equal(t, 3, report.Rows[1].Line)
+		assert.Equal(t, []string{`title fails "min=2"`}, report.Rows[1].Errors)
+		// a quoted line break keeps the record together
+		assert.Equal(t, 4, report.Rows[2].Line)
+		assert.Equal(t, "Plan, then ship", report.Rows[2].Title)
+		assert.Equal(t, []string{`status fails "oneof=pending done"`}, report.Rows[2].Errors)
+	}
+	assert.Empty(t, storedTitles(db))
+
+	// all or nothing by default
+	request.DryRun = false
+	_, report, _ = postImport(t, app, request)
+	assert.False(t, report.Committed)
+	assert.Empty(t, storedTitles(db))
+
+	request.Partial = true
+	_, report, _ = postImport(t, app, request)
+	assert.True(t, report.Committed)
+	assert.Equal(t, 1, report.Imported)
+	assert.Equal(t, "created", report.Rows[0].Result)
+	assert.NotZero(t, report.Rows[0].TodoId)
+	assert.Equal(t, "invalid", report.Rows[1].Result)
+	assert.Equal(t, []string{"Write report"}, storedTitles(db))
+
+	todo, _ := repository.NewTodoRepository(db).FindById(context.Background(), db, report.Rows[0].TodoId)
+	assert.Equal(t, "done", todo.Status)
+	assert.Equal(t, "Quarterly numbers", todo.Description)
+
+	// imported todos get their history and events like created ones
+	var revisions, events int64
+	db.Model(&domain.TodoRevision{}).Where("todo_id = ?", todo.Id).Count(&revisions)
+	db.Model(&domain.OutboxMessage{}).Count(&events)
+	assert.Equal(t, int64(1), revisions)
+	assert.Equal(t, int64(1), events)
+
+	var auditLog domain.AuditLog
+	db.Order("id DESC").First(&auditLog)
+	assert.Equal(t, "todo.import", auditLog.Action)
+}
+
+func TestImportSkipsDuplicates(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+	repository.NewTodoRepository(db).Save(context.Background(), db, domain.Todo{Title: "Existing", Description: "d", Status: "pending"})
+
+	content := `{"title": "existing", "description": "d"}
+{"title": "New", "description": "d"}
+not json
+
+{"title": "new ", "description": "again"}
+`
+	_, report, _ := postImport(t, app, web.TodoImportRequest{Format: "ndjson", Content: content, Partial: true})
+	assert.Equal(t, 1, report.Imported)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, 1, report.Failed)
+	if assert.Len(t, report.Rows, 4) {
+		assert.Equal(t, "duplicate", report.Rows[0].Reason)
+		assert.Equal(t, "created", report.Rows[1].Result)
+		assert.Equal(t, web.TodoImportRowResponse{Line: 3, Result: "invalid", Errors: []string{"not a JSON object"}}, report.Rows[2])
+		assert.Equal(t, 5, report.Rows[3].Line)
+		assert.Equal(t, "duplicate", report.Rows[3].Reason)
+	}
+
+	_, report, _ = postImport(t, app, web.TodoImportRequest{Format: "json", Content: `[{"title": "Existing", "description": "d"}]`, Duplicates: "allow"})
+	assert.Equal(t, 1, report.Imported)
+	assert.Equal(t, []string{"Existing", "New", "Existing"}, storedTitles(db))
+}
+
+func TestImportFromOtherApps(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+
+	todoist := "TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE\n" +
+		"section,Errands,,,,,,,,\n" +
+		"task,Buy milk,Two litres,4,1,Ada (1),,,en,UTC\n" +
+		"note,Remember the receipt,,,,,,,,\n"
+	_, report, _ := postImport(t, app, web.TodoImportRequest{Format: "todoist", Content: todoist})
+	assert.Equal(t, 1, report.Total)
+	assert.Equal(t, 3, report.Rows[0].Line)
+	assert.Equal(t, "created", report.Rows[0].Result)
+
+	trello := `{"name": "Board", "lists": [], "cards": [
+		{"name": "Ship it", "desc": "Release v2", "closed": false, "dueComplete": true},
+		{"name": "Old idea", "desc": "d", "closed": true, "dueComplete": false}
+	]}`
+	_, report, _ = postImport(t, app, web.TodoImportRequest{Format: "trello", Content: trello})
+	assert.Equal(t, 1, report.Imported)
+	assert.Equal(t, "archived", report.Rows[1].Reason)
+
+	microsoftToDo := `{"@odata.context": "...", "value": [
+		{"title": "Call Bob", "status": "completed", "body": {"content": "About the contract", "contentType": "text"}},
+		{"title": "Book flights", "status": "notStarted", "body": {"content": "", "contentType": "text"}}
+	]}`
+	_, report, _ = postImport(t, app, web.TodoImportRequest{Format: "mstodo", Content: microsoftToDo, Partial: true})
+	assert.Equal(t, 1, report.Imported)
+	assert.Equal(t, []string{`description fails "required"`}, report.Rows[1].Errors)
+
+	var todos []domain.Todo
+	db.Order("id ASC").Find(&todos)
+	if assert.Len(t, todos, 3) {
+		assert.Equal(t, "Buy milk", todos[0].Title)
+		assert.Equal(t, "pending", todos[0].Status)
+		assert.Equal(t, "done", todos[1].Status)
+		assert.Equal(t, "Call Bob", todos[2].Title)
+		assert.Equal(t, "done", todos[2].Status)
+	}
+}
+
+func TestImportRejectsUnreadableFiles(t *testing.T) {
+	app, _, _ := setupAuditApp(t)
+
+	status, _, message := postImport(t, app, web.TodoImportRequest{Format: "csv", Content: "name,notes\nA,b\n"})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, message, `no "title" column`)
+
+	status, _, message = postImport(t, app, web.TodoImportRequest{Format: "csv", Content: "title\nA\n", Mapping: map[string]string{"description": "Notes"}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, `mapping.description: the CSV header has no column "Notes"`, message)
+
+	status, _, message = postImport(t, app, web.TodoImportRequest{Format: "csv", Content: "title\nA\n", Mapping: map[string]string{"owner": "Who"}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "body.mapping.owner: is not an allowed key", message)
+
+	status, _, _ = postImport(t, app, web.TodoImportRequest{Format: "trello", Content: `{"name": "not a board"}`})
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _, _ = postImport(t, app, web.TodoImportRequest{Format: "xlsx", Content: "x"})
+	assert.Equal(t, http.StatusBadRequest, status)
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-app-api/controller"
+	"todo-app-api/lifecycle"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleShutdownDrainsRequestsAndWorkers(t *testing.T) {
+	db := setupTestDB(t)
+	appLifecycle := lifecycle.New()
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/readyz", controller.NewHealthController(setupHealthService(t, db, appLifecycle)).Readiness)
+
+	started := make(chan struct{})
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		return c.SendString("done")
+	})
+
+	response, _ := app.Test(httptest.NewRequest(http.MethodGet, "/readyz", nil), -1)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	flushed := false
+	appLifecycle.Go("flusher", func(ctx context.Context) {
+		<-ctx.Done()
+		flushed = true
+	})
+	hookCalled := false
+	appLifecycle.OnShutdown(func() { hookCalled = true })
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go app.Listener(listener)
+
+	body := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		content, _ := io.ReadAll(resp.Body)
+		body <- string(content)
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, appLifecycle.Shutdown(ctx, app, db))
+
+	// the in-flight request finished before Shutdown returned
+	assert.Equal(t, "done", <-body)
+	assert.True(t, appLifecycle.ShuttingDown())
+	assert.True(t, flushed)
+	assert.Equal(t, map[string]bool{"flusher": false}, appLifecycle.Workers())
+	assert.True(t, hookCalled)
+
+	response, _ = app.Test(httptest.NewRequest(http.MethodGet, "/readyz", nil), -1)
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+
+	sqlDB, _ := db.DB()
+	assert.Error(t, sqlDB.Ping())
+}
+
+func TestLifecycleShutdownGivesUpOnStuckWorkers(t *testing.T) {
+	db := setupTestDB(t)
+	appLifecycle := lifecycle.New()
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+
+	release := make(chan struct{})
+	defer close(release)
+	appLifecycle.Go("stuck", func(ctx context.Context) { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, appLifecycle.Shutdown(ctx, app, db), context.DeadlineExceeded)
+
+	sqlDB, _ := db.DB()
+	assert.Error(t, sqlDB.Ping())
+}
@@ -0,0 +1,106 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todo-app-api/controller"
+	"todo-app-api/exception"
+	"todo-app-api/logging"
+	"todo-app-api/middleware"
+	"todo-app-api/replica"
+	"todo-app-api/repository"
+	"todo-app-api/service"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func decodeLogs(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record), scanner.Text())
+		records = append(records, record)
+	}
+	return records
+}
+
+func setupLoggingApp(t *testing.T, out *bytes.Buffer) *fiber.App {
+	logger := logging.New(out, "info", "json")
+
+	db := setupTestDB(t).Session(&gorm.Session{Logger: logging.NewGormLogger(logger, time.Nanosecond)})
+	todoService := service.NewTodoService(repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db), repository.NewTodoReferenceRepository(db), repository.NewCommentRepository(db), repository.NewOutboxRepository(db), db, replica.NewRouter(db, nil), validator.New())
+	todoController := controller.NewTodoController(todoService)
+
+	app := fiber.New(fiber.Config{ErrorHandler: exception.NewErrorHandler})
+	app.Use(middleware.RequestId())
+	app.Use(middleware.AccessLog(logger))
+	app.Post("/todos", todoController.Create)
+	app.Get("/todos/:todoId", todoController.FindById)
+	return app
+}
+
+func TestRequestIdIsGeneratedOrEchoed(t *testing.T) {
+	app := setupLoggingApp(t, &bytes.Buffer{})
+
+	response, _ := app.Test(httptest.NewRequest(http.MethodGet, "/todos/1", nil), -1)
+	assert.Len(t, response.Header.Get(fiber.HeaderXRequestID), 36)
+
+	request := httptest.NewRequest(http.MethodGet, "/todos/1", nil)
+	request.Header.Set(fiber.HeaderXRequestID, "req-123")
+	response, _ = app.Test(request, -1)
+	assert.Equal(t, "req-123", response.Header.Get(fiber.HeaderXRequestID))
+}
+
+func TestAccessLogRecordsRequest(t *testing.T) {
+	out := &bytes.Buffer{}
+	app := setupLoggingApp(t, out)
+
+	request := httptest.NewRequest(http.MethodGet, "/todos/42", nil)
+	request.Header.Set(fiber.HeaderXRequestID, "req-404")
+	response, _ := app.Test(request, -1)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	records := decodeLogs(t, out)
+	access := records[len(records)-1]
+	assert.Equal(t, "request", access["msg"])
+	assert.Equal(t, "GET", access["method"])
+	assert.Equal(t, "/todos/42", access["path"])
+	assert.Equal(t, "/todos/:todoId", access["route"])
+	assert.Equal(t, float64(404), access["status"])
+	assert.Equal(t, "req-404", access["request_id"])
+	assert.Greater(t, access["bytes_out"], float64(0))
+	assert.Contains(t, access, "latency_ms")
+}
+
+func TestRequestIdReachesRepositoryQueries(t *testing.T) {
+	out := &bytes.Buffer{}
+	app := setupLoggingApp(t, out)
+
+	request := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`{"title":"Log me","description":"traced"}`))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	request.Header.Set(fiber.HeaderXRequestID, "req-create")
+	response, _ := app.Test(request, -1)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	slowQueries := 0
+	for _, record := range decodeLogs(t, out) {
+		assert.Equal(t, "req-create", record["request_id"], record["msg"])
+		if record["msg"] == "slow query" {
+			slowQueries++
+			assert.Equal(t, slog.LevelWarn.String(), record["level"])
+			assert.NotEmpty(t, record["sql"])
+		}
+	}
+	assert.Greater(t, slowQueries, 0)
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app-api/markdown"
+	"todo-app-api/models/domain"
+	"todo-app-api/models/web"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const plan = "Release plan for @Alice and @bob-2, see [the board](https://example.com/board).\n" +
+	"\n" +
+	"- [ ] write the notes\n" +
+	"- [x] tag the build\n" +
+	"  - [ ] push the tag\n" +
+	"\n" +
+	"```\n" +
+	"- [ ] not a task, @nobody\n" +
+	"```\n"
+
+func sendTodo(t *testing.T, app *fiber.App, method string, path string, body string) (int, web.TodoResponseV2) {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response, err := app.Test(request, -1)
+	assert.NoError(t, err)
+
+	var envelope struct {
+		Data web.TodoResponseV2 `json:"data"`
+	}
+	json.NewDecoder(response.Body).Decode(&envelope)
+	return response.StatusCode, envelope.Data
+}
+
+func TestMarkdownRenderIsSanitized(t *testing.T) {
+	html := markdown.Render("**bold** <script>alert(1)</script> [click](javascript:alert(1)) [site](https://example.com)\n\n<img src=x onerror=alert(1)>\n\n- [x] done")
+	assert.Contains(t, html, "<strong>bold</strong>")
+	assert.NotContains(t, html, "<script")
+	assert.NotContains(t, html, "javascript:")
+	assert.NotContains(t, html, "onerror")
+	assert.Contains(t, html, `<a href="https://example.com" rel="nofollow noreferrer noopener" target="_blank">site</a>`)
+	assert.Contains(t, html, `<input checked="" disabled="" type="checkbox">`)
+}
+
+func TestMarkdownTasksAndReferences(t *testing.T) {
+	tasks := markdown.Tasks(plan)
+	if assert.Len(t, tasks, 3) {
+		assert.Equal(t, "write the notes", tasks[0].Text)
+		assert.True(t, tasks[1].Checked)
+		assert.Equal(t, 3, tasks[2].Number)
+	}
+
+	checked, err := markdown.SetTask(plan, 3, true)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Replace(plan, "  - [ ] push", "  - [x] push", 1), checked)
+	unchanged, _ := markdown.SetTask(plan, 2, true)
+	assert.Equal(t, plan, unchanged)
+	_, err = markdown.SetTask(plan, 4, true)
+	assert.ErrorIs(t, err, markdown.ErrTaskNotFound)
+
+	assert.Equal(t, []string{"https://example.com/board", "https://go.dev", "mailto:me@example.com"}, markdown.Links(plan+"\nAlso <https://go.dev>, me@example.com and [x](javascript:alert(1))."))
+	assert.Equal(t, []string{"alice", "bob-2"}, markdown.Mentions(plan+"\nMail me@example.com, `@code` is not a mention."))
+}
+
+func TestRenderAndToggleTasksOverHTTP(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+	body, _ := json.Marshal(web.TodoCreateRequest{Title: "Release", Description: plan})
+	sendTodo(t, app, http.MethodPost, "/v2/todos", string(body))
+
+	_, todo := sendTodo(t, app, http.MethodGet, "/v2/todos/1", "")
+	assert.Empty(t, todo.DescriptionHtml)
+
+	_, todo = sendTodo(t, app, http.MethodGet, "/v2/todos/1?render=html", "")
+	assert.Contains(t, todo.DescriptionHtml, `<a href="https://example.com/board"`)
+	assert.Contains(t, todo.DescriptionHtml, "<li><input disabled=\"\" type=\"checkbox\"> write the notes</li>")
+
+	status, _ := sendTodo(t, app, http.MethodGet, "/v2/todos/1?render=pdf", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, todo = sendTodo(t, app, http.MethodPut, "/v2/todos/1/tasks/1", `{"checked": true}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, todo.Description, "- [x] write the notes\n")
+	assert.Contains(t, todo.DescriptionHtml, "<li><input checked=\"\" disabled=\"\" type=\"checkbox\"> write the notes</li>")
+	var auditLog domain.AuditLog
+	db.Order("id DESC").First(&auditLog)
+	assert.Equal(t, "todo.task", auditLog.Action)
+
+	status, _ = sendTodo(t, app, http.MethodPut, "/v2/todos/1/tasks/9", `{"checked": true}`)
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = sendTodo(t, app, http.MethodPut, "/v2/todos/1/tasks/1", `{}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// toggling is an update with its own history entry
+	var revisions []domain.TodoRevision
+	db.Where("todo_id = ?", 1).Order("revision ASC").Find(&revisions)
+	if assert.Len(t, revisions, 2) {
+		assert.Equal(t, "description", revisions[1].Changes[0].Field)
+	}
+}
+
+func TestFilterTodosByMentionAndLink(t *testing.T) {
+	app, db, _ := setupAuditApp(t)
+	body, _ := json.Marshal(web.TodoCreateRequest{Title: "Release", Description: plan})
+	sendTodo(t, app, http.MethodPost, "/v2/todos", string(body))
+	body, _ = json.Marshal(web.TodoCreateRequest{Title: "Other", Description: "Ask @carol"})
+	sendTodo(t, app, http.MethodPost, "/v2/todos", string(body))
+
+	findTitles := func(query string) []string {
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/v2/todos?"+query, nil), -1)
+		assert.NoError(t, err)
+		var envelope struct {
+			Data []web.TodoResponseV2 `json:"data"`
+		}
+		json.NewDecoder(response.Body).Decode(&envelope)
+		var titles []string
+		for _, todo := range envelope.Data {
+			titles = append(titles, todo.Title)
+		}
+		return titles
+	}
+
+	assert.Equal(t, []string{"Release"}, findTitles("mention=ALICE"))
+	assert.Equal(t, []string{"Other"}, findTitles("mention=carol"))
+	assert.Equal(t, []string{"Release"}, findTitles("link=https://example.com/board&status=pending"))
+	assert.Empty(t, findTitles("mention=nobody"))
+
+	// references follow the description
+	sendTodo(t, app, http.MethodPut, "/v2/todos/2", `{"title": "Other", "description": "Ask @dave"}`)
+	assert.Empty(t, findTitles("mention=carol"))
+	assert.Equal(t, []string{"Other"}, findTitles("mention=dave"))
+
+	sendTodo(t, app, http.MethodDelete, "/v2/todos/1", "")
+	var references int64
+	db.Model(&domain.TodoReference{}).Where("todo_id = ?", 1).Count(&references)
+	assert.Zero(t, references)
+}
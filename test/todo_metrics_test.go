@@ -0,0 +1,71 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app-api/controller"
+	"todo-app-api/exception"
+	"todo-app-api/metrics"
+	"todo-app-api/replica"
+	"todo-app-api/repository"
+	"todo-app-api/routes"
+	"todo-app-api/service"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupMetricsApp(t *testing.T) *fiber.App {
+	db := setupTestDB(t)
+	appMetrics := metrics.New()
+	assert.NoError(t, db.Use(appMetrics.GormPlugin()))
+	assert.NoError(t, appMetrics.RegisterDB(db, "primary"))
+	assert.NoError(t, appMetrics.RegisterTodos(db))
+
+	todoService := service.NewTodoService(repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db), repository.NewTodoReferenceRepository(db), repository.NewCommentRepository(db), repository.NewOutboxRepository(db), db, replica.NewRouter(db, nil), validator.New())
+	todoController := controller.NewTodoController(todoService)
+
+	app := fiber.New(fiber.Config{ErrorHandler: exception.NewErrorHandler})
+	app.Use(appMetrics.Middleware())
+	app.Post("/todos", todoController.Create)
+	app.Get("/todos/:todoId", todoController.FindById)
+	routes.NewMetricsRouter(app, appMetrics.Handler())
+	return app
+}
+
+func scrape(t *testing.T, app *fiber.App) string {
+	response, err := app.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	body, _ := io.ReadAll(response.Body)
+	return string(body)
+}
+
+func TestMetricsExposeHttpDatabaseAndTodoMetrics(t *testing.T) {
+	app := setupMetricsApp(t)
+
+	for _, title := range []string{"first", "second"} {
+		request := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`{"title":"`+title+`","description":"metric"}`))
+		request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		app.Test(request, -1)
+	}
+	app.Test(httptest.NewRequest(http.MethodGet, "/todos/1", nil), -1)
+	app.Test(httptest.NewRequest(http.MethodGet, "/todos/99", nil), -1)
+	app.Test(httptest.NewRequest(http.MethodGet, "/todos/1/unknown", nil), -1)
+
+	body := scrape(t, app)
+
+	assert.Contains(t, body, `http_requests_total{method="POST",route="/todos",status="200"} 2`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/todos/:todoId",status="200"} 1`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/todos/:todoId",status="404"} 1`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/todos/:todoId",status="404"} 1`)
+	assert.Contains(t, body, `db_query_duration_seconds_count{operation="create",table="todos"} 2`)
+	assert.Contains(t, body, `go_sql_max_open_connections{db_name="primary"}`)
+	assert.Contains(t, body, `todos{status="pending"} 2`)
+}
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"todo-app-api/command"
+	"todo-app-api/migration"
+	"todo-app-api/models/domain"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func openEmptyDB(t *testing.T) *gorm.DB {
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	return db
+}
+
+func TestMigrationsLoadForEveryDriver(t *testing.T) {
+	var versions [][]int64
+	for _, driver := range []string{"postgres", "sqlite", "mysql"} {
+		migrations, err := migration.Load(driver)
+		assert.NoError(t, err, driver)
+
+		var driverVersions []int64
+		for _, m := range migrations {
+			assert.NotEmpty(t, m.Down, "%s %d has no down script", driver, m.Version)
+			driverVersions = append(driverVersions, m.Version)
+		}
+		versions = append(versions, driverVersions)
+	}
+	assert.Equal(t, versions[0], versions[1])
+	assert.Equal(t, versions[0], versions[2])
+}
+
+func TestMigrationsMatchModels(t *testing.T) {
+	db := setupTestDB(t)
+
+	models := []interface{}{
+		&domain.Todo{}, &domain.TodoRevision{}, &domain.AuditLog{},
+		&domain.WebhookSubscription{}, &domain.WebhookDelivery{}, &domain.OutboxMessage{},
+	}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		assert.NoError(t, stmt.Parse(model))
+		assert.True(t, db.Migrator().HasTable(model), stmt.Schema.Table)
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || field.IgnoreMigration {
+				continue
+			}
+			assert.True(t, db.Migrator().HasColumn(model, field.DBName), "%s.%s", stmt.Schema.Table, field.DBName)
+		}
+		for _, index := range stmt.Schema.ParseIndexes() {
+			assert.True(t, db.Migrator().HasIndex(model, index.Name), "%s index %s", stmt.Schema.Table, index.Name)
+		}
+	}
+}
+
+func TestMigratorUpDownToAndStatus(t *testing.T) {
+	db := openEmptyDB(t)
+	ctx := context.Background()
+	migrator, err := migration.NewMigrator(db)
+	assert.NoError(t, err)
+	latest := migrator.Migrations[len(migrator.Migrations)-1].Version
+
+	ran, err := migrator.To(ctx, 2)
+	assert.NoError(t, err)
+	assert.Len(t, ran, 2)
+	assert.True(t, db.Migrator().HasTable("todo_revisions"))
+	assert.False(t, db.Migrator().HasTable("audit_logs"))
+
+	pending, err := migrator.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, pending, int(latest)-2)
+
+	var out bytes.Buffer
+	assert.NoError(t, command.Migrate(ctx, migrator, []string{"up"}, &out))
+	assert.Contains(t, out.String(), "migrated 0003_create_audit_logs")
+
+	out.Reset()
+	assert.NoError(t, command.Migrate(ctx, migrator, []string{"status"}, &out))
+	assert.Contains(t, out.String(), "0001_create_todos\tapplied")
+
+	out.Reset()
+	assert.NoError(t, command.Migrate(ctx, migrator, []string{"down"}, &out))
+	assert.Equal(t, fmt.Sprintf("migrated %04d_%s\n", latest, migrator.Migrations[len(migrator.Migrations)-1].Name), out.String())
+	assert.NoError(t, command.Migrate(ctx, migrator, []string{"to", "7"}, &out))
+	assert.False(t, db.Migrator().HasTable("comments"))
+
+	ran, err = migrator.To(ctx, 0)
+	assert.NoError(t, err)
+	assert.Len(t, ran, 7)
+	assert.False(t, db.Migrator().HasTable("todos"))
+
+	assert.Error(t, command.Migrate(ctx, migrator, []string{"to", "abc"}, &out))
+	assert.Error(t, command.Migrate(ctx, migrator, []string{"sideways"}, &out))
+	_, err = migrator.To(ctx, 999)
+	assert.EqualError(t, err, "unknown migration version 999")
+}
+
+func TestMigratorDetectsEditedMigration(t *testing.T) {
+	db := openEmptyDB(t)
+	ctx := context.Background()
+	migrator, _ := migration.NewMigrator(db)
+
+	_, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+
+	db.Model(&migration.SchemaMigration{}).Where("version = ?", 1).Update("checksum", "tampered")
+	_, err = migrator.Status(ctx)
+	assert.ErrorContains(t, err, "checksum mismatch for migration 1_create_todos")
+}
+
+func TestEnsureSchemaRefusesOrAutoMigrates(t *testing.T) {
+	db := openEmptyDB(t)
+	ctx := context.Background()
+	migrator, _ := migration.NewMigrator(db)
+
+	var out bytes.Buffer
+	err := command.EnsureSchema(ctx, migrator, false, &out)
+	assert.True(t, errors.Is(err, migration.ErrSchemaBehind))
+
+	assert.NoError(t, command.EnsureSchema(ctx, migrator, true, &out))
+	assert.NoError(t, command.EnsureSchema(ctx, migrator, false, &out))
+}
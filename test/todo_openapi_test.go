@@ -0,0 +1,132 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"todo-app-api/openapi"
+	"todo-app-api/routes"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func fetchSpec(t *testing.T, app *fiber.App) map[string]interface{} {
+	response, err := app.Test(httptest.NewRequest(http.MethodGet, "/openapi.json", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, fiber.MIMEApplicationJSON, response.Header.Get(fiber.HeaderContentType))
+
+	var spec map[string]interface{}
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&spec))
+	return spec
+}
+
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	app, _, _ := setupAuditApp(t)
+	routes.NewMetricsRouter(app, func(c *fiber.Ctx) error { return nil })
+
+	var registered []string
+	for _, route := range app.GetRoutes(true) {
+		// fiber adds a HEAD route for every GET
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+		registered = append(registered, route.Method+" "+openapi.Path(route.Path))
+	}
+
+	var documented []string
+	for path, item := range fetchSpec(t, app)["paths"].(map[string]interface{}) {
+		for method := range item.(map[string]interface{}) {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	sort.Strings(registered)
+	sort.Strings(documented)
+	assert.Equal(t, registered, documented, "routes and routes.Operations have drifted apart")
+}
+
+func TestOpenAPITranslatesValidatorTags(t *testing.T) {
+	spec := routes.OpenAPI("test")
+	assert.Equal(t, "3.1.0", spec["openapi"])
+
+	schemas := spec["components"].(map[string]any)["schemas"].(map[string]openapi.Schema)
+
+	create := schemas["TodoCreateRequest"]
+	assert.Equal(t, []string{"title", "description"}, create["required"])
+	title := create["properties"].(map[string]openapi.Schema)["title"]
+	assert.Equal(t, 2, title["minLength"])
+	assert.Equal(t, 200, title["maxLength"])
+	description := create["properties"].(map[string]openapi.Schema)["description"]
+	assert.Equal(t, 1, description["minLength"], "required strings may not be empty")
+	// omitempty lets the empty string through besides the enum
+	status := create["properties"].(map[string]openapi.Schema)["status"]
+	assert.Equal(t, []openapi.Schema{{"enum": []any{"pending", "done"}}, {"const": ""}}, status["anyOf"])
+
+	update := schemas["TodoUpdateRequest"]
+	assert.NotContains(t, update["properties"], "Id", "the id comes from the path")
+
+	webhook := schemas["WebhookCreateRequest"]["properties"].(map[string]openapi.Schema)
+	assert.Equal(t, "uri", webhook["url"]["format"])
+	assert.Equal(t, 1, webhook["events"]["minItems"])
+	assert.Equal(t, []any{"todo.created", "todo.updated", "todo.completed", "todo.deleted"}, webhook["events"]["items"].(openapi.Schema)["enum"])
+
+	delivery := schemas["WebhookDeliveryResponse"]["properties"].(map[string]openapi.Schema)
+	assert.Equal(t, []any{"string", "null"}, delivery["delivered_at"]["type"])
+
+	assert.Contains(t, schemas, "WebResponse")
+	assert.Contains(t, schemas, "ErrorResponse")
+}
+
+func TestOpenAPIDocumentsEnvelopeAndQueryParameters(t *testing.T) {
+	app, _, _ := setupAuditApp(t)
+	paths := fetchSpec(t, app)["paths"].(map[string]interface{})
+
+	errorSchema := func(path string) interface{} {
+		getTodo := paths[path].(map[string]interface{})["get"].(map[string]interface{})
+		responses := getTodo["responses"].(map[string]interface{})
+		assert.Contains(t, responses, "200")
+		return responses["404"].(map[string]interface{})["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"].(map[string]interface{})["$ref"]
+	}
+	assert.Equal(t, "#/components/schemas/ErrorResponse", errorSchema("/v1/todos/{todoId}"))
+	assert.Equal(t, "#/components/schemas/ErrorResponseV2", errorSchema("/v2/todos/{todoId}"))
+	assert.Equal(t, true, paths["/v1/todos/{todoId}"].(map[string]interface{})["get"].(map[string]interface{})["deprecated"])
+	assert.NotContains(t, paths["/v2/todos/{todoId}"].(map[string]interface{})["get"], "deprecated")
+
+	var names []string
+	for _, parameter := range paths["/v1/audit"].(map[string]interface{})["get"].(map[string]interface{})["parameters"].([]interface{}) {
+		parameter := parameter.(map[string]interface{})
+		names = append(names, parameter["name"].(string))
+		if parameter["name"] == "size" {
+			bounds := parameter["schema"].(map[string]interface{})["anyOf"].([]interface{})[0].(map[string]interface{})
+			assert.Equal(t, float64(1), bounds["minimum"])
+			assert.Equal(t, float64(100), bounds["maximum"])
+		}
+	}
+	assert.Equal(t, []string{"actor", "action", "resource", "outcome", "from", "to", "page", "size"}, names)
+}
+
+func TestOpenAPIReferencePage(t *testing.T) {
+	app, _, _ := setupAuditApp(t)
+
+	response, err := app.Test(httptest.NewRequest(http.MethodGet, "/docs", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	body, _ := io.ReadAll(response.Body)
+	assert.Contains(t, string(body), `spec-url="/openapi.json"`)
+	// Redoc is served by the API itself, not loaded from a CDN
+	assert.Contains(t, string(body), `<script src="/assets/scripts/redoc.standalone.js">`)
+	assert.NotContains(t, string(body), "https://")
+
+	for _, target := range []string{"/assets/scripts/README.md", "/assets/styles/redoc.standalone.js", "/assets/scripts/..%2Fassets.go"} {
+		response, err = app.Test(httptest.NewRequest(http.MethodGet, target, nil), -1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode, target)
+	}
+}
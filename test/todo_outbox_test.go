@@ -0,0 +1,175 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-app-api/event"
+	"todo-app-api/models/domain"
+	"todo-app-api/models/web"
+	"todo-app-api/outbox"
+	"todo-app-api/replica"
+	"todo-app-api/repository"
+	"todo-app-api/service"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type recordingPublisher struct {
+	events []event.Event
+	failOn map[string]bool
+}
+
+func (publisher *recordingPublisher) Publish(ctx context.Context, evt event.Event) error {
+	if publisher.failOn[evt.Id] {
+		return errors.New("broker unavailable")
+	}
+	publisher.events = append(publisher.events, evt)
+	return nil
+}
+
+func setupOutbox(t *testing.T) (service.TodoService, *outbox.Relay, *recordingPublisher, *gorm.DB) {
+	db := setupTestDB(t)
+	outboxRepository := repository.NewOutboxRepository(db)
+	publisher := &recordingPublisher{failOn: map[string]bool{}}
+
+	todoService := service.NewTodoService(repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db), repository.NewTodoReferenceRepository(db), repository.NewCommentRepository(db), outboxRepository, db, replica.NewRouter(db, nil), validator.New())
+	return todoService, outbox.NewRelay(outboxRepository, db, publisher), publisher, db
+}
+
+func TestOutboxWritesEventsWithTheChange(t *testing.T) {
+	todoService, _, _, db := setupOutbox(t)
+	ctx := context.Background()
+
+	created := todoService.Create(ctx, web.TodoCreateRequest{Title: "Outbox", Description: "d"})
+	todoService.Delete(ctx, created.Id)
+
+	// a failed change leaves nothing behind in the outbox
+	assert.Panics(t, func() {
+		todoService.Update(ctx, web.TodoUpdateRequest{Id: created.Id, Title: "Gone", Description: "d"})
+	})
+
+	var messages []domain.OutboxMessage
+	db.Order("id ASC").Find(&messages)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, event.TodoCreated, messages[0].EventType)
+	assert.Equal(t, event.TodoDeleted, messages[1].EventType)
+	assert.Nil(t, messages[0].PublishedAt)
+}
+
+func TestOutboxRelayKeepsOrderPerTodoAndRetries(t *testing.T) {
+	todoService, relay, publisher, db := setupOutbox(t)
+	ctx := context.Background()
+
+	first := todoService.Create(ctx, web.TodoCreateRequest{Title: "First", Description: "d"})
+	todoService.Update(ctx, web.TodoUpdateRequest{Id: first.Id, Title: "First", Description: "d2", Status: "pending"})
+	second := todoService.Create(ctx, web.TodoCreateRequest{Title: "Second", Description: "d"})
+
+	var head domain.OutboxMessage
+	db.Order("id ASC").First(&head)
+	publisher.failOn[head.EventId] = true
+	// retry right away
+	relay.BaseBackoff = 0
+
+	// the failed head blocks the first todo, the second todo is unaffected
+	assert.Equal(t, 1, relay.RelayBatch(ctx))
+	assert.Equal(t, 0, relay.RelayBatch(ctx))
+	assert.Len(t, publisher.events, 1)
+	assert.Equal(t, second.Id, publisher.events[0].TodoId)
+
+	db.First(&head, head.Id)
+	assert.Equal(t, 2, head.Attempts)
+	assert.Equal(t, "broker unavailable", head.LastError)
+
+	delete(publisher.failOn, head.EventId)
+	assert.Equal(t, 1, relay.RelayBatch(ctx))
+	assert.Equal(t, 1, relay.RelayBatch(ctx))
+	assert.Equal(t, 0, relay.RelayBatch(ctx))
+
+	assert.Len(t, publisher.events, 3)
+	assert.Equal(t, event.TodoCreated, publisher.events[1].Type)
+	assert.Equal(t, event.TodoUpdated, publisher.events[2].Type)
+	assert.Equal(t, first.Id, publisher.events[2].TodoId)
+}
+
+func TestOutboxRelayBacksOffAndParksFailingMessages(t *testing.T) {
+	todoService, relay, publisher, db := setupOutbox(t)
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	relay.Now = func() time.Time { return now }
+	relay.MaxAttempts = 3
+	relay.BaseBackoff = time.Minute
+
+	created := todoService.Create(ctx, web.TodoCreateRequest{Title: "Stuck", Description: "d"})
+	todoService.Update(ctx, web.TodoUpdateRequest{Id: created.Id, Title: "Stuck", Description: "d2", Status: "pending"})
+
+	var head domain.OutboxMessage
+	db.Order("id ASC").First(&head)
+	publisher.failOn[head.EventId] = true
+
+	assert.Equal(t, 0, relay.RelayBatch(ctx))
+	db.First(&head, head.Id)
+	assert.Equal(t, 1, head.Attempts)
+	assert.Equal(t, now.Add(time.Minute), head.NextAttemptAt.UTC())
+	assert.Nil(t, head.ClaimedUntil)
+
+	// nothing is due before the backoff has passed
+	assert.Equal(t, 0, relay.RelayBatch(ctx))
+	db.First(&head, head.Id)
+	assert.Equal(t, 1, head.Attempts)
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, 0, relay.RelayBatch(ctx))
+	db.First(&head, head.Id)
+	assert.Equal(t, 2, head.Attempts)
+	assert.Equal(t, now.Add(2*time.Minute), head.NextAttemptAt.UTC())
+
+	// the last attempt parks the message and releases the next event
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, 0, relay.RelayBatch(ctx))
+	db.First(&head, head.Id)
+	assert.Equal(t, 3, head.Attempts)
+	assert.NotNil(t, head.FailedAt)
+	assert.Nil(t, head.PublishedAt)
+
+	assert.Equal(t, 1, relay.RelayBatch(ctx))
+	assert.Len(t, publisher.events, 1)
+	assert.Equal(t, event.TodoUpdated, publisher.events[0].Type)
+	assert.Equal(t, 0, relay.RelayBatch(ctx))
+}
+
+func TestOutboxClaimLeasesMessages(t *testing.T) {
+	todoService, _, _, db := setupOutbox(t)
+	ctx := context.Background()
+	outboxRepository := repository.NewOutboxRepository(db)
+	now := time.Now().UTC()
+
+	todoService.Create(ctx, web.TodoCreateRequest{Title: "Leased", Description: "d"})
+
+	claimed := outboxRepository.ClaimUnpublished(ctx, db, 10, now, now.Add(time.Minute))
+	assert.Len(t, claimed, 1)
+
+	// a second relay does not get the claimed message until the lease expires
+	assert.Empty(t, outboxRepository.ClaimUnpublished(ctx, db, 10, now, now.Add(time.Minute)))
+	assert.Len(t, outboxRepository.ClaimUnpublished(ctx, db, 10, now.Add(time.Minute), now.Add(2*time.Minute)), 1)
+}
+
+func TestEventBusFansOutToSubscribers(t *testing.T) {
+	bus := event.NewBus()
+	var received []string
+	bus.Subscribe(func(ctx context.Context, evt event.Event) error {
+		received = append(received, "a:"+evt.Type)
+		return nil
+	})
+	bus.Subscribe(func(ctx context.Context, evt event.Event) error {
+		received = append(received, "b:"+evt.Type)
+		return errors.New("b failed")
+	})
+
+	err := bus.Publish(context.Background(), event.Event{Type: event.TodoCreated})
+	assert.EqualError(t, err, "b failed")
+	assert.Equal(t, []string{"a:todo.created", "b:todo.created"}, received)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	"todo-app-api/migration"
 	"todo-app-api/models/domain"
 	"todo-app-api/repository"
 
@@ -13,7 +14,7 @@ import (
 	"gorm.io/gorm"
 )
 
-// helper to create an in-memory gorm DB and migrate the Todo model
+// helper to create an in-memory gorm DB with the schema built by the embedded migrations
 func setupTestDB(t *testing.T) *gorm.DB {
 	// create a unique in-memory database per test to avoid cross-test pollution
 	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
@@ -22,16 +23,17 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("failed to open test db: %v", err)
 	}
 
-	err = db.AutoMigrate(&domain.Todo{})
+	migrator, err := migration.NewMigrator(db)
 	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 
 	return db
 }
 
-
-
 func TestTodoRepository_SaveAndFindById(t *testing.T) {
 	db := setupTestDB(t)
 	repo := repository.NewTodoRepository(db)
@@ -87,8 +89,31 @@ func TestTodoRepository_FindAll(t *testing.T) {
 		t.Fatalf("commit failed: %v", err)
 	}
 
-	all := repo.FindAll(ctx, db)
+	all := repo.FindAll(ctx, db, domain.TodoFilter{})
 	assert.Len(t, all, 2)
+
+	done := repo.FindAll(ctx, db, domain.TodoFilter{Status: "done"})
+	if assert.Len(t, done, 1) {
+		assert.Equal(t, "Two", done[0].Title)
+	}
+}
+
+func TestTodoRepository_FindEach(t *testing.T) {
+	db := setupTestDB(t)
+	repo := repository.NewTodoRepository(db)
+	ctx := context.Background()
+
+	for _, title := range []string{"One", "Two", "Three"} {
+		repo.Save(ctx, db, domain.Todo{Title: title, Description: "d", Status: "pending"})
+	}
+
+	var titles []string
+	err := repo.FindEach(ctx, db, domain.TodoFilter{Status: "pending"}, func(todo domain.Todo) bool {
+		titles = append(titles, todo.Title)
+		return len(titles) < 2
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"One", "Two"}, titles)
 }
 
 func TestTodoRepository_Delete(t *testing.T) {
@@ -3,9 +3,12 @@ package test
 import (
 	"context"
 	"testing"
+	"time"
 	"todo-app-api/exception"
 	"todo-app-api/models/domain"
 	"todo-app-api/models/web"
+	"todo-app-api/replica"
+	"todo-app-api/repository"
 	"todo-app-api/service"
 
 	"github.com/go-playground/validator/v10"
@@ -38,11 +41,144 @@ func (m *TodoRepositoryMock) FindById(ctx context.Context, tx *gorm.DB, id int)
 	return args.Get(0).(domain.Todo), args.Error(1)
 }
 
-func (m *TodoRepositoryMock) FindAll(ctx context.Context, tx *gorm.DB) []domain.Todo {
-	args := m.Called(ctx, tx)
+func (m *TodoRepositoryMock) FindByIdForUpdate(ctx context.Context, tx *gorm.DB, id int) (domain.Todo, error) {
+	args := m.Called(ctx, tx, id)
+	return args.Get(0).(domain.Todo), args.Error(1)
+}
+
+func (m *TodoRepositoryMock) FindAll(ctx context.Context, tx *gorm.DB, filter domain.TodoFilter) []domain.Todo {
+	args := m.Called(ctx, tx, filter)
+	return args.Get(0).([]domain.Todo)
+}
+
+func (m *TodoRepositoryMock) FindEach(ctx context.Context, tx *gorm.DB, filter domain.TodoFilter, fn func(todo domain.Todo) bool) error {
+	args := m.Called(ctx, tx, filter, fn)
+	return args.Error(0)
+}
+
+func (m *TodoRepositoryMock) FindByTitles(ctx context.Context, tx *gorm.DB, titles []string) []domain.Todo {
+	args := m.Called(ctx, tx, titles)
 	return args.Get(0).([]domain.Todo)
 }
 
+func (m *TodoRepositoryMock) FindByIds(ctx context.Context, tx *gorm.DB, todoIds []int) []domain.Todo {
+	args := m.Called(ctx, tx, todoIds)
+	return args.Get(0).([]domain.Todo)
+}
+
+// TodoRevisionRepositoryStub keeps revisions in memory so service tests don't
+// need to set expectations for history bookkeeping.
+type TodoRevisionRepositoryStub struct {
+	Revisions []domain.TodoRevision
+}
+
+func (s *TodoRevisionRepositoryStub) Save(ctx context.Context, tx *gorm.DB, revision domain.TodoRevision) (domain.TodoRevision, error) {
+	s.Revisions = append(s.Revisions, revision)
+	return revision, nil
+}
+
+func (s *TodoRevisionRepositoryStub) FindByTodoId(ctx context.Context, tx *gorm.DB, todoId int) []domain.TodoRevision {
+	var revisions []domain.TodoRevision
+	for _, revision := range s.Revisions {
+		if revision.TodoId == todoId {
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions
+}
+
+func (s *TodoRevisionRepositoryStub) FindByTodoIds(ctx context.Context, tx *gorm.DB, todoIds []int) []domain.TodoRevision {
+	var revisions []domain.TodoRevision
+	for _, todoId := range todoIds {
+		revisions = append(revisions, s.FindByTodoId(ctx, tx, todoId)...)
+	}
+	return revisions
+}
+
+func (s *TodoRevisionRepositoryStub) FindByRevision(ctx context.Context, tx *gorm.DB, todoId int, revision int) (domain.TodoRevision, error) {
+	for _, r := range s.Revisions {
+		if r.TodoId == todoId && r.Revision == revision {
+			return r, nil
+		}
+	}
+	return domain.TodoRevision{}, gorm.ErrRecordNotFound
+}
+
+func (s *TodoRevisionRepositoryStub) LastRevision(ctx context.Context, tx *gorm.DB, todoId int) int {
+	return len(s.FindByTodoId(ctx, tx, todoId))
+}
+
+func (s *TodoRevisionRepositoryStub) LastRevisions(ctx context.Context, tx *gorm.DB, todoIds []int) map[int]int {
+	last := map[int]int{}
+	for _, todoId := range todoIds {
+		if revision := s.LastRevision(ctx, tx, todoId); revision > 0 {
+			last[todoId] = revision
+		}
+	}
+	return last
+}
+
+type TodoReferenceRepositoryStub struct {
+	References map[int][]domain.TodoReference
+}
+
+func (s *TodoReferenceRepositoryStub) Replace(ctx context.Context, tx *gorm.DB, todoId int, references []domain.TodoReference) {
+	if s.References == nil {
+		s.References = map[int][]domain.TodoReference{}
+	}
+	s.References[todoId] = references
+}
+
+func (s *TodoReferenceRepositoryStub) FindByTodoId(ctx context.Context, tx *gorm.DB, todoId int) []domain.TodoReference {
+	return s.References[todoId]
+}
+
+// CommentRepositoryStub stands in for todos without comments.
+type CommentRepositoryStub struct {
+	repository.CommentRepository
+}
+
+func (s *CommentRepositoryStub) CountByTodoIds(ctx context.Context, tx *gorm.DB, todoIds []int) map[int]int {
+	return map[int]int{}
+}
+
+func (s *CommentRepositoryStub) DeleteByTodoId(ctx context.Context, tx *gorm.DB, todoId int) {
+}
+
+type OutboxRepositoryStub struct {
+	Messages []domain.OutboxMessage
+}
+
+func (s *OutboxRepositoryStub) Save(ctx context.Context, tx *gorm.DB, message domain.OutboxMessage) domain.OutboxMessage {
+	s.Messages = append(s.Messages, message)
+	return message
+}
+
+func (s *OutboxRepositoryStub) ClaimUnpublished(ctx context.Context, tx *gorm.DB, limit int, now time.Time, claimedUntil time.Time) []domain.OutboxMessage {
+	return nil
+}
+
+func (s *OutboxRepositoryStub) MarkPublished(ctx context.Context, tx *gorm.DB, messageId int, publishedAt time.Time) {
+}
+
+func (s *OutboxRepositoryStub) MarkFailed(ctx context.Context, tx *gorm.DB, messageId int, reason string, nextAttemptAt time.Time) {
+}
+
+func (s *OutboxRepositoryStub) MarkDead(ctx context.Context, tx *gorm.DB, messageId int, reason string, failedAt time.Time) {
+}
+
+func (s *OutboxRepositoryStub) FindAfter(ctx context.Context, tx *gorm.DB, afterId int, limit int) []domain.OutboxMessage {
+	return nil
+}
+
+func (s *OutboxRepositoryStub) FindByIds(ctx context.Context, tx *gorm.DB, messageIds []int) []domain.OutboxMessage {
+	return nil
+}
+
+func (s *OutboxRepositoryStub) FindLatest(ctx context.Context, tx *gorm.DB, limit int) []domain.OutboxMessage {
+	return nil
+}
+
 func TestServiceCreateSuccess(t *testing.T) {
 	mockRepo := new(TodoRepositoryMock)
 
@@ -63,7 +199,7 @@ func TestServiceCreateSuccess(t *testing.T) {
 	}
 	mockRepo.On("Save", mock.Anything, mock.Anything, mock.AnythingOfType("domain.Todo")).Return(expected)
 
-	todoService := service.NewTodoService(mockRepo, db, validate)
+	todoService := service.NewTodoService(mockRepo, new(TodoRevisionRepositoryStub), new(TodoReferenceRepositoryStub), new(CommentRepositoryStub), new(OutboxRepositoryStub), db, replica.NewRouter(db, nil), validate)
 	result := todoService.Create(context.Background(), request)
 
 	assert.Equal(t, "Test", result.Title)
@@ -75,7 +211,7 @@ func TestServiceCreateFailed(t *testing.T) {
 	mockRepo := new(TodoRepositoryMock)
 	validate := validator.New()
 	db, _ := gorm.Open(sqlite.Open(":memory"), &gorm.Config{})
-	todoService := service.NewTodoService(mockRepo, db, validate)
+	todoService := service.NewTodoService(mockRepo, new(TodoRevisionRepositoryStub), new(TodoReferenceRepositoryStub), new(CommentRepositoryStub), new(OutboxRepositoryStub), db, replica.NewRouter(db, nil), validate)
 
 	request := web.TodoCreateRequest{
 		Title: "",
@@ -91,7 +227,7 @@ func TestServiceUpdateSuccess(t *testing.T) {
 	db, _ := gorm.Open(sqlite.Open(":memory"), &gorm.Config{})
 	validate := validator.New()
 
-	todoService := service.NewTodoService(mockRepo, db, validate)
+	todoService := service.NewTodoService(mockRepo, new(TodoRevisionRepositoryStub), new(TodoReferenceRepositoryStub), new(CommentRepositoryStub), new(OutboxRepositoryStub), db, replica.NewRouter(db, nil), validate)
 
 	existing := domain.Todo{
 		Id:          1,
@@ -114,7 +250,7 @@ func TestServiceUpdateSuccess(t *testing.T) {
 		Status:      "done",
 	}
 
-	mockRepo.On("FindById", mock.Anything, mock.Anything, 1).Return(existing, nil)
+	mockRepo.On("FindByIdForUpdate", mock.Anything, mock.Anything, 1).Return(existing, nil)
 	mockRepo.On("Update", mock.Anything, mock.Anything, mock.AnythingOfType("domain.Todo")).Return(updated)
 
 	result := todoService.Update(context.Background(), request)
@@ -138,9 +274,9 @@ func TestServiceUpdateFailed(t *testing.T) {
 		Description: "Test Description",
 		Status:      "done",
 	}
-	mockRepo.On("FindById", mock.Anything, mock.Anything, 99).Return(domain.Todo{}, gorm.ErrRecordNotFound)
+	mockRepo.On("FindByIdForUpdate", mock.Anything, mock.Anything, 99).Return(domain.Todo{}, gorm.ErrRecordNotFound)
 
-	todoService := service.NewTodoService(mockRepo, db, validate)
+	todoService := service.NewTodoService(mockRepo, new(TodoRevisionRepositoryStub), new(TodoReferenceRepositoryStub), new(CommentRepositoryStub), new(OutboxRepositoryStub), db, replica.NewRouter(db, nil), validate)
 
 	assert.PanicsWithValue(t, exception.NotFoundError{Message: "todo not found"}, func() {
 		todoService.Update(context.Background(), request)
@@ -152,7 +288,7 @@ func TestServiceDeleteSuccess(t *testing.T) {
 	db, _ := gorm.Open(sqlite.Open(":memory"), &gorm.Config{})
 	validate := validator.New()
 
-	todoService := service.NewTodoService(mockRepo, db, validate)
+	todoService := service.NewTodoService(mockRepo, new(TodoRevisionRepositoryStub), new(TodoReferenceRepositoryStub), new(CommentRepositoryStub), new(OutboxRepositoryStub), db, replica.NewRouter(db, nil), validate)
 
 	existing := domain.Todo{
 		Id:          1,
@@ -161,7 +297,7 @@ func TestServiceDeleteSuccess(t *testing.T) {
 		Status:      "pending",
 	}
 
-	mockRepo.On("FindById", mock.Anything, mock.Anything, 1).Return(existing, nil)
+	mockRepo.On("FindByIdForUpdate", mock.Anything, mock.Anything, 1).Return(existing, nil)
 	mockRepo.On("Delete", mock.Anything, mock.Anything, mock.AnythingOfType("domain.Todo")).Return()
 
 	todoService.Delete(context.Background(), 1)
@@ -174,9 +310,9 @@ func TestServiceDeleteFailed(t *testing.T) {
 	db, _ := gorm.Open(sqlite.Open(":memory"), &gorm.Config{})
 	validate := validator.New()
 
-	todoService := service.NewTodoService(mockRepo, db, validate)
+	todoService := service.NewTodoService(mockRepo, new(TodoRevisionRepositoryStub), new(TodoReferenceRepositoryStub), new(CommentRepositoryStub), new(OutboxRepositoryStub), db, replica.NewRouter(db, nil), validate)
 
-	mockRepo.On("FindById", mock.Anything, mock.Anything, 99).Return(domain.Todo{}, gorm.ErrRecordNotFound)
+	mockRepo.On("FindByIdForUpdate", mock.Anything, mock.Anything, 99).Return(domain.Todo{}, gorm.ErrRecordNotFound)
 
 	assert.PanicsWithValue(t, exception.NotFoundError{Message: "todo not found"}, func() {
 		todoService.Delete(context.Background(), 99)
@@ -188,7 +324,7 @@ func TestServiceFindByIdSuccess(t *testing.T) {
 	db, _ := gorm.Open(sqlite.Open(":memory"), &gorm.Config{})
 	validate := validator.New()
 
-	todoService := service.NewTodoService(mockRepo, db, validate)
+	todoService := service.NewTodoService(mockRepo, new(TodoRevisionRepositoryStub), new(TodoReferenceRepositoryStub), new(CommentRepositoryStub), new(OutboxRepositoryStub), db, replica.NewRouter(db, nil), validate)
 
 	existing := domain.Todo{
 		Id:          1,
@@ -213,7 +349,7 @@ func TestServiceFindByIdFailed(t *testing.T) {
 	db, _ := gorm.Open(sqlite.Open(":memory"), &gorm.Config{})
 	validate := validator.New()
 
-	todoService := service.NewTodoService(mockRepo, db, validate)
+	todoService := service.NewTodoService(mockRepo, new(TodoRevisionRepositoryStub), new(TodoReferenceRepositoryStub), new(CommentRepositoryStub), new(OutboxRepositoryStub), db, replica.NewRouter(db, nil), validate)
 
 	mockRepo.On("FindById", mock.Anything, mock.Anything, 99).Return(domain.Todo{}, gorm.ErrRecordNotFound)
 
@@ -227,13 +363,13 @@ func TestServiceFindAllSuccess(t *testing.T) {
 	db, _ := gorm.Open(sqlite.Open(":memory"), &gorm.Config{})
 	validate := validator.New()
 
-	todoService := service.NewTodoService(mockRepo, db, validate)
+	todoService := service.NewTodoService(mockRepo, new(TodoRevisionRepositoryStub), new(TodoReferenceRepositoryStub), new(CommentRepositoryStub), new(OutboxRepositoryStub), db, replica.NewRouter(db, nil), validate)
 
 	existing := []domain.Todo{}
 
-	mockRepo.On("FindAll", mock.Anything, mock.Anything).Return(existing)
+	mockRepo.On("FindAll", mock.Anything, mock.Anything, domain.TodoFilter{}).Return(existing)
 
-	todoService.FindAll(context.Background())
+	todoService.FindAll(context.Background(), web.TodoFilterRequest{})
 
 	assert.Len(t, existing, 0)
 
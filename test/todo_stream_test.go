@@ -0,0 +1,264 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"todo-app-api/controller"
+	"todo-app-api/event"
+	"todo-app-api/models/domain"
+	"todo-app-api/models/web"
+	"todo-app-api/outbox"
+	"todo-app-api/replica"
+	"todo-app-api/repository"
+	"todo-app-api/service"
+	"todo-app-api/stream"
+
+	"github.com/fasthttp/websocket"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamHubResumeAndBackpressure(t *testing.T) {
+	hub := stream.NewHub(3, 1)
+	ctx := context.Background()
+
+	// sequences come from the outbox: gaps and out of order across todos
+	for _, sequence := range []uint64{10, 12, 11, 14} {
+		hub.Publish(ctx, event.Event{Type: event.TodoCreated, TodoId: int(sequence), Sequence: sequence})
+	}
+	// a redelivery is not buffered twice
+	hub.Publish(ctx, event.Event{Type: event.TodoCreated, TodoId: 14, Sequence: 14})
+
+	// 12, 11 and 14 are still buffered; the backlog follows publication order
+	_, backlog, complete := hub.Subscribe(12, nil)
+	assert.True(t, complete)
+	if assert.Len(t, backlog, 2) {
+		assert.Equal(t, uint64(11), backlog[0].Id)
+		assert.Equal(t, uint64(14), backlog[1].Id)
+	}
+
+	// an evicted id is unknown
+	_, _, complete = hub.Subscribe(0, nil)
+	assert.True(t, complete)
+	_, backlog, complete = hub.Subscribe(10, nil)
+	assert.False(t, complete)
+	assert.Len(t, backlog, 3)
+	_, backlog, complete = hub.Subscribe(99, nil)
+	assert.False(t, complete)
+	assert.Empty(t, backlog)
+
+	slow, _, _ := hub.Subscribe(0, func(evt event.Event) bool { return evt.TodoId == 7 })
+	hub.Publish(ctx, event.Event{Type: event.TodoUpdated, TodoId: 7, Sequence: 15})
+	hub.Publish(ctx, event.Event{Type: event.TodoUpdated, TodoId: 8, Sequence: 16})
+	hub.Publish(ctx, event.Event{Type: event.TodoUpdated, TodoId: 7, Sequence: 17})
+
+	select {
+	case <-slow.Lagged:
+	default:
+		t.Fatal("expected slow subscriber to be dropped")
+	}
+	message := <-slow.Messages
+	assert.Equal(t, 7, message.Event.TodoId)
+}
+
+func TestStreamTailersFeedEveryInstance(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	outboxRepository := repository.NewOutboxRepository(db)
+	todoService := service.NewTodoService(repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db), repository.NewTodoReferenceRepository(db), repository.NewCommentRepository(db), outboxRepository, db, replica.NewRouter(db, nil), validator.New())
+
+	// two instances, only the first of which relays
+	hubA, hubB := stream.NewHub(100, 16), stream.NewHub(100, 16)
+	tailerA, tailerB := stream.NewTailer(outboxRepository, db, hubA), stream.NewTailer(outboxRepository, db, hubB)
+	relay := outbox.NewRelay(outboxRepository, db, tailerA)
+	tailerA.Poll(ctx)
+	tailerB.Poll(ctx)
+
+	subscriptionB, _, _ := hubB.Subscribe(0, nil)
+	created := todoService.Create(ctx, web.TodoCreateRequest{Title: "Everywhere", Description: "d"})
+	assert.Equal(t, 1, relay.RelayBatch(ctx))
+	assert.Equal(t, 1, tailerA.Poll(ctx))
+	assert.Equal(t, 1, tailerB.Poll(ctx))
+
+	message := <-subscriptionB.Messages
+	assert.Equal(t, created.Id, message.Event.TodoId)
+
+	// the ids are the same, so a client can resume on the other instance
+	todoService.Update(ctx, web.TodoUpdateRequest{Id: created.Id, Title: "Everywhere", Description: "d2", Status: "pending"})
+	tailerB.Poll(ctx)
+	_, backlog, complete := hubB.Subscribe(message.Id, nil)
+	assert.True(t, complete)
+	assert.Len(t, backlog, 1)
+
+	// a restarted instance loads the recent history
+	restarted := stream.NewHub(100, 16)
+	stream.NewTailer(outboxRepository, db, restarted).Poll(ctx)
+	_, backlog, complete = restarted.Subscribe(message.Id, nil)
+	assert.True(t, complete)
+	assert.Len(t, backlog, 1)
+}
+
+func TestStreamTailerFillsGapsLeftByOpenTransactions(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	outboxRepository := repository.NewOutboxRepository(db)
+	hub := stream.NewHub(100, 16)
+	tailer := stream.NewTailer(outboxRepository, db, hub)
+	now := time.Now()
+	tailer.Now = func() time.Time { return now }
+	tailer.Poll(ctx)
+
+	save := func(id int) {
+		payload, _ := json.Marshal(event.Event{Id: fmt.Sprint(id), Type: event.TodoCreated, TodoId: id})
+		outboxRepository.Save(ctx, db, domain.OutboxMessage{Id: id, AggregateType: "todo", AggregateId: id, EventId: fmt.Sprint(id), EventType: event.TodoCreated, Payload: string(payload)})
+	}
+	subscription, _, _ := hub.Subscribe(0, nil)
+	received := func() (ids []uint64) {
+		for {
+			select {
+			case message := <-subscription.Messages:
+				ids = append(ids, message.Id)
+			default:
+				return ids
+			}
+		}
+	}
+
+	// 2 and 3 are written but not committed yet when 4 is read
+	save(1)
+	save(4)
+	tailer.Poll(ctx)
+	assert.Equal(t, []uint64{1, 4}, received())
+
+	save(2)
+	tailer.Poll(ctx)
+	assert.Equal(t, []uint64{2}, received())
+
+	// 3 rolled back; once given up on, it is not looked for again
+	now = now.Add(tailer.GapTimeout + time.Second)
+	tailer.Poll(ctx)
+	save(3)
+	save(5)
+	tailer.Poll(ctx)
+	assert.Equal(t, []uint64{5}, received())
+}
+
+type streamFixture struct {
+	todoService service.TodoService
+	relay       *outbox.Relay
+	baseUrl     string
+}
+
+func setupStreamFixture(t *testing.T) *streamFixture {
+	db := setupTestDB(t)
+	outboxRepository := repository.NewOutboxRepository(db)
+	hub := stream.NewHub(100, 16)
+
+	streamController := controller.NewStreamController(hub, 50*time.Millisecond)
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/todos/stream", streamController.Events)
+	app.Get("/todos/ws", streamController.WebSocket)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go app.Listener(listener)
+	t.Cleanup(func() { app.ShutdownWithTimeout(time.Second) })
+
+	return &streamFixture{
+		todoService: service.NewTodoService(repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db), repository.NewTodoReferenceRepository(db), repository.NewCommentRepository(db), outboxRepository, db, replica.NewRouter(db, nil), validator.New()),
+		relay:       outbox.NewRelay(outboxRepository, db, hub),
+		baseUrl:     listener.Addr().String(),
+	}
+}
+
+func readServerSentEvent(t *testing.T, reader *bufio.Reader) map[string]string {
+	fields := map[string]string{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if len(fields) > 0 {
+				return fields
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			fields["comment"] = line
+			continue
+		}
+		key, value, _ := strings.Cut(line, ": ")
+		fields[key] = value
+	}
+}
+
+func TestStreamServerSentEventsResumeFromLastEventId(t *testing.T) {
+	fixture := setupStreamFixture(t)
+	ctx := context.Background()
+
+	created := fixture.todoService.Create(ctx, web.TodoCreateRequest{Title: "Stream", Description: "d"})
+	fixture.todoService.Update(ctx, web.TodoUpdateRequest{Id: created.Id, Title: "Stream", Description: "d2", Status: "pending"})
+	fixture.relay.RelayBatch(ctx)
+	fixture.relay.RelayBatch(ctx)
+
+	request, _ := http.NewRequest(http.MethodGet, "http://"+fixture.baseUrl+"/todos/stream", nil)
+	request.Header.Set("Last-Event-ID", "1")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer response.Body.Close()
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(response.Body)
+	fields := readServerSentEvent(t, reader)
+	assert.Equal(t, "2", fields["id"])
+	assert.Equal(t, event.TodoUpdated, fields["event"])
+
+	var evt event.Event
+	assert.NoError(t, json.Unmarshal([]byte(fields["data"]), &evt))
+	assert.Equal(t, "d2", evt.Data.Description)
+
+	// nothing else is pending, so the next frame is a heartbeat
+	fields = readServerSentEvent(t, reader)
+	assert.Equal(t, ": ping", fields["comment"])
+}
+
+func TestStreamWebSocketPushesLiveEvents(t *testing.T) {
+	fixture := setupStreamFixture(t)
+	ctx := context.Background()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+fixture.baseUrl+"/todos/ws", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	// give the handler a moment to subscribe before publishing
+	time.Sleep(50 * time.Millisecond)
+	created := fixture.todoService.Create(ctx, web.TodoCreateRequest{Title: "Socket", Description: "d"})
+	fixture.relay.RelayBatch(ctx)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var message struct {
+		Id    uint64      `json:"id"`
+		Type  string      `json:"type"`
+		Event event.Event `json:"event"`
+	}
+	assert.NoError(t, conn.ReadJSON(&message))
+	assert.Equal(t, uint64(1), message.Id)
+	assert.Equal(t, event.TodoCreated, message.Type)
+	assert.Equal(t, created.Id, message.Event.TodoId)
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"todo-app-api/controller"
+	"todo-app-api/exception"
+	"todo-app-api/middleware"
+	"todo-app-api/replica"
+	"todo-app-api/repository"
+	"todo-app-api/service"
+	"todo-app-api/tracing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const incomingTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func setupTracingApp(t *testing.T) (*fiber.App, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	db := setupTestDB(t)
+	assert.NoError(t, db.Use(tracing.GormPlugin()))
+
+	todoService := service.NewTodoService(repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db), repository.NewTodoReferenceRepository(db), repository.NewCommentRepository(db), repository.NewOutboxRepository(db), db, replica.NewRouter(db, nil), validator.New())
+	todoController := controller.NewTodoController(todoService)
+
+	app := fiber.New(fiber.Config{ErrorHandler: exception.NewErrorHandler})
+	app.Use(middleware.Trace())
+	app.Post("/todos", todoController.Create)
+	app.Get("/todos/:todoId", todoController.FindById)
+	return app, exporter
+}
+
+func spanByName(spans tracetest.SpanStubs, name string) *tracetest.SpanStub {
+	for i := range spans {
+		if spans[i].Name == name {
+			return &spans[i]
+		}
+	}
+	return nil
+}
+
+func TestTracingBuildsSpanTreeFromIncomingTraceparent(t *testing.T) {
+	app, exporter := setupTracingApp(t)
+
+	request := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`{"title":"Trace me","description":"spans"}`))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	request.Header.Set("traceparent", incomingTraceparent)
+	response, _ := app.Test(request, -1)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	spans := exporter.GetSpans()
+	server := spanByName(spans, "POST /todos")
+	serviceSpan := spanByName(spans, "TodoService.Create")
+	if !assert.NotNil(t, server) || !assert.NotNil(t, serviceSpan) {
+		return
+	}
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.True(t, server.Parent.IsRemote())
+	assert.Equal(t, server.SpanContext.SpanID(), serviceSpan.Parent.SpanID())
+
+	tables := map[string]bool{}
+	for _, span := range spans {
+		if span.Name == "gorm.create" {
+			assert.Equal(t, serviceSpan.SpanContext.SpanID(), span.Parent.SpanID())
+			for _, attr := range span.Attributes {
+				if attr.Key == "db.collection.name" {
+					tables[attr.Value.AsString()] = true
+				}
+			}
+		}
+	}
+	assert.Equal(t, map[string]bool{"todos": true, "todo_revisions": true, "outbox_messages": true}, tables)
+}
+
+func TestTracingAddsTraceIdToErrorResponses(t *testing.T) {
+	app, exporter := setupTracingApp(t)
+
+	request := httptest.NewRequest(http.MethodGet, "/todos/404", nil)
+	request.Header.Set("traceparent", incomingTraceparent)
+	response, _ := app.Test(request, -1)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	var body map[string]interface{}
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&body))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", body["trace_id"])
+
+	serviceSpan := spanByName(exporter.GetSpans(), "TodoService.FindById")
+	if assert.NotNil(t, serviceSpan) {
+		assert.Equal(t, codes.Error, serviceSpan.Status.Code)
+		assert.Equal(t, "todo not found", serviceSpan.Status.Description)
+	}
+}
+
+func TestTracingStdoutExporterWritesToFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := tracing.Setup(context.Background(), tracing.ExporterStdout, file, "todo-app-api", "test")
+	assert.NoError(t, err)
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	_, span := tracing.Tracer().Start(context.Background(), "offline")
+	span.End()
+	assert.NoError(t, shutdown(context.Background()))
+
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"Name":"offline"`)
+
+	_, err = tracing.Setup(context.Background(), "zipkin", "", "todo-app-api", "test")
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app-api/exception"
+	"todo-app-api/middleware"
+	"todo-app-api/models/web"
+	"todo-app-api/openapi"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func sendValidated(t *testing.T, app *fiber.App, method string, target string, body string) (int, web.WebResponse) {
+	var request *http.Request
+	if body == "" {
+		request = httptest.NewRequest(method, target, nil)
+	} else {
+		request = httptest.NewRequest(method, target, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := app.Test(request, -1)
+	assert.NoError(t, err)
+
+	var webResponse web.WebResponse
+	json.NewDecoder(response.Body).Decode(&webResponse)
+	return response.StatusCode, webResponse
+}
+
+func TestValidateRejectsBadParameters(t *testing.T) {
+	app, _, _ := setupAuditApp(t)
+
+	status, response := sendValidated(t, app, http.MethodGet, "/v2/todos/abc", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "BAD REQUEST", response.Status)
+	assert.Equal(t, "path todoId: must be an integer", response.Data)
+
+	status, response = sendValidated(t, app, http.MethodGet, "/v2/audit?size=500&page=x", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "query page: must be an integer; query size: must be at most 100", response.Data)
+
+	// the literal route is not mistaken for a todo id
+	status, _ = sendValidated(t, app, http.MethodGet, "/v2/todos/stream?todo_id=x", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// omitempty parameters accept their zero value
+	status, _ = sendValidated(t, app, http.MethodGet, "/v2/audit?outcome=", "")
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestValidateLeavesVersionOneParametersToTheHandlers(t *testing.T) {
+	app, _, _ := setupAuditApp(t)
+
+	// v1 answers with the messages it always did
+	status, response := sendValidated(t, app, http.MethodGet, "/todos/abc", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "todoId must a be number", response.Data)
+
+	status, response = sendValidated(t, app, http.MethodDelete, "/v1/todos/abc", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "todoId must be a number", response.Data)
+}
+
+func TestValidateRejectsBadBodies(t *testing.T) {
+	app, _, _ := setupAuditApp(t)
+
+	status, response := sendValidated(t, app, http.MethodPost, "/v2/todos", `{"title":`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "body: is not valid JSON: unexpected EOF", response.Data)
+
+	status, response = sendValidated(t, app, http.MethodPost, "/v2/todos", `{"title": 5, "status": "later"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "body.description: is required; body.status: must be one of pending, done; body.title: must be a string", response.Data)
+
+	status, response = sendValidated(t, app, http.MethodPost, "/v2/todos", `{"title": "Valid", "description": "d", "status": null}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "body.status: must be a string", response.Data)
+
+	status, response = sendValidated(t, app, http.MethodPost, "/v2/webhooks", `{"url": "example.com", "secret": "0123456789abcdef", "events": ["todo.exploded"]}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "body.events[0]: must be one of todo.created, todo.updated, todo.completed, todo.deleted; body.url: must be a valid uri", response.Data)
+
+	request := httptest.NewRequest(http.MethodPost, "/v2/todos", strings.NewReader("title=Form"))
+	request.Header.Set("Content-Type", fiber.MIMEApplicationForm)
+	httpResponse, _ := app.Test(request, -1)
+	assert.Equal(t, http.StatusBadRequest, httpResponse.StatusCode)
+
+	status, response = sendValidated(t, app, http.MethodPost, "/v2/todos", `{"title": "Valid", "description": "d", "status": ""}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Success", response.Status)
+}
+
+func TestValidateLeavesVersionOneBodiesToTheHandlers(t *testing.T) {
+	app, _, _ := setupAuditApp(t)
+
+	request := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader("title=Form&description=d"))
+	request.Header.Set("Content-Type", fiber.MIMEApplicationForm)
+	httpResponse, _ := app.Test(request, -1)
+	assert.Equal(t, http.StatusOK, httpResponse.StatusCode)
+
+	status, response := sendValidated(t, app, http.MethodPost, "/todos", `{"Title": "Keys in any case", "DESCRIPTION": "d"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Keys in any case", response.Data.(map[string]interface{})["title"])
+
+	// the handler still rejects what it always rejected
+	status, _ = sendValidated(t, app, http.MethodPost, "/v1/todos", `{"title": "x"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestValidateCatchesResponseDrift(t *testing.T) {
+	document := openapi.Build("drift", "test", []openapi.Operation{
+		{Method: fiber.MethodGet, Path: "/todo", Summary: "Returns the wrong shape", Response: web.TodoResponse{}},
+		{Method: fiber.MethodGet, Path: "/teapot", Summary: "Returns an undocumented status"},
+	})
+	apiValidator, err := openapi.NewValidator(document)
+	assert.NoError(t, err)
+
+	app := fiber.New(fiber.Config{ErrorHandler: exception.NewErrorHandler})
+	app.Use(middleware.Validate(apiValidator, true))
+	app.Get("/todo", func(c *fiber.Ctx) error {
+		return c.JSON(web.WebResponse{Code: 200, Status: "Success", Data: fiber.Map{"id": "one"}})
+	})
+	app.Get("/teapot", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusTeapot)
+	})
+
+	status, response := sendValidated(t, app, http.MethodGet, "/todo", "")
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Contains(t, response.Data, "response.Data.id: must be an integer")
+	assert.Contains(t, response.Data, "response.Data.title: is required")
+
+	status, response = sendValidated(t, app, http.MethodGet, "/teapot", "")
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, "response does not match the OpenAPI document: status 418 is not documented", response.Data)
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app-api/config"
+	"todo-app-api/middleware"
+	"todo-app-api/routes"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func getVersioned(t *testing.T, app *fiber.App, target string, accept string) (*http.Response, map[string]interface{}) {
+	request := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	response, err := app.Test(request, -1)
+	assert.NoError(t, err)
+
+	var body map[string]interface{}
+	json.NewDecoder(response.Body).Decode(&body)
+	return response, body
+}
+
+func createVersionedTodo(t *testing.T, app *fiber.App) {
+	request := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`{"title": "Versioned", "description": "d"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response, err := app.Test(request, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestVersionOnePreservesTheOriginalContract(t *testing.T) {
+	app, _, _ := setupAuditApp(t)
+	createVersionedTodo(t, app)
+
+	response, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/todos/1", nil), -1)
+	assert.NoError(t, err)
+	versioned, _ := io.ReadAll(response.Body)
+	response, err = app.Test(httptest.NewRequest(http.MethodGet, "/todos/1", nil), -1)
+	assert.NoError(t, err)
+	unversioned, _ := io.ReadAll(response.Body)
+
+	assert.Equal(t, string(versioned), string(unversioned))
+	assert.JSONEq(t, `{"code":200,"status":"Success","Data":{"id":1,"title":"Versioned","description":"d","status":"pending"}}`, string(versioned))
+
+	assert.Equal(t, "1", response.Header.Get("API-Version"))
+	assert.Equal(t, "@1793491200", response.Header.Get("Deprecation"))
+	assert.Equal(t, "Sat, 01 May 2027 00:00:00 GMT", response.Header.Get("Sunset"))
+	assert.Equal(t, `</v2/todos/1>; rel="successor-version"`, response.Header.Get("Link"))
+}
+
+func TestVersionTwoUsesTheNewContract(t *testing.T) {
+	app, _, _ := setupAuditApp(t)
+
+	response, body := getVersioned(t, app, "/v2/todos", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []interface{}{}, body["data"], "v2 lists are never null")
+
+	createVersionedTodo(t, app)
+
+	response, body = getVersioned(t, app, "/v2/todos/1", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "2", response.Header.Get("API-Version"))
+	assert.Empty(t, response.Header.Get("Deprecation"))
+	assert.NotContains(t, body, "Data")
+	todo := body["data"].(map[string]interface{})
+	assert.Equal(t, "Versioned", todo["title"])
+	assert.NotEmpty(t, todo["created_at"])
+	assert.NotEmpty(t, todo["updated_at"])
+
+	response, body = getVersioned(t, app, "/v2/todos/99", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	assert.Equal(t, "NOT FOUND", body["status"])
+	assert.NotEmpty(t, body["data"])
+
+	// webhooks keep their shape but move into the lowercase envelope
+	response, body = getVersioned(t, app, "/v2/webhooks", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Contains(t, body, "data")
+}
+
+func TestVersionNegotiatedFromAccept(t *testing.T) {
+	app, _, _ := setupAuditApp(t)
+	createVersionedTodo(t, app)
+
+	for _, accept := range []string{"application/vnd.todo.v2+json", "application/json; version=2", "text/html, application/vnd.todo.v2+json;q=0.9"} {
+		response, body := getVersioned(t, app, "/todos/1", accept)
+		assert.Equal(t, http.StatusOK, response.StatusCode, accept)
+		assert.Equal(t, "2", response.Header.Get("API-Version"), accept)
+		assert.Contains(t, response.Header.Get("Vary"), "Accept", accept)
+		assert.Contains(t, body, "data", accept)
+	}
+
+	response, body := getVersioned(t, app, "/todos/1", "application/json")
+	assert.Equal(t, "1", response.Header.Get("API-Version"))
+	assert.Contains(t, body, "Data")
+
+	response, body = getVersioned(t, app, "/todos/1", "application/vnd.todo.v3+json")
+	assert.Equal(t, http.StatusNotAcceptable, response.StatusCode)
+	assert.Equal(t, "API version 3 is not supported, use 1 to 2", body["Data"])
+
+	// operational endpoints are not versioned
+	response, _ = getVersioned(t, app, "/healthz", "application/vnd.todo.v2+json")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Empty(t, response.Header.Get("API-Version"))
+}
+
+func TestVersionOneRetirementIsOptIn(t *testing.T) {
+	cfg := config.Default()
+	assert.Empty(t, cfg.API.V1DeprecatedAt)
+	assert.Empty(t, cfg.API.V1Sunset)
+
+	app := fiber.New()
+	app.Use(middleware.APIVersion(routes.Resources, config.Date(cfg.API.V1DeprecatedAt), config.Date(cfg.API.V1Sunset)))
+	app.Get("/v1/todos", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	response, _ := getVersioned(t, app, "/todos", "")
+	assert.Equal(t, "1", response.Header.Get("API-Version"))
+	assert.Empty(t, response.Header.Get("Deprecation"))
+	assert.Empty(t, response.Header.Get("Sunset"))
+	assert.Equal(t, `</v2/todos>; rel="successor-version"`, response.Header.Get("Link"))
+}
@@ -0,0 +1,289 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"todo-app-api/event"
+	"todo-app-api/models/domain"
+	"todo-app-api/models/web"
+	"todo-app-api/outbox"
+	"todo-app-api/replica"
+	"todo-app-api/repository"
+	"todo-app-api/service"
+	"todo-app-api/webhook"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type webhookReceiver struct {
+	mutex     sync.Mutex
+	statuses  []int
+	requests  []*http.Request
+	bodies    [][]byte
+	callCount int
+}
+
+func (receiver *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	receiver.requests = append(receiver.requests, r)
+	receiver.bodies = append(receiver.bodies, body)
+
+	status := http.StatusOK
+	if receiver.callCount < len(receiver.statuses) {
+		status = receiver.statuses[receiver.callCount]
+	}
+	receiver.callCount++
+	w.WriteHeader(status)
+}
+
+type webhookFixture struct {
+	todoService    service.TodoService
+	webhookService service.WebhookService
+	dispatcher     *webhook.Dispatcher
+	relay          *outbox.Relay
+	deliveries     repository.WebhookDeliveryRepository
+	db             *gorm.DB
+	now            time.Time
+}
+
+func setupWebhookFixture(t *testing.T) *webhookFixture {
+	db := setupTestDB(t)
+	validate := validator.New()
+
+	subscriptionRepository := repository.NewWebhookSubscriptionRepository(db)
+	deliveryRepository := repository.NewWebhookDeliveryRepository(db)
+
+	fixture := &webhookFixture{deliveries: deliveryRepository, db: db, now: time.Now()}
+	fixture.dispatcher = webhook.NewDispatcher(subscriptionRepository, deliveryRepository, db)
+	fixture.dispatcher.MaxAttempts = 3
+	// the receivers in these tests listen on loopback
+	fixture.dispatcher.AllowPrivateTargets = true
+	fixture.dispatcher.Now = func() time.Time { return fixture.now }
+	fixture.webhookService = service.NewWebhookService(subscriptionRepository, deliveryRepository, db, validate)
+	outboxRepository := repository.NewOutboxRepository(db)
+	fixture.relay = outbox.NewRelay(outboxRepository, db, fixture.dispatcher)
+	fixture.todoService = service.NewTodoService(repository.NewTodoRepository(db), repository.NewTodoRevisionRepository(db), repository.NewTodoReferenceRepository(db), repository.NewCommentRepository(db), outboxRepository, db, replica.NewRouter(db, nil), validate)
+
+	return fixture
+}
+
+func TestWebhookDeliversSignedEvents(t *testing.T) {
+	fixture := setupWebhookFixture(t)
+	ctx := context.Background()
+
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	secret := "0123456789abcdef"
+	fixture.webhookService.Create(ctx, web.WebhookCreateRequest{
+		Url:    server.URL,
+		Secret: secret,
+		Events: []string{event.TodoCreated, event.TodoCompleted},
+	})
+
+	created := fixture.todoService.Create(ctx, web.TodoCreateRequest{Title: "Hook", Description: "d"})
+	// plain updates are filtered out, completion is not
+	fixture.todoService.Update(ctx, web.TodoUpdateRequest{Id: created.Id, Title: "Hook", Description: "d", Status: "done"})
+
+	// events reach the dispatcher through the outbox
+	assert.Equal(t, 0, fixture.dispatcher.DeliverDue(ctx))
+	assert.Equal(t, 3, fixture.relay.RelayBatch(ctx)+fixture.relay.RelayBatch(ctx)+fixture.relay.RelayBatch(ctx))
+
+	assert.Equal(t, 2, fixture.dispatcher.DeliverDue(ctx))
+	assert.Len(t, receiver.requests, 2)
+
+	request := receiver.requests[0]
+	assert.Equal(t, event.TodoCreated, request.Header.Get(webhook.HeaderEvent))
+	signature := request.Header.Get(webhook.HeaderSignature)
+	assert.Equal(t, webhook.Sign(secret, fixture.now, receiver.bodies[0]), signature)
+	assert.NoError(t, webhook.Verify(secret, signature, receiver.bodies[0], fixture.now, 5*time.Minute))
+
+	var evt event.Event
+	assert.NoError(t, json.Unmarshal(receiver.bodies[1], &evt))
+	assert.Equal(t, event.TodoCompleted, evt.Type)
+	assert.Equal(t, created.Id, evt.TodoId)
+	assert.Equal(t, "done", evt.Data.Status)
+
+	deliveries := fixture.webhookService.Deliveries(ctx, 1)
+	assert.Len(t, deliveries, 2)
+	assert.Equal(t, domain.WebhookDeliverySucceeded, deliveries[0].Status)
+}
+
+func TestWebhookRetriesWithBackoffThenDeadLetters(t *testing.T) {
+	fixture := setupWebhookFixture(t)
+	ctx := context.Background()
+
+	receiver := &webhookReceiver{statuses: []int{500, 502, 503}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	fixture.webhookService.Create(ctx, web.WebhookCreateRequest{
+		Url:    server.URL,
+		Secret: "0123456789abcdef",
+		Events: []string{event.TodoCreated},
+	})
+	fixture.todoService.Create(ctx, web.TodoCreateRequest{Title: "Retry", Description: "d"})
+	fixture.relay.RelayBatch(ctx)
+
+	assert.Equal(t, 1, fixture.dispatcher.DeliverDue(ctx))
+	delivery := fixture.webhookService.Deliveries(ctx, 1)[0]
+	assert.Equal(t, domain.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, 500, delivery.ResponseCode)
+
+	// not due until the backoff has elapsed
+	assert.Equal(t, 0, fixture.dispatcher.DeliverDue(ctx))
+
+	fixture.now = fixture.now.Add(fixture.dispatcher.Backoff(1))
+	assert.Equal(t, 1, fixture.dispatcher.DeliverDue(ctx))
+	fixture.now = fixture.now.Add(fixture.dispatcher.Backoff(2))
+	assert.Equal(t, 1, fixture.dispatcher.DeliverDue(ctx))
+
+	delivery = fixture.webhookService.Deliveries(ctx, 1)[0]
+	assert.Equal(t, domain.WebhookDeliveryDead, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, 3, receiver.callCount)
+
+	fixture.now = fixture.now.Add(time.Hour)
+	assert.Equal(t, 0, fixture.dispatcher.DeliverDue(ctx))
+}
+
+func TestWebhookSignatureCoversTheTimestamp(t *testing.T) {
+	secret := "0123456789abcdef"
+	body := []byte(`{"type":"todo.created"}`)
+	signedAt := time.Unix(1700000000, 0)
+
+	signature := webhook.Sign(secret, signedAt, body)
+	assert.Regexp(t, `^t=1700000000,v1=[0-9a-f]{64}$`, signature)
+	assert.NoError(t, webhook.Verify(secret, signature, body, signedAt.Add(time.Minute), 5*time.Minute))
+
+	// a replay after the tolerance is refused
+	assert.Error(t, webhook.Verify(secret, signature, body, signedAt.Add(time.Hour), 5*time.Minute))
+	// so is moving the timestamp forward without re-signing
+	replayed := strings.Replace(signature, "t=1700000000", "t=1700003600", 1)
+	assert.Error(t, webhook.Verify(secret, replayed, body, signedAt.Add(time.Hour), 5*time.Minute))
+	assert.Error(t, webhook.Verify("another-secret!!", signature, body, signedAt, 5*time.Minute))
+	assert.Error(t, webhook.Verify(secret, "sha256=abc", body, signedAt, 5*time.Minute))
+}
+
+func TestWebhookRefusesPrivateTargets(t *testing.T) {
+	fixture := setupWebhookFixture(t)
+	fixture.dispatcher.AllowPrivateTargets = false
+	ctx := context.Background()
+
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	fixture.webhookService.Create(ctx, web.WebhookCreateRequest{
+		Url:    server.URL,
+		Secret: "0123456789abcdef",
+		Events: []string{event.TodoCreated},
+	})
+	fixture.todoService.Create(ctx, web.TodoCreateRequest{Title: "Internal", Description: "d"})
+	fixture.relay.RelayBatch(ctx)
+
+	assert.Equal(t, 1, fixture.dispatcher.DeliverDue(ctx))
+	assert.Equal(t, 0, receiver.callCount)
+
+	delivery := fixture.webhookService.Deliveries(ctx, 1)[0]
+	assert.Equal(t, domain.WebhookDeliveryPending, delivery.Status)
+	assert.Contains(t, delivery.LastError, webhook.ErrForbiddenTarget.Error())
+
+	for _, target := range []string{"http://127.0.0.1:9/", "http://[::1]:9/", "http://169.254.169.254/latest/meta-data", "http://10.0.0.1/", "http://192.168.1.1/"} {
+		_, err := fixture.dispatcher.Client.Get(target)
+		assert.ErrorIs(t, err, webhook.ErrForbiddenTarget, target)
+	}
+}
+
+func TestWebhookDeliveriesAreClaimedOnce(t *testing.T) {
+	fixture := setupWebhookFixture(t)
+	ctx := context.Background()
+
+	fixture.webhookService.Create(ctx, web.WebhookCreateRequest{
+		Url:    "https://example.com/hook",
+		Secret: "0123456789abcdef",
+		Events: []string{event.TodoCreated},
+	})
+	fixture.todoService.Create(ctx, web.TodoCreateRequest{Title: "Claimed", Description: "d"})
+	fixture.relay.RelayBatch(ctx)
+
+	now := fixture.now.UTC()
+	claimedUntil := now.Add(time.Minute)
+	first := fixture.deliveries.ClaimDue(ctx, fixture.db, now, claimedUntil, 10)
+	assert.Len(t, first, 1)
+
+	// another dispatcher polling meanwhile finds nothing to send
+	assert.Empty(t, fixture.deliveries.ClaimDue(ctx, fixture.db, now, claimedUntil, 10))
+	fixture.now = fixture.now.Add(30 * time.Second)
+	assert.Equal(t, 0, fixture.dispatcher.DeliverDue(ctx))
+
+	// a claim whose dispatcher died lapses
+	second := fixture.deliveries.ClaimDue(ctx, fixture.db, claimedUntil, claimedUntil.Add(time.Minute), 10)
+	assert.Len(t, second, 1)
+	assert.Equal(t, first[0].Id, second[0].Id)
+}
+
+// failingDeliveryRepository cannot store deliveries.
+type failingDeliveryRepository struct {
+	repository.WebhookDeliveryRepository
+}
+
+func (r *failingDeliveryRepository) Save(ctx context.Context, tx *gorm.DB, delivery domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	return delivery, errors.New("disk full")
+}
+
+func TestWebhookDeliveriesSurviveFailedInsertsAndRedeliveries(t *testing.T) {
+	fixture := setupWebhookFixture(t)
+	ctx := context.Background()
+
+	fixture.webhookService.Create(ctx, web.WebhookCreateRequest{
+		Url:    "https://example.com/hook",
+		Secret: "0123456789abcdef",
+		Events: []string{event.TodoCreated},
+	})
+	fixture.todoService.Create(ctx, web.TodoCreateRequest{Title: "Once", Description: "d"})
+
+	// the event stays with the relay until its delivery is stored
+	fixture.dispatcher.DeliveryRepository = &failingDeliveryRepository{fixture.deliveries}
+	fixture.relay.BaseBackoff = 0
+	assert.Equal(t, 0, fixture.relay.RelayBatch(ctx))
+	assert.Empty(t, fixture.webhookService.Deliveries(ctx, 1))
+
+	fixture.dispatcher.DeliveryRepository = fixture.deliveries
+	assert.Equal(t, 1, fixture.relay.RelayBatch(ctx))
+	assert.Len(t, fixture.webhookService.Deliveries(ctx, 1), 1)
+
+	// handing the same event out again adds no second delivery
+	var message domain.OutboxMessage
+	fixture.db.First(&message)
+	var evt event.Event
+	assert.NoError(t, json.Unmarshal([]byte(message.Payload), &evt))
+	assert.NoError(t, fixture.dispatcher.Publish(ctx, evt))
+	assert.Len(t, fixture.webhookService.Deliveries(ctx, 1), 1)
+}
+
+func TestWebhookBackoffIsExponentialAndCapped(t *testing.T) {
+	dispatcher := webhook.NewDispatcher(nil, nil, nil)
+	dispatcher.BaseBackoff = time.Second
+	dispatcher.MaxBackoff = 10 * time.Second
+
+	assert.Equal(t, time.Second, dispatcher.Backoff(1))
+	assert.Equal(t, 2*time.Second, dispatcher.Backoff(2))
+	assert.Equal(t, 8*time.Second, dispatcher.Backoff(4))
+	assert.Equal(t, 10*time.Second, dispatcher.Backoff(5))
+}
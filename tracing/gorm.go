@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+type gormPlugin struct{}
+
+// GormPlugin adds a client span for every statement GORM executes, as a child
+// of the span in the statement's context; install it with db.Use.
+func GormPlugin() gorm.Plugin {
+	return gormPlugin{}
+}
+
+func (gormPlugin) Name() string {
+	return "tracing"
+}
+
+func (gormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("gorm:create").Register("tracing:before_create", start("create")),
+		callback.Create().After("gorm:create").Register("tracing:after_create", end),
+		callback.Query().Before("gorm:query").Register("tracing:before_query", start("query")),
+		callback.Query().After("gorm:query").Register("tracing:after_query", end),
+		callback.Update().Before("gorm:update").Register("tracing:before_update", start("update")),
+		callback.Update().After("gorm:update").Register("tracing:after_update", end),
+		callback.Delete().Before("gorm:delete").Register("tracing:before_delete", start("delete")),
+		callback.Delete().After("gorm:delete").Register("tracing:after_delete", end),
+		callback.Row().Before("gorm:row").Register("tracing:before_row", start("row")),
+		callback.Row().After("gorm:row").Register("tracing:after_row", end),
+		callback.Raw().Before("gorm:raw").Register("tracing:before_raw", start("raw")),
+		callback.Raw().After("gorm:raw").Register("tracing:after_raw", end),
+	)
+}
+
+func start(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			// a query outside any request or job is not worth a root span
+			return
+		}
+
+		_, span := Tracer().Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNameKey.String(db.Dialector.Name()),
+				semconv.DBOperationName(operation),
+				semconv.DBCollectionName(db.Statement.Table),
+			),
+		)
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func end(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+
+	tracerName = "todo-app-api"
+)
+
+// Tracer returns the application tracer from the global provider, so spans
+// go to whichever provider is installed at the time they start.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Setup installs the global tracer provider and the W3C trace context
+// propagator. exporter is one of the Exporter constants; the OTLP exporter is
+// configured through the standard OTEL_EXPORTER_OTLP_* variables and the
+// stdout exporter writes to file, or to stdout when file is empty. The
+// returned function flushes and stops the provider.
+func Setup(ctx context.Context, exporter string, file string, serviceName string, version string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	var closer io.Closer
+	switch exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		otlpExporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("create OTLP exporter: %w", err)
+		}
+		spanExporter = otlpExporter
+	case ExporterStdout:
+		var out io.Writer = os.Stdout
+		if file != "" {
+			opened, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return nil, fmt.Errorf("open trace file: %w", err)
+			}
+			out, closer = opened, opened
+		}
+		stdoutExporter, err := stdouttrace.New(stdouttrace.WithWriter(out))
+		if err != nil {
+			return nil, fmt.Errorf("create stdout exporter: %w", err)
+		}
+		spanExporter = stdoutExporter
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter %q", exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(version),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			closer.Close()
+		}
+		return err
+	}, nil
+}
+
+// End finishes span, marking it failed when the surrounding function is
+// panicking, which is how the service layer reports errors. It must be
+// deferred directly: defer tracing.End(span).
+func End(span trace.Span) {
+	if r := recover(); r != nil {
+		span.RecordError(fmt.Errorf("%v", r))
+		span.SetStatus(codes.Error, fmt.Sprint(r))
+		span.End()
+		panic(r)
+	}
+	span.End()
+}
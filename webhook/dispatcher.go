@@ -0,0 +1,262 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app-api/event"
+	"todo-app-api/helper"
+	"todo-app-api/models/domain"
+	"todo-app-api/repository"
+
+	"gorm.io/gorm"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Dispatcher turns published events into webhook deliveries and sends them.
+// Deliveries are persisted before the first attempt, so retries survive a
+// restart and the delivery log doubles as a dead-letter queue.
+type Dispatcher struct {
+	SubscriptionRepository repository.WebhookSubscriptionRepository
+	DeliveryRepository     repository.WebhookDeliveryRepository
+	DB                     *gorm.DB
+	Client                 *http.Client
+	// AllowPrivateTargets lets deliveries reach loopback, link-local and
+	// private addresses, which are refused by default.
+	AllowPrivateTargets bool
+	MaxAttempts         int
+	BaseBackoff         time.Duration
+	MaxBackoff          time.Duration
+	PollInterval        time.Duration
+	BatchSize           int
+	// ClaimTimeout is how long a claimed delivery is reserved for this
+	// dispatcher before another may send it.
+	ClaimTimeout time.Duration
+	Now          func() time.Time
+	wake         chan struct{}
+}
+
+func NewDispatcher(subscriptionRepository repository.WebhookSubscriptionRepository, deliveryRepository repository.WebhookDeliveryRepository, db *gorm.DB) *Dispatcher {
+	dispatcher := &Dispatcher{
+		SubscriptionRepository: subscriptionRepository,
+		DeliveryRepository:     deliveryRepository,
+		DB:                     db,
+		MaxAttempts:            8,
+		BaseBackoff:            time.Second,
+		MaxBackoff:             time.Hour,
+		PollInterval:           5 * time.Second,
+		BatchSize:              50,
+		ClaimTimeout:           time.Minute,
+		Now:                    time.Now,
+		wake:                   make(chan struct{}, 1),
+	}
+	dispatcher.Client = newClient(10*time.Second, func() bool { return dispatcher.AllowPrivateTargets })
+	return dispatcher
+}
+
+// Sign returns the value of the X-Webhook-Signature header for body sent at
+// timestamp: t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">. Signing
+// the timestamp lets receivers reject replayed deliveries.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + unix + ",v1=" + signature(secret, unix, body)
+}
+
+func signature(secret string, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature made by Sign and that it is no older than
+// tolerance at now, as a receiver of deliveries would.
+func Verify(secret string, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var unix, v1 string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			v1 = value
+		}
+	}
+
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || v1 == "" {
+		return errors.New("malformed signature")
+	}
+	if !hmac.Equal([]byte(v1), []byte(signature(secret, unix, body))) {
+		return errors.New("signature mismatch")
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return errors.New("signature timestamp outside the tolerance")
+	}
+	return nil
+}
+
+// Backoff returns the delay before the next attempt once attempts have failed.
+func (dispatcher *Dispatcher) Backoff(attempts int) time.Duration {
+	delay := dispatcher.BaseBackoff
+	for i := 1; i < attempts && delay < dispatcher.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > dispatcher.MaxBackoff {
+		delay = dispatcher.MaxBackoff
+	}
+	return delay
+}
+
+// Publish stores a delivery of evt for every subscription that wants it. An
+// error leaves the event with the relay, which hands it out again later.
+func (dispatcher *Dispatcher) Publish(ctx context.Context, evt event.Event) (err error) {
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	tx := dispatcher.DB.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		if err = tx.Commit().Error; err == nil {
+			dispatcher.notify()
+		}
+	}()
+
+	for _, subscription := range dispatcher.SubscriptionRepository.FindActive(ctx, tx) {
+		if !subscription.Accepts(evt.Type) {
+			continue
+		}
+		_, err = dispatcher.DeliveryRepository.Save(ctx, tx, domain.WebhookDelivery{
+			SubscriptionId: subscription.Id,
+			EventId:        evt.Id,
+			EventType:      evt.Type,
+			Payload:        string(payload),
+			Status:         domain.WebhookDeliveryPending,
+			NextAttemptAt:  dispatcher.Now().UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("store delivery for subscription %d: %w", subscription.Id, err)
+		}
+	}
+	return nil
+}
+
+func (dispatcher *Dispatcher) notify() {
+	select {
+	case dispatcher.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run delivers due webhooks until ctx is cancelled.
+func (dispatcher *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(dispatcher.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		dispatcher.DeliverDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-dispatcher.wake:
+		}
+	}
+}
+
+// DeliverDue attempts every delivery whose next attempt is due and returns
+// how many were attempted. The deliveries are claimed in a short transaction
+// of their own, so concurrent dispatchers do not send the same one twice and
+// no row locks are held while sending.
+func (dispatcher *Dispatcher) DeliverDue(ctx context.Context) int {
+	deliveries := dispatcher.claim(ctx)
+	for _, delivery := range deliveries {
+		dispatcher.attempt(ctx, delivery)
+	}
+	return len(deliveries)
+}
+
+func (dispatcher *Dispatcher) claim(ctx context.Context) []domain.WebhookDelivery {
+	tx := dispatcher.DB.Begin()
+	defer helper.CommitOrRollback(tx)
+
+	now := dispatcher.Now().UTC()
+	return dispatcher.DeliveryRepository.ClaimDue(ctx, tx, now, now.Add(dispatcher.ClaimTimeout), dispatcher.BatchSize)
+}
+
+func (dispatcher *Dispatcher) attempt(ctx context.Context, delivery domain.WebhookDelivery) {
+	subscription, err := dispatcher.SubscriptionRepository.FindById(ctx, dispatcher.DB, delivery.SubscriptionId)
+
+	delivery.Attempts++
+	delivery.ResponseCode = 0
+	delivery.LastError = ""
+	if err != nil {
+		// the subscription was removed; nothing left to retry against
+		delivery.Status = domain.WebhookDeliveryDead
+		delivery.LastError = "subscription not found"
+		dispatcher.DeliveryRepository.Update(ctx, dispatcher.DB, delivery)
+		return
+	}
+
+	statusCode, err := dispatcher.send(ctx, subscription, delivery)
+	delivery.ResponseCode = statusCode
+	now := dispatcher.Now().UTC()
+	switch {
+	case err == nil:
+		delivery.Status = domain.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= dispatcher.MaxAttempts:
+		delivery.Status = domain.WebhookDeliveryDead
+		delivery.LastError = err.Error()
+	default:
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(dispatcher.Backoff(delivery.Attempts))
+	}
+
+	dispatcher.DeliveryRepository.Update(ctx, dispatcher.DB, delivery)
+}
+
+func (dispatcher *Dispatcher) send(ctx context.Context, subscription domain.WebhookSubscription, delivery domain.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEvent, delivery.EventType)
+	request.Header.Set(HeaderDelivery, strconv.Itoa(delivery.Id))
+	request.Header.Set(HeaderSignature, Sign(subscription.Secret, dispatcher.Now(), body))
+
+	response, err := dispatcher.Client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
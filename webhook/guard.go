@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var ErrForbiddenTarget = errors.New("webhook target is not a public address")
+
+// reservedPrefixes are not reachable on the public internet but are not
+// covered by the netip predicates used in forbidden.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// forbidden reports whether ip is a loopback, link-local, private or
+// otherwise internal address a subscription must not reach.
+func forbidden(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() {
+		return true
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// newClient returns the client deliveries are sent with. The address is
+// checked after DNS resolution, right before connecting, so neither a host
+// name resolving to an internal address nor a redirect to one gets through.
+// Proxies from the environment are ignored, as they would connect on the
+// dispatcher's behalf.
+func newClient(timeout time.Duration, allowPrivateTargets func() bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network string, address string, conn syscall.RawConn) error {
+			if allowPrivateTargets() {
+				return nil
+			}
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrForbiddenTarget, address)
+			}
+			if forbidden(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrForbiddenTarget, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}